@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecoveryStatsName is the name of the RecoveryStats the controller keeps.
+const RecoveryStatsName = "cluster"
+
+// -------------------- SPEC --------------------
+type RecoveryStatsSpec struct {
+	// Number of recent workflow durations kept per entry for the percentiles
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +kubebuilder:default=100
+	// +optional
+	DurationSamples int32 `json:"durationSamples,omitempty"`
+}
+
+// ------------------- STATUS -------------------
+
+// RecoveryStatsEntry aggregates the recoveries of one FailureType with one
+// WorkflowTemplate.
+type RecoveryStatsEntry struct {
+	FailureType      string `json:"failureType"`
+	WorkflowTemplate string `json:"workflowTemplate"`
+	// Workflows started
+	Attempts  int64 `json:"attempts"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+	// Recoveries followed by another trigger on the same target within the
+	// escalation window, because they failed or did not fix the fault
+	Escalated int64 `json:"escalated"`
+	// Succeeded / (Succeeded + Failed), in percent
+	SuccessPercent int32 `json:"successPercent"`
+	// Escalated / Attempts, in percent
+	EscalationPercent int32 `json:"escalationPercent"`
+	// Percentiles of the recent workflow durations
+	P50Duration *metav1.Duration `json:"p50Duration,omitempty"`
+	P95Duration *metav1.Duration `json:"p95Duration,omitempty"`
+	// Recent workflow durations in seconds, oldest first
+	RecentDurations []int64      `json:"recentDurations,omitempty"`
+	LastUpdated     *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+type RecoveryStatsStatus struct {
+	Entries []RecoveryStatsEntry `json:"entries,omitempty"`
+}
+
+// RecoveryStats keeps the recovery statistics across controller restarts.
+// The controller maintains a single one named cluster.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RecoveryStats struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RecoveryStatsSpec   `json:"spec,omitempty"`
+	Status RecoveryStatsStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type RecoveryStatsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RecoveryStats `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RecoveryStats{}, &RecoveryStatsList{})
+}
@@ -30,6 +30,10 @@ type RecoveryTriggerSpec struct {
 	FailureType      string         `json:"failureType,omitempty"`
 	WorkflowTemplate string         `json:"workflowTemplate,omitempty"`
 	TargetObjects    []TargetObject `json:"targetObjects,omitempty"`
+	// Compute the conflict decision and render the workflow into status
+	// without creating it. The controller's --dry-run flag does so for
+	// every trigger.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // ------------------- STATUS -------------------
@@ -37,7 +41,10 @@ type RecoveryTriggerStatus struct {
 	State        string       `json:"state,omitempty"`
 	Reason       string       `json:"reason,omitempty"`
 	StartedAt    *metav1.Time `json:"startedAt,omitempty"`
+	FinishedAt   *metav1.Time `json:"finishedAt,omitempty"`
 	WorkflowName string       `json:"workflowName,omitempty"`
+	// Workflow that would have been created, in dry run
+	DryRunWorkflow string `json:"dryRunWorkflow,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,9 +21,140 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStats) DeepCopyInto(out *RecoveryStats) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStats.
+func (in *RecoveryStats) DeepCopy() *RecoveryStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryStats) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStatsEntry) DeepCopyInto(out *RecoveryStatsEntry) {
+	*out = *in
+	if in.P50Duration != nil {
+		in, out := &in.P50Duration, &out.P50Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.P95Duration != nil {
+		in, out := &in.P95Duration, &out.P95Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RecentDurations != nil {
+		in, out := &in.RecentDurations, &out.RecentDurations
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStatsEntry.
+func (in *RecoveryStatsEntry) DeepCopy() *RecoveryStatsEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStatsEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStatsList) DeepCopyInto(out *RecoveryStatsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RecoveryStats, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStatsList.
+func (in *RecoveryStatsList) DeepCopy() *RecoveryStatsList {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStatsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryStatsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStatsSpec) DeepCopyInto(out *RecoveryStatsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStatsSpec.
+func (in *RecoveryStatsSpec) DeepCopy() *RecoveryStatsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStatsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStatsStatus) DeepCopyInto(out *RecoveryStatsStatus) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]RecoveryStatsEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStatsStatus.
+func (in *RecoveryStatsStatus) DeepCopy() *RecoveryStatsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStatsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecoveryTrigger) DeepCopyInto(out *RecoveryTrigger) {
 	*out = *in
@@ -110,6 +241,10 @@ func (in *RecoveryTriggerStatus) DeepCopyInto(out *RecoveryTriggerStatus) {
 		in, out := &in.StartedAt, &out.StartedAt
 		*out = (*in).DeepCopy()
 	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryTriggerStatus.
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command conflictsim replays a timeline of RecoveryTrigger creations and
+// workflow completions through the controller's conflict handling on a
+// virtual clock:
+//
+//	conflictsim -f timeline.yaml
+//
+// It prints every decision taken with its reason, then the queue wait and
+// recovery time of each trigger. With -o json the report can be kept and
+// diffed as a regression test. The exit code is 0 on success, 1 when the
+// simulation fails and 2 on invalid input.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/phuongbac/conflictawareworkflowcontroller/internal/simulator"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("conflictsim", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var file, output string
+	var verbose bool
+	fs.StringVar(&file, "f", "", "A YAML or JSON timeline, - for stdin.")
+	fs.StringVar(&output, "o", "text", "Output format: text or json.")
+	fs.BoolVar(&verbose, "v", false, "Log the reconciles to stderr.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if file == "" || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: conflictsim -f FILE [-o text|json]")
+		return 2
+	}
+	if output != "text" && output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", output)
+		return 2
+	}
+	if verbose {
+		ctrl.SetLogger(zap.New(zap.WriteTo(stderr), zap.UseDevMode(true)))
+	}
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	var tl *simulator.Timeline
+	if err == nil {
+		tl, err = simulator.Load(data)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		return 2
+	}
+
+	report, err := simulator.Run(context.Background(), tl)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if output == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = printText(stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printText(w io.Writer, report *simulator.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTRIGGER\tSTATE\tREASON")
+	for _, d := range report.Decisions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.At.Duration, d.Trigger, orDash(d.State), d.Reason)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "TRIGGER\tFAILURE TYPE\tSTATE\tCREATED\tSTARTED\tFINISHED\tQUEUE WAIT\tRECOVERY TIME")
+	for _, t := range report.Triggers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, orDash(t.FailureType), orDash(t.State),
+			t.Created.Duration, duration(t.Started), duration(t.Finished), duration(t.QueueWait), duration(t.RecoveryTime))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "total queue wait %s, makespan %s\n", report.TotalQueueWait.Duration, report.Makespan.Duration)
+	return tw.Flush()
+}
+
+func duration(d *metav1.Duration) string {
+	if d == nil {
+		return "-"
+	}
+	return d.Duration.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
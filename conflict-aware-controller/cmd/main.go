@@ -41,6 +41,7 @@ import (
 	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
 	// "github.com/phuongbac/conflictawareworkflowcontroller/internal/controller"
 	controllers "github.com/phuongbac/conflictawareworkflowcontroller/internal/controller"
+	webhookrecoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -65,6 +66,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var dryRun bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -83,6 +85,8 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, conflict decisions are recorded and workflows rendered into trigger status instead of being created.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -205,12 +209,27 @@ func main() {
 	}
 
 	if err := (&controllers.RecoveryTriggerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Scheme:    mgr.GetScheme(),
+		DryRun:    dryRun,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RecoveryTrigger")
 		os.Exit(1)
 	}
+	if err := (&controllers.RecoveryStatsReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RecoveryStats")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookrecoveryv1alpha1.SetupRecoveryTriggerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RecoveryTrigger")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -0,0 +1,229 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// AnnotationAction on a template of a WorkflowTemplate, or on the
+	// WorkflowTemplate itself, is the action type of the step: read,
+	// restart, delete, drain or scale.
+	AnnotationAction = "recovery.workflow-recovery.io/action"
+	// AnnotationResources lists the resources the step touches, as comma
+	// separated Kind/name. Names may be globs, and ${target.kind} and
+	// ${target.name} expand to each target object of the trigger, as in
+	// Pod/${target.name}-*. Argo's own {{ }} syntax is left to Argo. Without
+	// it the step touches the target objects.
+	AnnotationResources = "recovery.workflow-recovery.io/resources"
+)
+
+// Action types.
+const (
+	ActionRead    = "read"
+	ActionRestart = "restart"
+	ActionDelete  = "delete"
+	ActionDrain   = "drain"
+	ActionScale   = "scale"
+)
+
+// compatibleActions is the action compatibility matrix: whether two steps
+// may act on the same resource at once. Unknown actions are compatible with
+// nothing.
+var compatibleActions = map[string]map[string]bool{
+	ActionRead:    {ActionRead: true, ActionRestart: true, ActionDelete: true, ActionDrain: true, ActionScale: true},
+	ActionRestart: {ActionRead: true, ActionRestart: true},
+	ActionDelete:  {ActionRead: true},
+	ActionDrain:   {ActionRead: true},
+	ActionScale:   {ActionRead: true},
+}
+
+// +kubebuilder:rbac:groups=argoproj.io,resources=workflowtemplates,verbs=get;list;watch
+
+// stepAction is what a step of the workflow of a trigger does.
+type stepAction struct {
+	Step   string
+	Action string
+	// Resources as Kind/name
+	Resources []string
+}
+
+// triggerActions returns the step actions of trigger and of the running
+// triggers, by trigger name. Triggers whose WorkflowTemplate declares no
+// actions, or cannot be read, are left out and conflict by target.
+func (r *RecoveryTriggerReconciler) triggerActions(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger, triggers []recoveryv1alpha1.RecoveryTrigger) (map[string][]stepAction, error) {
+	log := logf.FromContext(ctx)
+	templates := map[string]*argov1alpha1.WorkflowTemplate{}
+	actions := map[string][]stepAction{}
+	add := func(t *recoveryv1alpha1.RecoveryTrigger) error {
+		name := t.Spec.WorkflowTemplate
+		if name == "" {
+			return nil
+		}
+		wft, ok := templates[name]
+		if !ok {
+			wft = &argov1alpha1.WorkflowTemplate{}
+			if err := r.Get(ctx, client.ObjectKey{Namespace: trigger.Namespace, Name: name}, wft); err != nil {
+				if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+					return err
+				}
+				wft = nil
+			}
+			templates[name] = wft
+		}
+		if wft == nil {
+			return nil
+		}
+		steps, err := workflowActions(wft, t)
+		if err != nil {
+			log.Error(err, "unable to read step actions, conflicts are detected by target", "workflowTemplate", name)
+			return nil
+		}
+		if len(steps) > 0 {
+			actions[t.Name] = steps
+		}
+		return nil
+	}
+
+	running := false
+	for i := range triggers {
+		t := &triggers[i]
+		if t.Name == trigger.Name || t.Status.State != "Running" {
+			continue
+		}
+		running = true
+		if err := add(t); err != nil {
+			return nil, err
+		}
+	}
+	// Without a running trigger there is nothing to conflict with
+	if !running {
+		return actions, nil
+	}
+	return actions, add(trigger)
+}
+
+// workflowActions returns the annotated steps of wft, with their resources
+// rendered for the target objects of trigger.
+func workflowActions(wft *argov1alpha1.WorkflowTemplate, trigger *recoveryv1alpha1.RecoveryTrigger) ([]stepAction, error) {
+	var steps []stepAction
+	add := func(step string, annotations map[string]string) error {
+		action := annotations[AnnotationAction]
+		if action == "" {
+			return nil
+		}
+		resources, err := stepResources(annotations[AnnotationResources], trigger)
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step, err)
+		}
+		steps = append(steps, stepAction{Step: step, Action: strings.ToLower(strings.TrimSpace(action)), Resources: resources})
+		return nil
+	}
+	if err := add(wft.Name, wft.Annotations); err != nil {
+		return nil, err
+	}
+	for _, t := range wft.Spec.Templates {
+		if err := add(t.Name, t.Metadata.Annotations); err != nil {
+			return nil, err
+		}
+	}
+	return steps, nil
+}
+
+// stepResources renders the resources annotation of a step for every
+// target object of trigger.
+func stepResources(annotation string, trigger *recoveryv1alpha1.RecoveryTrigger) ([]string, error) {
+	var resources []string
+	if strings.TrimSpace(annotation) == "" {
+		for _, obj := range trigger.Spec.TargetObjects {
+			resources = append(resources, obj.Kind+"/"+obj.Name)
+		}
+		return resources, nil
+	}
+	targets := trigger.Spec.TargetObjects
+	if len(targets) == 0 {
+		targets = []recoveryv1alpha1.TargetObject{{}}
+	}
+	for _, entry := range strings.Split(annotation, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			return nil, fmt.Errorf("resource %q is not Kind/name", entry)
+		}
+		for _, target := range targets {
+			resource := strings.NewReplacer("${target.kind}", target.Kind, "${target.name}", target.Name).Replace(entry)
+			if !slices.Contains(resources, resource) {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// actionConflict returns a description of the first pair of steps of a and
+// b that act incompatibly on a common resource, if any.
+func actionConflict(a, b []stepAction) (string, bool) {
+	for _, sa := range a {
+		for _, sb := range b {
+			if compatibleActions[sa.Action][sb.Action] {
+				continue
+			}
+			for _, ra := range sa.Resources {
+				for _, rb := range sb.Resources {
+					if resourcesOverlap(ra, rb) {
+						return fmt.Sprintf("%s %s in step %s conflicts with %s %s in step %s",
+							sa.Action, ra, sa.Step, sb.Action, rb, sb.Step), true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// resourcesOverlap reports whether two Kind/name resources, whose names may
+// be globs, can be the same object.
+func resourcesOverlap(a, b string) bool {
+	kindA, nameA, _ := strings.Cut(a, "/")
+	kindB, nameB, _ := strings.Cut(b, "/")
+	if kindA != kindB {
+		return false
+	}
+	if nameA == nameB {
+		return true
+	}
+	if ok, _ := path.Match(nameA, nameB); ok {
+		return true
+	}
+	ok, _ := path.Match(nameB, nameA)
+	return ok
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+func TestDetectConflictsActions(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{recoveryv1alpha1.AddToScheme, argov1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workflowTemplate := func(name string, annotations map[string]string, steps ...argov1alpha1.Template) *argov1alpha1.WorkflowTemplate {
+		return &argov1alpha1.WorkflowTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "recovery", Name: name, Annotations: annotations},
+			Spec:       argov1alpha1.WorkflowSpec{Templates: steps},
+		}
+	}
+	step := func(name, action string) argov1alpha1.Template {
+		return argov1alpha1.Template{Name: name, Metadata: argov1alpha1.Metadata{Annotations: map[string]string{AnnotationAction: action}}}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			workflowTemplate("scale-down", map[string]string{
+				AnnotationAction:    ActionScale,
+				AnnotationResources: "Deployment/${target.name}, Pod/${target.name}-*",
+			}),
+			workflowTemplate("restart-pods", nil, step("main", ""), step("restart", ActionRestart)),
+			workflowTemplate("diagnose", nil, step("collect", ActionRead)),
+			workflowTemplate("unannotated", nil, step("main", "")),
+		).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+
+	trigger := func(name, failureType, template, kind, target string) recoveryv1alpha1.RecoveryTrigger {
+		return recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "recovery", Name: name},
+			Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+				FailureType:      failureType,
+				WorkflowTemplate: template,
+				TargetObjects:    []recoveryv1alpha1.TargetObject{{Kind: kind, Name: target}},
+			},
+		}
+	}
+	scaling := trigger("scale-web", "HighLatency", "scale-down", "Deployment", "web")
+	scaling.Status.State = "Running"
+
+	for _, tc := range []struct {
+		name     string
+		trigger  recoveryv1alpha1.RecoveryTrigger
+		conflict string
+		blocker  string
+	}{
+		{"different objects, incompatible actions", trigger("restart-pod", "CrashLoop", "restart-pods", "Pod", "web-7d9f"),
+			"ActionConflict", "scale-web: restart Pod/web-7d9f in step restart conflicts with scale Pod/web-* in step scale-down"},
+		{"same object, compatible actions", trigger("diagnose-web", "CrashLoop", "diagnose", "Deployment", "web"), "None", ""},
+		{"undeclared actions fall back to targets", trigger("other-web", "CrashLoop", "unannotated", "Deployment", "web"),
+			"ResourceConflict", "scale-web"},
+		{"unrelated objects", trigger("restart-api", "CrashLoop", "restart-pods", "Pod", "api-1"), "None", ""},
+		{"same failure type", trigger("diagnose-api", "HighLatency", "diagnose", "Deployment", "api"),
+			"DependencyConflict", "scale-web"},
+	} {
+		triggers := []recoveryv1alpha1.RecoveryTrigger{scaling, tc.trigger}
+		actions, err := r.triggerActions(ctx, &tc.trigger, triggers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conflict, blocker := detectConflicts(&tc.trigger, triggers, actions)
+		if conflict != tc.conflict || blocker != tc.blocker {
+			t.Errorf("%s: conflict = %s, %q; want %s, %q", tc.name, conflict, blocker, tc.conflict, tc.blocker)
+		}
+	}
+}
+
+func TestWorkflowActions(t *testing.T) {
+	rt := &recoveryv1alpha1.RecoveryTrigger{Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+		TargetObjects: []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: "worker-1"}, {Kind: "Node", Name: "worker-2"}},
+	}}
+	wft := &argov1alpha1.WorkflowTemplate{Spec: argov1alpha1.WorkflowSpec{Templates: []argov1alpha1.Template{{
+		Name:     "drain",
+		Metadata: argov1alpha1.Metadata{Annotations: map[string]string{AnnotationAction: "Drain"}},
+	}}}}
+	steps, err := workflowActions(wft, rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 1 || steps[0].Action != ActionDrain || strings.Join(steps[0].Resources, ",") != "Node/worker-1,Node/worker-2" {
+		t.Errorf("steps = %+v, want a drain of both targets", steps)
+	}
+
+	wft.Spec.Templates[0].Metadata.Annotations[AnnotationResources] = "${target.name}"
+	if _, err := workflowActions(wft, rt); err == nil {
+		t.Error("resource without kind accepted, want an error")
+	}
+}
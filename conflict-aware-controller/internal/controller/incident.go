@@ -0,0 +1,216 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LabelIncident on a RecoveryTrigger names the Incident it belongs to.
+	// Triggers without it are matched to incidents by target.
+	LabelIncident = "detect.failure-recovery.io/incident"
+	// maxIncidentTimeline matches the timeline length the detection
+	// controller keeps.
+	maxIncidentTimeline = 100
+)
+
+// incidentGVK is the detection controller's Incident, read and updated as
+// unstructured like MaintenanceWindows.
+var incidentGVK = schema.GroupVersionKind{
+	Group:   "detect.failure-recovery.io",
+	Version: "v1alpha1",
+	Kind:    "Incident",
+}
+
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=incidents,verbs=get;list
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=incidents/status,verbs=get;update
+
+// recordIncident records the state of trigger in its Incident: the trigger
+// entry, a timeline event and, once a workflow runs, the recovery start.
+// Triggers that belong to no incident are skipped.
+func (r *RecoveryTriggerReconciler) recordIncident(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		inc, err := r.incidentFor(ctx, trigger)
+		if err != nil || inc == nil {
+			return err
+		}
+		now := r.now().UTC().Format(time.RFC3339)
+		status, _, _ := unstructured.NestedMap(inc.Object, "status")
+		if status == nil {
+			status = map[string]interface{}{}
+		}
+
+		entry := map[string]interface{}{
+			"namespace": trigger.Namespace,
+			"name":      trigger.Name,
+			"state":     trigger.Status.State,
+		}
+		if trigger.Spec.FailureType != "" {
+			entry["failureType"] = trigger.Spec.FailureType
+		}
+		if trigger.Status.WorkflowName != "" {
+			entry["workflowName"] = trigger.Status.WorkflowName
+		}
+		if trigger.Status.StartedAt != nil {
+			entry["startedAt"] = trigger.Status.StartedAt.UTC().Format(time.RFC3339)
+		}
+		triggers, _, _ := unstructured.NestedSlice(status, "triggers")
+		i := incidentTriggerIndex(triggers, trigger)
+		if i >= 0 {
+			old, _ := triggers[i].(map[string]interface{})
+			if old["state"] == entry["state"] && old["workflowName"] == entry["workflowName"] {
+				return nil
+			}
+			if finished, ok := old["finishedAt"]; ok {
+				entry["finishedAt"] = finished
+			}
+		}
+		if _, ok := entry["finishedAt"]; !ok && (trigger.Status.State == "Succeeded" || trigger.Status.State == "Failed") {
+			entry["finishedAt"] = now
+			if trigger.Status.FinishedAt != nil {
+				entry["finishedAt"] = trigger.Status.FinishedAt.UTC().Format(time.RFC3339)
+			}
+		}
+		if i >= 0 {
+			triggers[i] = entry
+		} else {
+			triggers = append(triggers, entry)
+		}
+		status["triggers"] = triggers
+
+		timeline, _, _ := unstructured.NestedSlice(status, "timeline")
+		timeline = append(timeline, map[string]interface{}{
+			"time":    now,
+			"source":  "recovery",
+			"object":  "RecoveryTrigger/" + trigger.Namespace + "/" + trigger.Name,
+			"message": fmt.Sprintf("%s: %s", trigger.Status.State, trigger.Status.Reason),
+		})
+		if n := len(timeline); n > maxIncidentTimeline {
+			timeline = timeline[n-maxIncidentTimeline:]
+		}
+		status["timeline"] = timeline
+
+		if trigger.Status.State == "Running" {
+			if status["phase"] != "Resolved" {
+				status["phase"] = "Recovering"
+			}
+			if _, ok := status["recoveryStartedAt"]; !ok {
+				status["recoveryStartedAt"] = now
+			}
+		}
+		if err := unstructured.SetNestedMap(inc.Object, status, "status"); err != nil {
+			return err
+		}
+		return r.Status().Update(ctx, inc)
+	})
+}
+
+// incidentFor returns the Incident of trigger: the one its label names, the
+// one it is already recorded in, or the newest unresolved incident whose
+// root or detections target one of its target objects.
+func (r *RecoveryTriggerReconciler) incidentFor(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) (*unstructured.Unstructured, error) {
+	if name := trigger.Labels[LabelIncident]; name != "" {
+		inc := &unstructured.Unstructured{}
+		inc.SetGroupVersionKind(incidentGVK)
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, inc); err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return inc, nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(incidentGVK.GroupVersion().WithKind("IncidentList"))
+	if err := r.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Newest first
+	sort.Slice(list.Items, func(i, j int) bool {
+		ti, tj := list.Items[i].GetCreationTimestamp(), list.Items[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+	for i := range list.Items {
+		triggers, _, _ := unstructured.NestedSlice(list.Items[i].Object, "status", "triggers")
+		if incidentTriggerIndex(triggers, trigger) >= 0 {
+			return &list.Items[i], nil
+		}
+	}
+	for i := range list.Items {
+		inc := &list.Items[i]
+		if phase, _, _ := unstructured.NestedString(inc.Object, "status", "phase"); phase == "Resolved" {
+			continue
+		}
+		if incidentCovers(inc, trigger) {
+			return inc, nil
+		}
+	}
+	return nil, nil
+}
+
+// incidentCovers tells whether an incident's root or one of its detections
+// targets an object of trigger. Target objects carry no namespace, so
+// kinds and names are compared.
+func incidentCovers(inc *unstructured.Unstructured, trigger *recoveryv1alpha1.RecoveryTrigger) bool {
+	var targets []map[string]interface{}
+	if root, found, _ := unstructured.NestedMap(inc.Object, "spec", "root"); found {
+		targets = append(targets, root)
+	}
+	detections, _, _ := unstructured.NestedSlice(inc.Object, "status", "detections")
+	for _, d := range detections {
+		if target, found, _ := unstructured.NestedMap(d.(map[string]interface{}), "target"); found {
+			targets = append(targets, target)
+		}
+	}
+	for _, obj := range trigger.Spec.TargetObjects {
+		for _, t := range targets {
+			if t["kind"] == obj.Kind && t["name"] == obj.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// incidentTriggerIndex returns the position of trigger in an incident's
+// trigger entries, or -1.
+func incidentTriggerIndex(triggers []interface{}, trigger *recoveryv1alpha1.RecoveryTrigger) int {
+	for i, t := range triggers {
+		m, _ := t.(map[string]interface{})
+		if m["namespace"] == trigger.Namespace && m["name"] == trigger.Name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+func TestRecordIncident(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := recoveryv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	incident := func(name, phase, rootKind, rootName string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec":   map[string]interface{}{"root": map[string]interface{}{"kind": rootKind, "name": rootName}},
+			"status": map[string]interface{}{"phase": phase},
+		}}
+		u.SetGroupVersionKind(incidentGVK)
+		u.SetName(name)
+		return u
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			incident("worker-1-old", "Resolved", "Node", "worker-1"),
+			incident("worker-1-now", "Open", "Node", "worker-1"),
+			incident("worker-2", "Open", "Node", "worker-2"),
+		).
+		WithStatusSubresource(incident("", "", "", "")).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+
+	trigger := &recoveryv1alpha1.RecoveryTrigger{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "recovery", Name: "drain-worker-1"},
+		Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+			FailureType:   "NodeNotReady",
+			TargetObjects: []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: "worker-1"}},
+		},
+		Status: recoveryv1alpha1.RecoveryTriggerStatus{State: "Held", Reason: "Held by MaintenanceWindow upgrade"},
+	}
+	get := func(name string) map[string]interface{} {
+		t.Helper()
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(incidentGVK)
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, u); err != nil {
+			t.Fatal(err)
+		}
+		status, _, _ := unstructured.NestedMap(u.Object, "status")
+		return status
+	}
+
+	record := func() {
+		t.Helper()
+		if err := r.recordIncident(ctx, trigger); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record()
+	trigger.Status = recoveryv1alpha1.RecoveryTriggerStatus{State: "Running", Reason: "No conflicts", WorkflowName: "node-recovery-x1"}
+	record()
+	// Repeated reconciles of the same state add nothing
+	record()
+
+	status := get("worker-1-now")
+	if status["phase"] != "Recovering" || status["recoveryStartedAt"] == nil {
+		t.Errorf("status = %v, want recovering", status)
+	}
+	triggers, _, _ := unstructured.NestedSlice(status, "triggers")
+	timeline, _, _ := unstructured.NestedSlice(status, "timeline")
+	if len(triggers) != 1 || triggers[0].(map[string]interface{})["workflowName"] != "node-recovery-x1" {
+		t.Errorf("triggers = %v", triggers)
+	}
+	if len(timeline) != 2 {
+		t.Errorf("timeline = %v, want held and running", timeline)
+	}
+	if s := get("worker-1-old"); s["triggers"] != nil {
+		t.Errorf("resolved incident = %v, want it untouched", s)
+	}
+
+	// The trigger stays in its incident once that resolved
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(incidentGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: "worker-1-now"}, u); err != nil {
+		t.Fatal(err)
+	}
+	_ = unstructured.SetNestedField(u.Object, "Resolved", "status", "phase")
+	if err := c.Status().Update(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	trigger.Status.State = "Succeeded"
+	record()
+	triggers, _, _ = unstructured.NestedSlice(get("worker-1-now"), "triggers")
+	if len(triggers) != 1 || triggers[0].(map[string]interface{})["finishedAt"] == nil {
+		t.Errorf("triggers = %v, want the finished trigger", triggers)
+	}
+}
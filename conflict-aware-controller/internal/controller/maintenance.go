@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maintenanceWindowListGVK lists the detection controller's
+// MaintenanceWindows. They are read as unstructured so that this controller
+// does not depend on the detection API, and are ignored when their CRD is
+// not installed. The detection controller keeps their status current.
+var maintenanceWindowListGVK = schema.GroupVersionKind{
+	Group:   "detect.failure-recovery.io",
+	Version: "v1alpha1",
+	Kind:    "MaintenanceWindowList",
+}
+
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=maintenancewindows,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=nodes;pods,verbs=get
+
+// holdingWindow returns the name and end of an open MaintenanceWindow that
+// covers trigger, or "" if there is none. Windows are tried in name order.
+func (r *RecoveryTriggerReconciler) holdingWindow(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger, now time.Time) (string, time.Time, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(maintenanceWindowListGVK)
+	if err := r.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	var targetLabels []labels.Set
+	for _, mw := range list.Items {
+		until, ok := openUntil(&mw, now)
+		if !ok {
+			continue
+		}
+		spec, _, _ := unstructured.NestedMap(mw.Object, "spec")
+		// Failure types select triggers; a window that only selects
+		// DetectionTemplates silences detection alone
+		types, _, _ := unstructured.NestedStringSlice(spec, "failureTypes")
+		templates, _, _ := unstructured.NestedStringSlice(spec, "templates")
+		if len(types) > 0 && !slices.Contains(types, trigger.Spec.FailureType) ||
+			len(types) == 0 && len(templates) > 0 {
+			continue
+		}
+		if namespaces, _, _ := unstructured.NestedStringSlice(spec, "namespaces"); len(namespaces) > 0 &&
+			!slices.Contains(namespaces, trigger.Namespace) {
+			continue
+		}
+		if raw, found, _ := unstructured.NestedMap(spec, "targetSelector"); found {
+			var ls metav1.LabelSelector
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &ls); err != nil {
+				continue
+			}
+			sel, err := metav1.LabelSelectorAsSelector(&ls)
+			if err != nil {
+				continue
+			}
+			if targetLabels == nil {
+				targetLabels = r.targetLabels(ctx, trigger)
+			}
+			if !slices.ContainsFunc(targetLabels, func(l labels.Set) bool { return sel.Matches(l) }) {
+				continue
+			}
+		}
+		return mw.GetName(), until, nil
+	}
+	return "", time.Time{}, nil
+}
+
+// openUntil returns the end of a window that its status reports as open at
+// now. A stale status whose end has passed counts as closed.
+func openUntil(mw *unstructured.Unstructured, now time.Time) (time.Time, bool) {
+	active, _, _ := unstructured.NestedBool(mw.Object, "status", "active")
+	raw, _, _ := unstructured.NestedString(mw.Object, "status", "activeUntil")
+	if !active || raw == "" {
+		return time.Time{}, false
+	}
+	until, err := time.Parse(time.RFC3339, raw)
+	if err != nil || !until.After(now) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// targetLabels returns the labels of the trigger's target Nodes and Pods,
+// pods being looked up in the trigger's namespace. Other kinds and targets
+// that cannot be read have no labels.
+func (r *RecoveryTriggerReconciler) targetLabels(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) []labels.Set {
+	out := []labels.Set{}
+	for _, t := range trigger.Spec.TargetObjects {
+		key := client.ObjectKey{Name: t.Name}
+		switch t.Kind {
+		case "Node":
+		case "Pod":
+			key.Namespace = trigger.Namespace
+		default:
+			out = append(out, labels.Set{})
+			continue
+		}
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind(t.Kind)
+		if err := r.Get(ctx, key, u); err != nil {
+			out = append(out, labels.Set{})
+			continue
+		}
+		out = append(out, labels.Set(u.GetLabels()))
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+func maintenanceWindow(name string, until time.Time, spec map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+		"status": map[string]interface{}{
+			"active":      true,
+			"activeUntil": until.UTC().Format(time.RFC3339),
+		},
+	}}
+	u.SetGroupVersionKind(maintenanceWindowListGVK.GroupVersion().WithKind("MaintenanceWindow"))
+	u.SetName(name)
+	return u
+}
+
+func TestHoldingWindow(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := recoveryv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"pool": "gpu"}}}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			node,
+			// Only silences detection
+			maintenanceWindow("a-detection-only", now.Add(time.Hour), map[string]interface{}{
+				"templates": []interface{}{"node-not-ready"},
+			}),
+			maintenanceWindow("b-gpu-upgrade", now.Add(time.Hour), map[string]interface{}{
+				"templates":      []interface{}{"node-not-ready"},
+				"failureTypes":   []interface{}{"NodeNotReady"},
+				"targetSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"pool": "gpu"}},
+			}),
+			// Its status was not refreshed after it closed
+			maintenanceWindow("c-stale", now.Add(-time.Minute), map[string]interface{}{}),
+		).
+		WithStatusSubresource(&recoveryv1alpha1.RecoveryTrigger{}).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+
+	trigger := func(name, failureType, node string) *recoveryv1alpha1.RecoveryTrigger {
+		return &recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "recovery", Name: name},
+			Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+				FailureType:      failureType,
+				WorkflowTemplate: "node-recovery",
+				TargetObjects:    []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: node}},
+			},
+		}
+	}
+	tests := []struct {
+		trigger *recoveryv1alpha1.RecoveryTrigger
+		want    string
+	}{
+		{trigger("gpu-node", "NodeNotReady", "worker-1"), "b-gpu-upgrade"},
+		{trigger("other-node", "NodeNotReady", "worker-2"), ""},
+		{trigger("disk-pressure", "DiskPressure", "worker-1"), ""},
+	}
+	for _, tt := range tests {
+		got, until, err := r.holdingWindow(context.Background(), tt.trigger, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want || (got != "" && !until.After(now)) {
+			t.Errorf("%s: window = %q until %s, want %q", tt.trigger.Name, got, until, tt.want)
+		}
+	}
+
+	// A held trigger is not submitted
+	t.Cleanup(func() {
+		triggersByState.DeletePartialMatch(prometheus.Labels{"namespace": "recovery"})
+		queueDepth.DeleteLabelValues("recovery")
+	})
+	held := tests[0].trigger
+	if err := c.Create(context.Background(), held); err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(held)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(held), held); err != nil {
+		t.Fatal(err)
+	}
+	if held.Status.State != "Held" || held.Status.WorkflowName != "" || res.RequeueAfter <= 0 {
+		t.Errorf("status = %+v, requeue = %s; want held and requeued", held.Status, res.RequeueAfter)
+	}
+}
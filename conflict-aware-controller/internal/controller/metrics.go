@@ -0,0 +1,234 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+const metricsNamespace = "recovery"
+
+var (
+	// triggersByState is the number of RecoveryTriggers in each state.
+	triggersByState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "triggers",
+			Help:      "Number of RecoveryTriggers per namespace and state.",
+		},
+		[]string{"namespace", "state"},
+	)
+
+	// conflictsTotal counts conflicts detected when a trigger was evaluated.
+	conflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "conflicts_total",
+			Help:      "Number of conflicts detected per conflict type.",
+		},
+		[]string{"type"},
+	)
+
+	// queueDepth is the number of triggers held back by a conflict.
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "queue_depth",
+			Help:      "Number of RecoveryTriggers waiting on a conflict per namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	// waitDuration measures the time between trigger creation and workflow submission.
+	waitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Time a RecoveryTrigger waited before its workflow was started.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"failure_type"},
+	)
+
+	// workflowDuration measures the run time of completed recovery workflows.
+	workflowDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "workflow_duration_seconds",
+			Help:      "Duration of completed recovery workflows per WorkflowTemplate.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{"workflow_template", "result"},
+	)
+
+	// workflowCompletionsTotal counts finished workflows by outcome.
+	workflowCompletionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "workflow_completions_total",
+			Help:      "Number of completed recovery workflows per WorkflowTemplate and result.",
+		},
+		[]string{"workflow_template", "result"},
+	)
+
+	// workflowSuccessRatio is the share of successful workflows per template
+	// since the controller started.
+	workflowSuccessRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "workflow_success_ratio",
+			Help:      "Ratio of successful to completed recovery workflows per WorkflowTemplate.",
+		},
+		[]string{"workflow_template"},
+	)
+
+	// statsRecoveries mirrors the counts of the persisted RecoveryStats, so
+	// they survive controller restarts.
+	statsRecoveries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "stats_recoveries",
+			Help:      "Recoveries per FailureType, WorkflowTemplate and outcome (attempted, succeeded, failed, escalated) since statistics began.",
+		},
+		[]string{"failure_type", "workflow_template", "outcome"},
+	)
+
+	// statsSuccessRatio is the persisted share of successful recoveries.
+	statsSuccessRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "stats_success_ratio",
+			Help:      "Ratio of successful to completed recoveries per FailureType and WorkflowTemplate.",
+		},
+		[]string{"failure_type", "workflow_template"},
+	)
+
+	// statsEscalationRatio is the persisted share of escalated recoveries.
+	statsEscalationRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "stats_escalation_ratio",
+			Help:      "Ratio of escalated to attempted recoveries per FailureType and WorkflowTemplate.",
+		},
+		[]string{"failure_type", "workflow_template"},
+	)
+
+	// statsDuration holds the percentiles of recent recovery durations.
+	statsDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "stats_duration_seconds",
+			Help:      "Percentiles of recent recovery workflow durations per FailureType and WorkflowTemplate.",
+		},
+		[]string{"failure_type", "workflow_template", "quantile"},
+	)
+)
+
+// completionTally keeps the counts behind workflowSuccessRatio.
+var completionTally = struct {
+	sync.Mutex
+	succeeded map[string]int
+	total     map[string]int
+}{succeeded: map[string]int{}, total: map[string]int{}}
+
+func init() {
+	// Register custom metrics with the global controller-runtime registry so
+	// they are served by the manager's metrics endpoint.
+	metrics.Registry.MustRegister(
+		triggersByState,
+		conflictsTotal,
+		queueDepth,
+		waitDuration,
+		workflowDuration,
+		workflowCompletionsTotal,
+		workflowSuccessRatio,
+		statsRecoveries,
+		statsSuccessRatio,
+		statsEscalationRatio,
+		statsDuration,
+	)
+}
+
+// recordTriggerStates refreshes the per-state gauges for one namespace.
+func recordTriggerStates(namespace string, triggers []recoveryv1alpha1.RecoveryTrigger) {
+	counts := map[string]int{}
+	waiting := 0
+	for _, t := range triggers {
+		state := t.Status.State
+		if state == "" {
+			state = "Pending"
+		}
+		counts[state]++
+		if isWaiting(state) {
+			waiting++
+		}
+	}
+
+	triggersByState.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+	for state, n := range counts {
+		triggersByState.WithLabelValues(namespace, state).Set(float64(n))
+	}
+	queueDepth.WithLabelValues(namespace).Set(float64(waiting))
+}
+
+// recordWorkflowCompletion records the outcome and duration of a finished workflow.
+func recordWorkflowCompletion(template, result string, seconds float64) {
+	workflowDuration.WithLabelValues(template, result).Observe(seconds)
+	workflowCompletionsTotal.WithLabelValues(template, result).Inc()
+
+	completionTally.Lock()
+	defer completionTally.Unlock()
+	completionTally.total[template]++
+	if result == "Succeeded" {
+		completionTally.succeeded[template]++
+	}
+	workflowSuccessRatio.WithLabelValues(template).Set(
+		float64(completionTally.succeeded[template]) / float64(completionTally.total[template]))
+}
+
+// recordRecoveryStats replaces the statistics gauges with the entries of a
+// RecoveryStats.
+func recordRecoveryStats(entries []recoveryv1alpha1.RecoveryStatsEntry) {
+	statsRecoveries.Reset()
+	statsSuccessRatio.Reset()
+	statsEscalationRatio.Reset()
+	statsDuration.Reset()
+	for _, e := range entries {
+		ft, tpl := e.FailureType, e.WorkflowTemplate
+		statsRecoveries.WithLabelValues(ft, tpl, "attempted").Set(float64(e.Attempts))
+		statsRecoveries.WithLabelValues(ft, tpl, "succeeded").Set(float64(e.Succeeded))
+		statsRecoveries.WithLabelValues(ft, tpl, "failed").Set(float64(e.Failed))
+		statsRecoveries.WithLabelValues(ft, tpl, "escalated").Set(float64(e.Escalated))
+		if done := e.Succeeded + e.Failed; done > 0 {
+			statsSuccessRatio.WithLabelValues(ft, tpl).Set(float64(e.Succeeded) / float64(done))
+		}
+		if e.Attempts > 0 {
+			statsEscalationRatio.WithLabelValues(ft, tpl).Set(float64(e.Escalated) / float64(e.Attempts))
+		}
+		if e.P50Duration != nil {
+			statsDuration.WithLabelValues(ft, tpl, "0.5").Set(e.P50Duration.Seconds())
+		}
+		if e.P95Duration != nil {
+			statsDuration.WithLabelValues(ft, tpl, "0.95").Set(e.P95Duration.Seconds())
+		}
+	}
+}
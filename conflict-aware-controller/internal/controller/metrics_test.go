@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+func triggerInState(state string) recoveryv1alpha1.RecoveryTrigger {
+	return recoveryv1alpha1.RecoveryTrigger{Status: recoveryv1alpha1.RecoveryTriggerStatus{State: state}}
+}
+
+func TestRecordTriggerStates(t *testing.T) {
+	recordTriggerStates("metrics-test", []recoveryv1alpha1.RecoveryTrigger{
+		triggerInState("Running"),
+		triggerInState("Suspended"),
+		triggerInState("Delayed"),
+		triggerInState(""),
+	})
+
+	if got := testutil.ToFloat64(triggersByState.WithLabelValues("metrics-test", "Running")); got != 1 {
+		t.Errorf("Running triggers = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(triggersByState.WithLabelValues("metrics-test", "Pending")); got != 1 {
+		t.Errorf("Pending triggers = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(queueDepth.WithLabelValues("metrics-test")); got != 2 {
+		t.Errorf("queue depth = %v, want 2", got)
+	}
+
+	// A state that disappears must not leave a stale series behind
+	recordTriggerStates("metrics-test", []recoveryv1alpha1.RecoveryTrigger{triggerInState("Succeeded")})
+	if got := testutil.CollectAndCount(triggersByState); got != 1 {
+		t.Errorf("state series = %d, want 1", got)
+	}
+}
+
+func TestRecordWorkflowCompletion(t *testing.T) {
+	recordWorkflowCompletion("ratio-test", "Succeeded", 10)
+	recordWorkflowCompletion("ratio-test", "Succeeded", 20)
+	recordWorkflowCompletion("ratio-test", "Failed", 5)
+	recordWorkflowCompletion("ratio-test", "Succeeded", 15)
+
+	if got := testutil.ToFloat64(workflowSuccessRatio.WithLabelValues("ratio-test")); got != 0.75 {
+		t.Errorf("success ratio = %v, want 0.75", got)
+	}
+	if got := testutil.ToFloat64(workflowCompletionsTotal.WithLabelValues("ratio-test", "Failed")); got != 1 {
+		t.Errorf("failed completions = %v, want 1", got)
+	}
+}
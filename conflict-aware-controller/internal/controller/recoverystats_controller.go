@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RecoveryStatsReconciler publishes the persisted RecoveryStats as metrics,
+// so the figures survive controller restarts. The RecoveryTriggerReconciler
+// keeps the statistics themselves.
+type RecoveryStatsReconciler struct {
+	client.Client
+}
+
+// Reconcile refreshes the statistics gauges from the RecoveryStats
+func (r *RecoveryStatsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != recoveryv1alpha1.RecoveryStatsName {
+		return ctrl.Result{}, nil
+	}
+	var stats recoveryv1alpha1.RecoveryStats
+	if err := r.Get(ctx, req.NamespacedName, &stats); err != nil {
+		if apierrors.IsNotFound(err) {
+			recordRecoveryStats(nil)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	recordRecoveryStats(stats.Status.Entries)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers controller with manager
+func (r *RecoveryStatsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&recoveryv1alpha1.RecoveryStats{}).
+		Complete(r)
+}
@@ -27,36 +27,126 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 )
 
+// waitRequeueInterval is how often a trigger held back by a conflict is re-evaluated.
+const waitRequeueInterval = 15 * time.Second
+
 // RecoveryTriggerReconciler reconciles RecoveryTrigger CRs
 type RecoveryTriggerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads around the cache, the cached client if nil. A
+	// workflow missing from the cache is confirmed with it before the
+	// trigger is failed.
+	APIReader client.Reader
+	// DryRun renders the workflow of every trigger instead of creating it,
+	// as spec.dryRun does for a single trigger.
+	DryRun bool
+	// Clock tells the time, the real one if nil. The simulator runs the
+	// reconciler on a virtual clock.
+	Clock clock.PassiveClock
+}
+
+// now returns the current time of the reconciler's clock.
+func (r *RecoveryTriggerReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
 }
 
+// apiReader returns the uncached reader, or the cached client if none is set.
+func (r *RecoveryTriggerReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverytriggers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverytriggers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverytriggers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=argoproj.io,resources=workflows,verbs=get;list;watch;create
+
 // Reconcile executes conflict detection and workflow submission
 func (r *RecoveryTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var trigger recoveryv1alpha1.RecoveryTrigger
-	if err := r.Get(ctx, req.NamespacedName, &trigger); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+	getErr := r.Get(ctx, req.NamespacedName, &trigger)
+	if getErr != nil && !apierrors.IsNotFound(getErr) {
+		return ctrl.Result{}, getErr
 	}
 
-	// Fetch all triggers in the same namespace
+	// Fetch all triggers in the same namespace. The gauges are refreshed
+	// for deleted triggers too, so that they stop being counted.
 	var triggerList recoveryv1alpha1.RecoveryTriggerList
 	if err := r.List(ctx, &triggerList, client.InNamespace(req.Namespace)); err != nil {
 		return ctrl.Result{}, err
 	}
+	recordTriggerStates(req.Namespace, triggerList.Items)
+	if getErr != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// A submitted workflow is followed until it completes
+	if trigger.Status.WorkflowName != "" {
+		return ctrl.Result{}, r.syncWorkflowStatus(ctx, &trigger)
+	}
+	if trigger.Status.State == "Discarded" {
+		return ctrl.Result{}, nil
+	}
+	// Dry-run triggers are evaluated again once dry run is turned off
+	dryRun := r.DryRun || trigger.Spec.DryRun
+	if trigger.Status.State == "DryRun" && dryRun {
+		return ctrl.Result{}, nil
+	}
+
+	// Triggers covered by an open maintenance window wait until it closes
+	window, until, err := r.holdingWindow(ctx, &trigger, r.now())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if window != "" {
+		reason := fmt.Sprintf("Held by MaintenanceWindow %s until %s", window, until.UTC().Format(time.RFC3339))
+		if trigger.Status.State != "Held" || trigger.Status.Reason != reason {
+			trigger.Status.State = "Held"
+			trigger.Status.Reason = reason
+			if err := r.Status().Update(ctx, &trigger); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.updateIncident(ctx, &trigger)
+		}
+		// Windows may also be closed early by deleting them
+		return ctrl.Result{RequeueAfter: max(min(until.Sub(r.now()), waitRequeueInterval), time.Second)}, nil
+	}
 
 	// Detect conflicts
-	conflict := detectConflicts(&trigger, triggerList.Items)
+	actions, err := r.triggerActions(ctx, &trigger, triggerList.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	conflict, blocker := detectConflicts(&trigger, triggerList.Items, actions)
 
 	originalState := trigger.Status.State
+	started := false
 
 	switch conflict {
 	case "None":
+		if dryRun {
+			rendered, err := r.renderWorkflow(&trigger)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			trigger.Status.State = "DryRun"
+			trigger.Status.Reason = "No conflicts, dry run: workflow rendered but not created"
+			trigger.Status.DryRunWorkflow = rendered
+			break
+		}
 		// If workflow not yet created → submit to Argo
 		if trigger.Status.WorkflowName == "" {
 			wfName, err := r.submitWorkflow(ctx, &trigger)
@@ -65,38 +155,168 @@ func (r *RecoveryTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			}
 			trigger.Status.State = "Running"
 			trigger.Status.Reason = "No conflicts, workflow started"
-			trigger.Status.StartedAt = &metav1.Time{Time: time.Now()}
+			trigger.Status.StartedAt = &metav1.Time{Time: r.now()}
 			trigger.Status.WorkflowName = wfName
-
-			fmt.Printf("[Controller] Submitted workflow %s using template %s\n", wfName, trigger.Spec.WorkflowTemplate)
+			trigger.Status.DryRunWorkflow = ""
+			started = true
+			waitDuration.WithLabelValues(trigger.Spec.FailureType).
+				Observe(trigger.Status.StartedAt.Sub(trigger.CreationTimestamp.Time).Seconds())
+			logf.FromContext(ctx).Info("Submitted workflow", "workflow", wfName, "template", trigger.Spec.WorkflowTemplate)
 		}
 
 	case "ResourceConflict":
 		trigger.Status.State = "Suspended"
-		trigger.Status.Reason = "Resource conflict detected"
+		trigger.Status.Reason = "Resource conflict detected with running trigger " + blocker
+
+	case "ActionConflict":
+		trigger.Status.State = "Suspended"
+		trigger.Status.Reason = "Action conflict detected with running trigger " + blocker
 
 	case "DependencyConflict":
 		trigger.Status.State = "Delayed"
-		trigger.Status.Reason = "Dependency conflict detected"
+		trigger.Status.Reason = "Dependency conflict detected with running trigger " + blocker
 
 	default:
 		trigger.Status.State = "Discarded"
 		trigger.Status.Reason = "Workflow discarded as unnecessary"
 	}
 
+	if conflict != "None" && trigger.Status.State != originalState {
+		conflictsTotal.WithLabelValues(conflict).Inc()
+	}
+
 	// Update status only if changed
 	if trigger.Status.State != originalState {
 		if err := r.Status().Update(ctx, &trigger); err != nil {
 			return ctrl.Result{}, err
 		}
+		r.updateIncident(ctx, &trigger)
+		if started {
+			r.recordRecoveryStart(ctx, &trigger, triggerList.Items)
+		}
 	}
 
+	// Triggers held back by a conflict are retried once the blocker may have finished
+	if isWaiting(trigger.Status.State) {
+		return ctrl.Result{RequeueAfter: waitRequeueInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// syncWorkflowStatus moves a Running trigger to Succeeded or Failed once its
+// workflow has completed. A trigger whose workflow was deleted fails.
+func (r *RecoveryTriggerReconciler) syncWorkflowStatus(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) error {
+	if trigger.Status.State != "Running" {
+		return nil
+	}
+
+	var wf argov1alpha1.Workflow
+	key := client.ObjectKey{Namespace: trigger.Namespace, Name: trigger.Status.WorkflowName}
+	err := r.Get(ctx, key, &wf)
+	if apierrors.IsNotFound(err) {
+		// The cache may not have seen a workflow that was just created
+		err = r.apiReader().Get(ctx, key, &wf)
+	}
+	if apierrors.IsNotFound(err) {
+		now := r.now()
+		started := now
+		if trigger.Status.StartedAt != nil {
+			started = trigger.Status.StartedAt.Time
+		}
+		return r.finishWorkflow(ctx, trigger, "Failed",
+			fmt.Sprintf("Workflow %s not found", trigger.Status.WorkflowName), started, now)
+	}
+	if err != nil {
+		return err
+	}
+	if !wf.Status.Phase.Completed() {
+		return nil
+	}
+
+	result := "Failed"
+	if wf.Status.Phase == argov1alpha1.WorkflowSucceeded {
+		result = "Succeeded"
+	}
+	started := wf.Status.StartedAt.Time
+	if started.IsZero() && trigger.Status.StartedAt != nil {
+		started = trigger.Status.StartedAt.Time
+	}
+	finished := wf.Status.FinishedAt.Time
+	if finished.IsZero() {
+		finished = r.now()
+	}
+	return r.finishWorkflow(ctx, trigger, result,
+		fmt.Sprintf("Workflow %s finished with phase %s", wf.Name, wf.Status.Phase), started, finished)
+}
+
+// finishWorkflow records the result of a Running trigger. Metrics and
+// statistics are only recorded once the new state is stored, so a conflict
+// that retries the reconcile does not count the completion twice.
+func (r *RecoveryTriggerReconciler) finishWorkflow(
+	ctx context.Context,
+	trigger *recoveryv1alpha1.RecoveryTrigger,
+	result, reason string,
+	started, finished time.Time,
+) error {
+	trigger.Status.State = result
+	trigger.Status.Reason = reason
+	trigger.Status.FinishedAt = &metav1.Time{Time: finished}
+	if err := r.Status().Update(ctx, trigger); err != nil {
+		return err
+	}
+	recordWorkflowCompletion(trigger.Spec.WorkflowTemplate, result, finished.Sub(started).Seconds())
+	r.updateIncident(ctx, trigger)
+	r.recordRecoveryFinish(ctx, trigger, finished.Sub(started))
+	return nil
+}
+
+// updateIncident records a state change of trigger in its Incident. The
+// incident is informational, so a failure does not hold back recovery.
+func (r *RecoveryTriggerReconciler) updateIncident(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) {
+	if err := r.recordIncident(ctx, trigger); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to record trigger in incident", "trigger", trigger.Name)
+	}
+}
+
+// isWaiting reports whether a trigger is queued behind a conflicting one.
+func isWaiting(state string) bool {
+	return state == "Suspended" || state == "Delayed"
+}
+
 // submitWorkflow creates an Argo Workflow from WorkflowTemplateRef
 func (r *RecoveryTriggerReconciler) submitWorkflow(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) (string, error) {
-	// Build workflow object
+	wf, err := r.workflowFor(trigger)
+	if err != nil {
+		return "", err
+	}
+
+	// Create workflow in cluster
+	if err := r.Create(ctx, wf); err != nil {
+		// if already exists, return existing workflow name
+		if apierrors.IsAlreadyExists(err) {
+			return wf.Name, nil
+		}
+		return "", err
+	}
+	return wf.Name, nil
+}
+
+// renderWorkflow returns the workflow submitWorkflow would create, as YAML.
+func (r *RecoveryTriggerReconciler) renderWorkflow(trigger *recoveryv1alpha1.RecoveryTrigger) (string, error) {
+	wf, err := r.workflowFor(trigger)
+	if err != nil {
+		return "", err
+	}
+	wf.TypeMeta = metav1.TypeMeta{APIVersion: argov1alpha1.SchemeGroupVersion.String(), Kind: "Workflow"}
+	out, err := yaml.Marshal(wf)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// workflowFor builds the Argo Workflow of trigger.
+func (r *RecoveryTriggerReconciler) workflowFor(trigger *recoveryv1alpha1.RecoveryTrigger) (*argov1alpha1.Workflow, error) {
 	wf := &argov1alpha1.Workflow{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("%s-", trigger.Name),
@@ -108,42 +328,44 @@ func (r *RecoveryTriggerReconciler) submitWorkflow(ctx context.Context, trigger
 			},
 		},
 	}
-
-	// Create workflow in cluster
-	if err := r.Create(ctx, wf); err != nil {
-		// if already exists, return existing workflow name
-		if apierrors.IsAlreadyExists(err) {
-			return wf.Name, nil
-		}
-		return "", err
+	// Owning the workflow lets its completion requeue the trigger
+	if err := ctrl.SetControllerReference(trigger, wf, r.Scheme); err != nil {
+		return nil, err
 	}
-	return wf.Name, nil
+	return wf, nil
 }
 
-// detectConflicts checks if new trigger overlaps with running ones
-func detectConflicts(new *recoveryv1alpha1.RecoveryTrigger, running []recoveryv1alpha1.RecoveryTrigger) string {
+// detectConflicts checks if new trigger overlaps with running ones, and
+// returns the conflict with the name of the running trigger it is with.
+// Triggers whose workflows both declare step actions conflict when the
+// action matrix says so, others when they share a target.
+func detectConflicts(new *recoveryv1alpha1.RecoveryTrigger, running []recoveryv1alpha1.RecoveryTrigger, actions map[string][]stepAction) (string, string) {
 	for _, t := range running {
+		if t.Name == new.Name {
+			continue
+		}
 		if t.Status.State == "Running" {
 			// Check same resource conflict
-			for _, obj1 := range new.Spec.TargetObjects {
-				for _, obj2 := range t.Spec.TargetObjects {
-					if obj1.Kind == obj2.Kind && obj1.Name == obj2.Name {
-						return "ResourceConflict"
-					}
+			if newActions, runningActions := actions[new.Name], actions[t.Name]; len(newActions) > 0 && len(runningActions) > 0 {
+				if detail, ok := actionConflict(newActions, runningActions); ok {
+					return "ActionConflict", t.Name + ": " + detail
 				}
+			} else if sharesTarget(new, &t) {
+				return "ResourceConflict", t.Name
 			}
 			// Check dependency conflict (same failure type)
 			if new.Spec.FailureType == t.Spec.FailureType {
-				return "DependencyConflict"
+				return "DependencyConflict", t.Name
 			}
 		}
 	}
-	return "None"
+	return "None", ""
 }
 
 // SetupWithManager registers controller with manager
 func (r *RecoveryTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&recoveryv1alpha1.RecoveryTrigger{}).
+		Owns(&argov1alpha1.Workflow{}).
 		Complete(r)
 }
@@ -18,11 +18,21 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"testing"
 
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -82,3 +92,168 @@ var _ = Describe("RecoveryTrigger Controller", func() {
 		})
 	})
 })
+
+func TestReconcileDryRun(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{recoveryv1alpha1.AddToScheme, argov1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trigger := func(name, node string, dryRun bool) *recoveryv1alpha1.RecoveryTrigger {
+		return &recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dry-run", Name: name},
+			Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+				FailureType:      "NodeNotReady-" + node,
+				WorkflowTemplate: "node-recovery",
+				TargetObjects:    []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: node}},
+				DryRun:           dryRun,
+			},
+		}
+	}
+	running := trigger("running", "worker-1", false)
+	running.Status = recoveryv1alpha1.RecoveryTriggerStatus{State: "Running", WorkflowName: "running-x1"}
+	conflicting := trigger("conflicting", "worker-1", true)
+	free := trigger("free", "worker-2", true)
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(running, conflicting, free).
+		WithStatusSubresource(&recoveryv1alpha1.RecoveryTrigger{}).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+	t.Cleanup(func() {
+		triggersByState.DeletePartialMatch(prometheus.Labels{"namespace": "dry-run"})
+		queueDepth.DeleteLabelValues("dry-run")
+	})
+	reconcileTrigger := func(rt *recoveryv1alpha1.RecoveryTrigger) {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(rt)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(rt), rt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workflows := func() int {
+		t.Helper()
+		var list argov1alpha1.WorkflowList
+		if err := c.List(ctx, &list); err != nil {
+			t.Fatal(err)
+		}
+		return len(list.Items)
+	}
+
+	// Conflicts are decided as usual; free triggers render their workflow
+	reconcileTrigger(conflicting)
+	if conflicting.Status.State != "Suspended" {
+		t.Errorf("state = %s, want the conflict decided", conflicting.Status.State)
+	}
+	reconcileTrigger(free)
+	if free.Status.State != "DryRun" || free.Status.WorkflowName != "" ||
+		!strings.Contains(free.Status.DryRunWorkflow, "name: node-recovery") {
+		t.Errorf("status = %+v, want the workflow rendered", free.Status)
+	}
+	if n := workflows(); n != 0 {
+		t.Errorf("workflows = %d, want none created", n)
+	}
+
+	// The trigger is submitted once dry run is turned off
+	free.Spec.DryRun = false
+	if err := c.Update(ctx, free); err != nil {
+		t.Fatal(err)
+	}
+	reconcileTrigger(free)
+	if free.Status.State != "Running" || free.Status.DryRunWorkflow != "" || workflows() != 1 {
+		t.Errorf("status = %+v, want the workflow submitted", free.Status)
+	}
+}
+
+func TestSyncWorkflowStatusMissingWorkflow(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{recoveryv1alpha1.AddToScheme, argov1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trigger := &recoveryv1alpha1.RecoveryTrigger{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "missing-workflow", Name: "restart"},
+		Spec:       recoveryv1alpha1.RecoveryTriggerSpec{WorkflowTemplate: "missing-workflow-test"},
+		Status:     recoveryv1alpha1.RecoveryTriggerStatus{State: "Running", WorkflowName: "restart-x1"},
+	}
+	conflicts := 1
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(trigger).
+		WithStatusSubresource(&recoveryv1alpha1.RecoveryTrigger{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, sub string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if conflicts > 0 {
+					conflicts--
+					return errors.NewConflict(recoveryv1alpha1.GroupVersion.WithResource("recoverytriggers").GroupResource(), obj.GetName(), nil)
+				}
+				return c.SubResource(sub).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+	t.Cleanup(func() {
+		triggersByState.DeletePartialMatch(prometheus.Labels{"namespace": "missing-workflow"})
+		queueDepth.DeleteLabelValues("missing-workflow")
+	})
+	failed := workflowCompletionsTotal.WithLabelValues("missing-workflow-test", "Failed")
+	key := client.ObjectKeyFromObject(trigger)
+
+	// A status update that conflicts records nothing; the retry does
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); !errors.IsConflict(err) {
+		t.Fatalf("err = %v, want a conflict", err)
+	}
+	if n := testutil.ToFloat64(failed); n != 0 {
+		t.Errorf("completions = %v before the status was stored, want 0", n)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, key, trigger); err != nil {
+		t.Fatal(err)
+	}
+	if trigger.Status.State != "Failed" || trigger.Status.Reason != "Workflow restart-x1 not found" || trigger.Status.FinishedAt == nil {
+		t.Errorf("status = %+v, want failed for the missing workflow", trigger.Status)
+	}
+	if n := testutil.ToFloat64(failed); n != 1 {
+		t.Errorf("completions = %v, want 1", n)
+	}
+}
+
+func TestReconcileDeletedTriggerRefreshesGauges(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := recoveryv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	waiting := &recoveryv1alpha1.RecoveryTrigger{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "deleted-trigger", Name: "restart"},
+		Status:     recoveryv1alpha1.RecoveryTriggerStatus{State: "Suspended"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(waiting).Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+	t.Cleanup(func() {
+		triggersByState.DeletePartialMatch(prometheus.Labels{"namespace": "deleted-trigger"})
+		queueDepth.DeleteLabelValues("deleted-trigger")
+	})
+	recordTriggerStates("deleted-trigger", []recoveryv1alpha1.RecoveryTrigger{*waiting})
+
+	if err := c.Delete(ctx, waiting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(waiting)}); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.ToFloat64(queueDepth.WithLabelValues("deleted-trigger")); n != 0 {
+		t.Errorf("queue depth = %v, want the deleted trigger dropped", n)
+	}
+	if n := testutil.ToFloat64(triggersByState.WithLabelValues("deleted-trigger", "Suspended")); n != 0 {
+		t.Errorf("suspended = %v, want 0", n)
+	}
+}
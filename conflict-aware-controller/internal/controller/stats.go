@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// escalationWindow is how soon after a recovery finished another trigger
+	// on the same target counts it as escalated.
+	escalationWindow = time.Hour
+	// defaultDurationSamples is the durationSamples of a RecoveryStats the
+	// controller creates.
+	defaultDurationSamples = 100
+)
+
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverystats,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverystats/status,verbs=get;update;patch
+
+// recordRecoveryStart counts the workflow trigger started, and the recovery
+// it escalates, if any. Statistics are informational, so a failure does not
+// hold back recovery.
+func (r *RecoveryTriggerReconciler) recordRecoveryStart(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger, triggers []recoveryv1alpha1.RecoveryTrigger) {
+	now := r.now()
+	escalated := escalatedTrigger(trigger, triggers, now)
+	err := r.updateStats(ctx, func(st *recoveryv1alpha1.RecoveryStatsStatus) {
+		statsEntry(st, trigger, now).Attempts++
+		if escalated != nil {
+			statsEntry(st, escalated, now).Escalated++
+		}
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "unable to record recovery start", "trigger", trigger.Name)
+	}
+}
+
+// recordRecoveryFinish counts the result and duration of the workflow of
+// trigger.
+func (r *RecoveryTriggerReconciler) recordRecoveryFinish(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger, duration time.Duration) {
+	err := r.updateStats(ctx, func(st *recoveryv1alpha1.RecoveryStatsStatus) {
+		e := statsEntry(st, trigger, r.now())
+		if trigger.Status.State == "Succeeded" {
+			e.Succeeded++
+		} else {
+			e.Failed++
+		}
+		e.RecentDurations = append(e.RecentDurations, int64(math.Round(duration.Seconds())))
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "unable to record recovery result", "trigger", trigger.Name)
+	}
+}
+
+// updateStats applies change to the RecoveryStats, creating it on first use,
+// and refreshes the derived figures.
+func (r *RecoveryTriggerReconciler) updateStats(ctx context.Context, change func(*recoveryv1alpha1.RecoveryStatsStatus)) error {
+	// A RecoveryStats created concurrently is picked up on retry
+	retriable := func(err error) bool { return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) }
+	return retry.OnError(retry.DefaultBackoff, retriable, func() error {
+		var stats recoveryv1alpha1.RecoveryStats
+		if err := r.Get(ctx, client.ObjectKey{Name: recoveryv1alpha1.RecoveryStatsName}, &stats); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			stats = recoveryv1alpha1.RecoveryStats{
+				ObjectMeta: metav1.ObjectMeta{Name: recoveryv1alpha1.RecoveryStatsName},
+				Spec:       recoveryv1alpha1.RecoveryStatsSpec{DurationSamples: defaultDurationSamples},
+			}
+			if err := r.Create(ctx, &stats); err != nil {
+				return err
+			}
+		}
+		change(&stats.Status)
+		samples := int(stats.Spec.DurationSamples)
+		if samples <= 0 {
+			samples = defaultDurationSamples
+		}
+		for i := range stats.Status.Entries {
+			summarizeStatsEntry(&stats.Status.Entries[i], samples)
+		}
+		return r.Status().Update(ctx, &stats)
+	})
+}
+
+// statsEntry returns the entry for the FailureType and WorkflowTemplate of
+// trigger, adding it in order if missing, and marks it updated at now.
+func statsEntry(st *recoveryv1alpha1.RecoveryStatsStatus, trigger *recoveryv1alpha1.RecoveryTrigger, now time.Time) *recoveryv1alpha1.RecoveryStatsEntry {
+	ft, tpl := trigger.Spec.FailureType, trigger.Spec.WorkflowTemplate
+	i := sort.Search(len(st.Entries), func(i int) bool {
+		e := st.Entries[i]
+		return e.FailureType > ft || e.FailureType == ft && e.WorkflowTemplate >= tpl
+	})
+	if i == len(st.Entries) || st.Entries[i].FailureType != ft || st.Entries[i].WorkflowTemplate != tpl {
+		st.Entries = slices.Insert(st.Entries, i, recoveryv1alpha1.RecoveryStatsEntry{FailureType: ft, WorkflowTemplate: tpl})
+	}
+	st.Entries[i].LastUpdated = &metav1.Time{Time: now}
+	return &st.Entries[i]
+}
+
+// summarizeStatsEntry keeps the newest samples durations of e and derives
+// its rates and percentiles.
+func summarizeStatsEntry(e *recoveryv1alpha1.RecoveryStatsEntry, samples int) {
+	if n := len(e.RecentDurations); n > samples {
+		e.RecentDurations = e.RecentDurations[n-samples:]
+	}
+	e.SuccessPercent, e.EscalationPercent = 0, 0
+	if done := e.Succeeded + e.Failed; done > 0 {
+		e.SuccessPercent = int32(e.Succeeded * 100 / done)
+	}
+	if e.Attempts > 0 {
+		e.EscalationPercent = int32(min(e.Escalated, e.Attempts) * 100 / e.Attempts)
+	}
+	e.P50Duration, e.P95Duration = nil, nil
+	if len(e.RecentDurations) > 0 {
+		sorted := slices.Sorted(slices.Values(e.RecentDurations))
+		e.P50Duration = &metav1.Duration{Duration: time.Duration(percentile(sorted, 0.5)) * time.Second}
+		e.P95Duration = &metav1.Duration{Duration: time.Duration(percentile(sorted, 0.95)) * time.Second}
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// escalatedTrigger returns the most recently finished trigger that recovered
+// a target of trigger within the escalation window, or nil.
+func escalatedTrigger(trigger *recoveryv1alpha1.RecoveryTrigger, triggers []recoveryv1alpha1.RecoveryTrigger, now time.Time) *recoveryv1alpha1.RecoveryTrigger {
+	var latest *recoveryv1alpha1.RecoveryTrigger
+	for i := range triggers {
+		t := &triggers[i]
+		if t.Name == trigger.Name || t.Status.FinishedAt == nil || now.Sub(t.Status.FinishedAt.Time) > escalationWindow {
+			continue
+		}
+		if latest != nil && !latest.Status.FinishedAt.Before(t.Status.FinishedAt) {
+			continue
+		}
+		if sharesTarget(trigger, t) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// sharesTarget reports whether two triggers have a target object in common.
+func sharesTarget(a, b *recoveryv1alpha1.RecoveryTrigger) bool {
+	for _, obj1 := range a.Spec.TargetObjects {
+		for _, obj2 := range b.Spec.TargetObjects {
+			if obj1.Kind == obj2.Kind && obj1.Name == obj2.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
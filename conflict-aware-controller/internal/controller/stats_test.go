@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+func TestRecoveryStats(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := recoveryv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&recoveryv1alpha1.RecoveryStats{}).
+		Build()
+	r := &RecoveryTriggerReconciler{Client: c, Scheme: scheme}
+
+	trigger := func(name, template, node string) *recoveryv1alpha1.RecoveryTrigger {
+		return &recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "recovery", Name: name},
+			Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+				FailureType:      "NodeNotReady",
+				WorkflowTemplate: template,
+				TargetObjects:    []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: node}},
+			},
+		}
+	}
+	finish := func(rt *recoveryv1alpha1.RecoveryTrigger, state string, ago time.Duration) {
+		rt.Status.State = state
+		rt.Status.FinishedAt = &metav1.Time{Time: time.Now().Add(-ago)}
+	}
+
+	// A restart that fails, followed within the hour by a drain that works
+	restart := trigger("restart-worker-1", "restart-kubelet", "worker-1")
+	r.recordRecoveryStart(ctx, restart, nil)
+	finish(restart, "Failed", 20*time.Minute)
+	r.recordRecoveryFinish(ctx, restart, 30*time.Second)
+
+	drain := trigger("drain-worker-1", "drain-node", "worker-1")
+	r.recordRecoveryStart(ctx, drain, []recoveryv1alpha1.RecoveryTrigger{*restart})
+	for i, d := range []time.Duration{10, 20, 30, 40, 200} {
+		if i > 0 {
+			r.recordRecoveryStart(ctx, trigger("drain-worker-2", "drain-node", "worker-2"), nil)
+		}
+		finish(drain, "Succeeded", 0)
+		r.recordRecoveryFinish(ctx, drain, d*time.Second)
+	}
+
+	var stats recoveryv1alpha1.RecoveryStats
+	if err := c.Get(ctx, client.ObjectKey{Name: recoveryv1alpha1.RecoveryStatsName}, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(stats.Status.Entries); n != 2 {
+		t.Fatalf("entries = %d, want 2", n)
+	}
+	drained, restarted := stats.Status.Entries[0], stats.Status.Entries[1]
+	if restarted.WorkflowTemplate != "restart-kubelet" || restarted.Attempts != 1 || restarted.Failed != 1 ||
+		restarted.Escalated != 1 || restarted.EscalationPercent != 100 || restarted.SuccessPercent != 0 {
+		t.Errorf("restart entry = %+v", restarted)
+	}
+	if drained.Attempts != 5 || drained.Succeeded != 5 || drained.Escalated != 0 || drained.SuccessPercent != 100 {
+		t.Errorf("drain entry = %+v", drained)
+	}
+	if drained.P50Duration.Duration != 30*time.Second || drained.P95Duration.Duration != 200*time.Second {
+		t.Errorf("durations = %v, %v; want 30s and 200s", drained.P50Duration, drained.P95Duration)
+	}
+
+	// Only the configured number of durations is kept
+	stats.Spec.DurationSamples = 2
+	if err := c.Update(ctx, &stats); err != nil {
+		t.Fatal(err)
+	}
+	r.recordRecoveryFinish(ctx, drain, 50*time.Second)
+	if err := c.Get(ctx, client.ObjectKey{Name: recoveryv1alpha1.RecoveryStatsName}, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if got := stats.Status.Entries[0].RecentDurations; len(got) != 2 || got[0] != 200 || got[1] != 50 {
+		t.Errorf("durations = %v, want [200 50]", got)
+	}
+
+	// The metrics are published from the persisted entries
+	sr := &RecoveryStatsReconciler{Client: c}
+	t.Cleanup(func() { recordRecoveryStats(nil) })
+	if _, err := sr.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: recoveryv1alpha1.RecoveryStatsName}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(statsEscalationRatio.WithLabelValues("NodeNotReady", "restart-kubelet")); got != 1 {
+		t.Errorf("escalation ratio = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(statsRecoveries.WithLabelValues("NodeNotReady", "drain-node", "succeeded")); got != 6 {
+		t.Errorf("succeeded = %v, want 6", got)
+	}
+}
+
+func TestEscalatedTrigger(t *testing.T) {
+	now := time.Now()
+	finished := func(name, node string, ago time.Duration) recoveryv1alpha1.RecoveryTrigger {
+		return recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       recoveryv1alpha1.RecoveryTriggerSpec{TargetObjects: []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: node}}},
+			Status:     recoveryv1alpha1.RecoveryTriggerStatus{FinishedAt: &metav1.Time{Time: now.Add(-ago)}},
+		}
+	}
+	triggers := []recoveryv1alpha1.RecoveryTrigger{
+		finished("old", "worker-1", 2*time.Hour),
+		finished("earlier", "worker-1", 30*time.Minute),
+		finished("latest", "worker-1", 10*time.Minute),
+		finished("other-node", "worker-2", time.Minute),
+		{ObjectMeta: metav1.ObjectMeta{Name: "running"}, Spec: finished("", "worker-1", 0).Spec},
+	}
+	next := finished("new", "worker-1", 0)
+	next.Status.FinishedAt = nil
+	if got := escalatedTrigger(&next, triggers, now); got == nil || got.Name != "latest" {
+		t.Errorf("escalated = %v, want latest", got)
+	}
+	if got := escalatedTrigger(&next, triggers[:1], now); got != nil {
+		t.Errorf("escalated = %s, want none outside the window", got.Name)
+	}
+}
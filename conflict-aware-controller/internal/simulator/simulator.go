@@ -0,0 +1,493 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator replays a timeline of RecoveryTrigger creations and
+// workflow completions through the controller's RecoveryTriggerReconciler,
+// against a fake API server and on a virtual clock. Triggers are reconciled
+// as the controller would be: on creation, on their own status updates, on
+// completion of their workflow and when a reconcile asks to be requeued.
+package simulator
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+	"github.com/phuongbac/conflictawareworkflowcontroller/internal/controller"
+)
+
+const (
+	// defaultHorizon is how long a timeline is simulated unless it says
+	// otherwise.
+	defaultHorizon = 24 * time.Hour
+	// errorRequeue is how soon a reconcile that failed is retried.
+	errorRequeue = time.Second
+	// maxSteps bounds a simulation whose reconciles keep requeueing each
+	// other without the clock advancing.
+	maxSteps = 100000
+)
+
+// Timeline is the input of a simulation.
+type Timeline struct {
+	// Start of the virtual clock, a fixed date if unset so that runs are
+	// reproducible
+	Start *metav1.Time `json:"start,omitempty"`
+	// Horizon after which the simulation stops, 24h if unset
+	Horizon *metav1.Duration `json:"horizon,omitempty"`
+	// Namespace of the triggers, default if unset
+	Namespace string `json:"namespace,omitempty"`
+	// WorkflowTemplates the triggers reference, whose step action
+	// annotations decide conflicts between triggers that use them
+	WorkflowTemplates []argov1alpha1.WorkflowTemplate `json:"workflowTemplates,omitempty"`
+	// Events in the order they happen
+	Events []Event `json:"events"`
+}
+
+// Event is a trigger creation or a workflow completion. Exactly one of
+// Create and Complete is set.
+type Event struct {
+	// At is the time of the event after the start of the timeline
+	At       metav1.Duration `json:"at"`
+	Create   *Trigger        `json:"create,omitempty"`
+	Complete *Completion     `json:"complete,omitempty"`
+}
+
+// Trigger is a RecoveryTrigger to create.
+type Trigger struct {
+	Name                                 string `json:"name"`
+	recoveryv1alpha1.RecoveryTriggerSpec `json:",inline"`
+	// Workflow says how the workflow of the trigger runs once submitted. If
+	// unset it runs until a Completion event.
+	Workflow *WorkflowRun `json:"workflow,omitempty"`
+}
+
+// WorkflowRun is how long a workflow runs and how it ends.
+type WorkflowRun struct {
+	Duration metav1.Duration `json:"duration"`
+	// Phase the workflow ends in: Succeeded, Failed or Error. Defaults to
+	// Succeeded.
+	Phase argov1alpha1.WorkflowPhase `json:"phase,omitempty"`
+}
+
+// Completion completes the running workflow of a trigger.
+type Completion struct {
+	Trigger string `json:"trigger"`
+	// Phase the workflow ends in: Succeeded, Failed or Error. Defaults to
+	// Succeeded.
+	Phase argov1alpha1.WorkflowPhase `json:"phase,omitempty"`
+}
+
+// Decision is a change of a trigger during the simulation.
+type Decision struct {
+	// At is the time of the decision after the start of the timeline
+	At      metav1.Duration `json:"at"`
+	Trigger string          `json:"trigger"`
+	// State of the trigger after the decision, empty on creation
+	State  string `json:"state,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// TriggerSummary is the outcome of a trigger. Times are after the start of
+// the timeline.
+type TriggerSummary struct {
+	Name        string           `json:"name"`
+	FailureType string           `json:"failureType"`
+	State       string           `json:"state"`
+	Reason      string           `json:"reason"`
+	Created     metav1.Duration  `json:"created"`
+	Started     *metav1.Duration `json:"started,omitempty"`
+	Finished    *metav1.Duration `json:"finished,omitempty"`
+	// QueueWait is how long the trigger waited for its workflow to start
+	QueueWait *metav1.Duration `json:"queueWait,omitempty"`
+	// RecoveryTime is how long the trigger took from creation until its
+	// workflow finished
+	RecoveryTime *metav1.Duration `json:"recoveryTime,omitempty"`
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	Decisions []Decision       `json:"decisions"`
+	Triggers  []TriggerSummary `json:"triggers"`
+	// TotalQueueWait sums the queue waits of the triggers that started
+	TotalQueueWait metav1.Duration `json:"totalQueueWait"`
+	// Makespan is the time the last workflow finished, or the horizon if a
+	// trigger is left unfinished
+	Makespan metav1.Duration `json:"makespan"`
+}
+
+// Load decodes a YAML or JSON timeline.
+func Load(data []byte) (*Timeline, error) {
+	var tl Timeline
+	if err := yaml.UnmarshalStrict(data, &tl); err != nil {
+		return nil, err
+	}
+	if len(tl.Events) == 0 {
+		return nil, errors.New("timeline has no events")
+	}
+	for i, wft := range tl.WorkflowTemplates {
+		if wft.Name == "" {
+			return nil, fmt.Errorf("workflowTemplates[%d]: metadata.name is required", i)
+		}
+	}
+	created := map[string]bool{}
+	for i, ev := range tl.Events {
+		switch {
+		case ev.At.Duration < 0:
+			return nil, fmt.Errorf("events[%d]: at must not be negative", i)
+		case (ev.Create == nil) == (ev.Complete == nil):
+			return nil, fmt.Errorf("events[%d]: exactly one of create and complete is required", i)
+		case ev.Create != nil:
+			if ev.Create.Name == "" {
+				return nil, fmt.Errorf("events[%d]: create.name is required", i)
+			}
+			if created[ev.Create.Name] {
+				return nil, fmt.Errorf("events[%d]: trigger %s is created twice", i, ev.Create.Name)
+			}
+			created[ev.Create.Name] = true
+			if w := ev.Create.Workflow; w != nil {
+				if err := validPhase(w.Phase); err != nil {
+					return nil, fmt.Errorf("events[%d]: create.workflow.phase: %w", i, err)
+				}
+			}
+		default:
+			if !created[ev.Complete.Trigger] {
+				return nil, fmt.Errorf("events[%d]: trigger %q is completed before it is created", i, ev.Complete.Trigger)
+			}
+			if err := validPhase(ev.Complete.Phase); err != nil {
+				return nil, fmt.Errorf("events[%d]: complete.phase: %w", i, err)
+			}
+		}
+	}
+	return &tl, nil
+}
+
+func validPhase(phase argov1alpha1.WorkflowPhase) error {
+	if phase == "" || phase.Completed() {
+		return nil
+	}
+	return fmt.Errorf("%q is not a completed phase", phase)
+}
+
+// step kinds
+const (
+	stepEvent = iota
+	stepFinish
+	stepReconcile
+)
+
+// step is an item of the simulation queue.
+type step struct {
+	at    time.Time
+	seq   int
+	kind  int
+	event *Event
+	// trigger to reconcile or whose workflow finishes
+	trigger string
+	phase   argov1alpha1.WorkflowPhase
+}
+
+// queue orders steps by time, then by the order they were added.
+type queue []*step
+
+func (q queue) Len() int { return len(q) }
+func (q queue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)   { *q = append(*q, x.(*step)) }
+func (q *queue) Pop() any {
+	old := *q
+	s := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return s
+}
+
+// simulation is the state of one Run.
+type simulation struct {
+	client    client.Client
+	clock     *clocktesting.FakePassiveClock
+	r         *controller.RecoveryTriggerReconciler
+	namespace string
+	start     time.Time
+
+	queue queue
+	seq   int
+	// workflows counts the workflows created
+	workflows int
+	// pending is the earliest queued reconcile of each trigger; later ones
+	// are dropped as a workqueue would
+	pending map[string]time.Time
+	runs    map[string]*WorkflowRun
+	report  Report
+	order   []string
+}
+
+// Run simulates tl and reports the decisions taken.
+func Run(ctx context.Context, tl *Timeline) (*Report, error) {
+	scheme := runtime.NewScheme()
+	if err := recoveryv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := argov1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if tl.Start != nil {
+		start = tl.Start.UTC()
+	}
+	horizon := defaultHorizon
+	if tl.Horizon != nil {
+		horizon = tl.Horizon.Duration
+	}
+	namespace := tl.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	s := &simulation{
+		clock:     clocktesting.NewFakePassiveClock(start),
+		namespace: namespace,
+		start:     start,
+		pending:   map[string]time.Time{},
+		runs:      map[string]*WorkflowRun{},
+		report:    Report{Decisions: []Decision{}, Triggers: []TriggerSummary{}},
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range tl.WorkflowTemplates {
+		wft := tl.WorkflowTemplates[i].DeepCopy()
+		wft.Namespace = namespace
+		builder = builder.WithObjects(wft)
+	}
+	s.client = builder.
+		WithStatusSubresource(&recoveryv1alpha1.RecoveryTrigger{}, &recoveryv1alpha1.RecoveryStats{}).
+		WithInterceptorFuncs(interceptor.Funcs{Create: s.create}).
+		Build()
+	s.r = &controller.RecoveryTriggerReconciler{Client: s.client, Scheme: scheme, Clock: s.clock}
+
+	events := slices.Clone(tl.Events)
+	slices.SortStableFunc(events, func(a, b Event) int { return int(a.At.Duration - b.At.Duration) })
+	for i := range events {
+		s.push(&step{at: start.Add(events[i].At.Duration), kind: stepEvent, event: &events[i]})
+	}
+
+	end := start.Add(horizon)
+	for steps := 0; s.queue.Len() > 0; steps++ {
+		if steps == maxSteps {
+			return nil, fmt.Errorf("simulation did not settle after %d steps", maxSteps)
+		}
+		next := heap.Pop(&s.queue).(*step)
+		if next.at.After(end) {
+			break
+		}
+		s.clock.SetTime(next.at)
+		var err error
+		switch next.kind {
+		case stepEvent:
+			err = s.apply(ctx, next.event)
+		case stepFinish:
+			err = s.finish(ctx, next.trigger, next.phase)
+		case stepReconcile:
+			if !s.pending[next.trigger].Equal(next.at) {
+				continue
+			}
+			delete(s.pending, next.trigger)
+			err = s.reconcile(ctx, next.trigger)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.summarize(ctx, end)
+}
+
+// create names generated workflows predictably, so that reports of the same
+// timeline are identical.
+func (s *simulation) create(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetName() == "" && obj.GetGenerateName() != "" {
+		s.workflows++
+		obj.SetName(fmt.Sprintf("%s%d", obj.GetGenerateName(), s.workflows))
+	}
+	return c.Create(ctx, obj, opts...)
+}
+
+func (s *simulation) push(st *step) {
+	s.seq++
+	st.seq = s.seq
+	heap.Push(&s.queue, st)
+}
+
+// enqueue reconciles trigger after d, unless it is already due sooner.
+func (s *simulation) enqueue(trigger string, d time.Duration) {
+	at := s.clock.Now().Add(d)
+	if pending, ok := s.pending[trigger]; ok && !pending.After(at) {
+		return
+	}
+	s.pending[trigger] = at
+	s.push(&step{at: at, kind: stepReconcile, trigger: trigger})
+}
+
+func (s *simulation) decide(trigger, state, reason string) {
+	s.report.Decisions = append(s.report.Decisions, Decision{
+		At:      metav1.Duration{Duration: s.clock.Now().Sub(s.start)},
+		Trigger: trigger,
+		State:   state,
+		Reason:  reason,
+	})
+}
+
+func (s *simulation) apply(ctx context.Context, ev *Event) error {
+	if ev.Complete != nil {
+		return s.finish(ctx, ev.Complete.Trigger, ev.Complete.Phase)
+	}
+	t := ev.Create
+	trigger := &recoveryv1alpha1.RecoveryTrigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         s.namespace,
+			Name:              t.Name,
+			CreationTimestamp: metav1.Time{Time: s.clock.Now()},
+		},
+		Spec: t.RecoveryTriggerSpec,
+	}
+	if err := s.client.Create(ctx, trigger); err != nil {
+		return fmt.Errorf("creating trigger %s: %w", t.Name, err)
+	}
+	s.runs[t.Name] = t.Workflow
+	s.order = append(s.order, t.Name)
+	s.decide(t.Name, "", "Created")
+	s.enqueue(t.Name, 0)
+	return nil
+}
+
+// reconcile runs the reconciler for trigger and queues what the change it
+// made would queue in the controller.
+func (s *simulation) reconcile(ctx context.Context, name string) error {
+	key := client.ObjectKey{Namespace: s.namespace, Name: name}
+	var before recoveryv1alpha1.RecoveryTrigger
+	if err := s.client.Get(ctx, key, &before); err != nil {
+		return err
+	}
+	res, err := s.r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		s.decide(name, before.Status.State, "Reconcile failed: "+err.Error())
+		s.enqueue(name, errorRequeue)
+		return nil
+	}
+	var after recoveryv1alpha1.RecoveryTrigger
+	if err := s.client.Get(ctx, key, &after); err != nil {
+		return err
+	}
+	if after.ResourceVersion != before.ResourceVersion {
+		// The update is watched, and reconciles the trigger again
+		s.enqueue(name, 0)
+	}
+	if after.Status.State != before.Status.State || after.Status.Reason != before.Status.Reason {
+		s.decide(name, after.Status.State, after.Status.Reason)
+	}
+	if before.Status.WorkflowName == "" && after.Status.WorkflowName != "" {
+		if run := s.runs[name]; run != nil {
+			s.push(&step{at: s.clock.Now().Add(run.Duration.Duration), kind: stepFinish, trigger: name, phase: run.Phase})
+		}
+	}
+	if res.RequeueAfter > 0 {
+		s.enqueue(name, res.RequeueAfter)
+	}
+	return nil
+}
+
+// finish completes the workflow of trigger in phase, as Argo would.
+func (s *simulation) finish(ctx context.Context, name string, phase argov1alpha1.WorkflowPhase) error {
+	if phase == "" {
+		phase = argov1alpha1.WorkflowSucceeded
+	}
+	var trigger recoveryv1alpha1.RecoveryTrigger
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: name}, &trigger); err != nil {
+		return err
+	}
+	var wf argov1alpha1.Workflow
+	if trigger.Status.WorkflowName != "" {
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: trigger.Status.WorkflowName}, &wf); err != nil {
+			return err
+		}
+	}
+	if wf.Name == "" || wf.Status.Phase.Completed() {
+		s.decide(name, trigger.Status.State, fmt.Sprintf("Completion with phase %s ignored, no workflow running", phase))
+		return nil
+	}
+	wf.Status.Phase = phase
+	if trigger.Status.StartedAt != nil {
+		wf.Status.StartedAt = *trigger.Status.StartedAt
+	}
+	wf.Status.FinishedAt = metav1.Time{Time: s.clock.Now()}
+	if err := s.client.Update(ctx, &wf); err != nil {
+		return err
+	}
+	// The trigger owns the workflow, so its completion reconciles it
+	s.enqueue(name, 0)
+	return nil
+}
+
+func (s *simulation) summarize(ctx context.Context, end time.Time) (*Report, error) {
+	since := func(t time.Time) *metav1.Duration { return &metav1.Duration{Duration: t.Sub(s.start)} }
+	unfinished := false
+	for _, name := range s.order {
+		var trigger recoveryv1alpha1.RecoveryTrigger
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: name}, &trigger); err != nil {
+			return nil, err
+		}
+		sum := TriggerSummary{
+			Name:        name,
+			FailureType: trigger.Spec.FailureType,
+			State:       trigger.Status.State,
+			Reason:      trigger.Status.Reason,
+			Created:     *since(trigger.CreationTimestamp.Time),
+		}
+		if st := trigger.Status.StartedAt; st != nil {
+			sum.Started = since(st.Time)
+			sum.QueueWait = &metav1.Duration{Duration: st.Sub(trigger.CreationTimestamp.Time)}
+			s.report.TotalQueueWait.Duration += sum.QueueWait.Duration
+		}
+		if fin := trigger.Status.FinishedAt; fin != nil {
+			sum.Finished = since(fin.Time)
+			sum.RecoveryTime = &metav1.Duration{Duration: fin.Sub(trigger.CreationTimestamp.Time)}
+			s.report.Makespan.Duration = max(s.report.Makespan.Duration, sum.Finished.Duration)
+		} else if trigger.Status.State != "Discarded" {
+			unfinished = true
+		}
+		s.report.Triggers = append(s.report.Triggers, sum)
+	}
+	if unfinished {
+		s.report.Makespan.Duration = end.Sub(s.start)
+	}
+	return &s.report, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const timeline = `
+namespace: recovery
+events:
+- at: 0s
+  create:
+    name: restart-worker-1
+    failureType: NodeNotReady
+    workflowTemplate: restart-kubelet
+    targetObjects: [{kind: Node, name: worker-1}]
+    workflow: {duration: 3m}
+- at: 30s
+  create:
+    name: drain-worker-1
+    failureType: DiskPressure
+    workflowTemplate: drain-node
+    targetObjects: [{kind: Node, name: worker-1}]
+    workflow: {duration: 1m, phase: Failed}
+- at: 1m
+  create:
+    name: restart-worker-2
+    failureType: NodeNotReady
+    workflowTemplate: restart-kubelet
+    targetObjects: [{kind: Node, name: worker-2}]
+- at: 2m
+  complete: {trigger: drain-worker-1}
+- at: 10m
+  complete: {trigger: restart-worker-2, phase: Failed}
+`
+
+func TestRun(t *testing.T) {
+	tl, err := Load([]byte(timeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(context.Background(), tl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Triggers) != 3 {
+		t.Fatalf("triggers = %d, want 3", len(report.Triggers))
+	}
+	restart1, drain, restart2 := report.Triggers[0], report.Triggers[1], report.Triggers[2]
+
+	if restart1.State != "Succeeded" || restart1.QueueWait.Duration != 0 || restart1.RecoveryTime.Duration != 3*time.Minute {
+		t.Errorf("restart-worker-1 = %+v, want it to start at once and take 3m", restart1)
+	}
+	// Waiting triggers notice the blocker finished on their next requeue
+	if drain.State != "Failed" || drain.Started.Duration != 3*time.Minute+15*time.Second ||
+		drain.QueueWait.Duration != 2*time.Minute+45*time.Second || drain.RecoveryTime.Duration != 3*time.Minute+45*time.Second {
+		t.Errorf("drain-worker-1 = %+v, want it queued behind restart-worker-1", drain)
+	}
+	if restart2.State != "Failed" || restart2.Finished.Duration != 10*time.Minute {
+		t.Errorf("restart-worker-2 = %+v, want it completed by the timeline", restart2)
+	}
+	if report.Makespan.Duration != 10*time.Minute {
+		t.Errorf("makespan = %v, want 10m", report.Makespan.Duration)
+	}
+
+	var reasons []string
+	for _, d := range report.Decisions {
+		reasons = append(reasons, d.Trigger+": "+d.Reason)
+	}
+	joined := strings.Join(reasons, "\n")
+	for _, want := range []string{
+		"drain-worker-1: Resource conflict detected with running trigger restart-worker-1",
+		"restart-worker-2: Dependency conflict detected with running trigger restart-worker-1",
+		"drain-worker-1: Completion with phase Succeeded ignored, no workflow running",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("decisions missing %q:\n%s", want, joined)
+		}
+	}
+
+	// Replays are reproducible
+	again, err := Run(context.Background(), tl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report, again) {
+		t.Error("replaying the timeline gave a different report")
+	}
+}
+
+func TestRunHorizon(t *testing.T) {
+	tl, err := Load([]byte(`
+horizon: 1h
+events:
+- at: 0s
+  create: {name: stuck, failureType: NodeNotReady, targetObjects: [{kind: Node, name: worker-1}]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(context.Background(), tl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Triggers[0]; got.State != "Running" || got.Finished != nil || report.Makespan.Duration != time.Hour {
+		t.Errorf("report = %+v, want the trigger left running at the horizon", report)
+	}
+}
+
+func TestRunWorkflowTemplates(t *testing.T) {
+	const events = `
+events:
+- at: 0s
+  create: {name: diagnose-1, failureType: NodeNotReady, workflowTemplate: diagnose, targetObjects: [{kind: Node, name: worker-1}], workflow: {duration: 5m}}
+- at: 1m
+  create: {name: diagnose-2, failureType: DiskPressure, workflowTemplate: diagnose, targetObjects: [{kind: Node, name: worker-1}], workflow: {duration: 5m}}
+`
+	const templates = `
+workflowTemplates:
+- metadata:
+    name: diagnose
+  spec:
+    templates:
+    - name: collect
+      metadata:
+        annotations: {recovery.workflow-recovery.io/action: read}
+`
+	// Read-only steps on the same node may run at once, unlike steps whose
+	// actions are not declared
+	for timeline, want := range map[string]time.Duration{events: 4*time.Minute + 15*time.Second, templates + events: 0} {
+		tl, err := Load([]byte(timeline))
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := Run(context.Background(), tl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := report.Triggers[1].QueueWait; got == nil || got.Duration != want {
+			t.Errorf("queue wait = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":         "events: []",
+		"unknown field": "events: [{at: 0s, craete: {name: a}}]",
+		"both":          "events: [{at: 0s, create: {name: a}, complete: {trigger: a}}]",
+		"unnamed":       "events: [{at: 0s, create: {failureType: x}}]",
+		"duplicate":     "events: [{at: 0s, create: {name: a}}, {at: 1s, create: {name: a}}]",
+		"unknown":       "events: [{at: 0s, complete: {trigger: a}}]",
+		"phase":         "events: [{at: 0s, create: {name: a}}, {at: 1s, complete: {trigger: a, phase: Running}}]",
+	} {
+		if _, err := Load([]byte(data)); err == nil {
+			t.Errorf("%s: loaded, want an error", name)
+		}
+	}
+}
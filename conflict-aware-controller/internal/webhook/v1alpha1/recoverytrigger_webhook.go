@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var recoverytriggerlog = logf.Log.WithName("recoverytrigger-resource")
+
+// SetupRecoveryTriggerWebhookWithManager registers the webhook for RecoveryTrigger in the manager.
+func SetupRecoveryTriggerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&recoveryv1alpha1.RecoveryTrigger{}).
+		WithValidator(&RecoveryTriggerCustomValidator{Client: mgr.GetAPIReader()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-recovery-workflow-recovery-io-v1alpha1-recoverytrigger,mutating=false,failurePolicy=fail,sideEffects=None,groups=recovery.workflow-recovery.io,resources=recoverytriggers,verbs=create;update,versions=v1alpha1,name=vrecoverytrigger-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=argoproj.io,resources=workflowtemplates,verbs=get
+
+// RecoveryTriggerCustomValidator validates RecoveryTriggers on create and update.
+type RecoveryTriggerCustomValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &RecoveryTriggerCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type RecoveryTrigger.
+func (v *RecoveryTriggerCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	trigger, ok := obj.(*recoveryv1alpha1.RecoveryTrigger)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecoveryTrigger object but got %T", obj)
+	}
+	recoverytriggerlog.Info("Validation for RecoveryTrigger upon creation", "name", trigger.GetName())
+	return v.validate(ctx, trigger)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type RecoveryTrigger.
+func (v *RecoveryTriggerCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	trigger, ok := newObj.(*recoveryv1alpha1.RecoveryTrigger)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecoveryTrigger object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*recoveryv1alpha1.RecoveryTrigger)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecoveryTrigger object for the oldObj but got %T", oldObj)
+	}
+	recoverytriggerlog.Info("Validation for RecoveryTrigger upon update", "name", trigger.GetName())
+
+	if !trigger.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	// Metadata edits must keep working after the WorkflowTemplate is gone
+	if equality.Semantic.DeepEqual(old.Spec, trigger.Spec) {
+		return nil, nil
+	}
+	// The submitted workflow was built from the spec, so it cannot change afterwards
+	if old.Status.WorkflowName != "" {
+		return nil, apierrors.NewInvalid(
+			recoveryv1alpha1.GroupVersion.WithKind("RecoveryTrigger").GroupKind(), trigger.Name,
+			field.ErrorList{field.Forbidden(field.NewPath("spec"),
+				fmt.Sprintf("spec is immutable once workflow %s has been submitted", old.Status.WorkflowName))})
+	}
+	return v.validate(ctx, trigger)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type RecoveryTrigger.
+func (v *RecoveryTriggerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *RecoveryTriggerCustomValidator) validate(ctx context.Context, trigger *recoveryv1alpha1.RecoveryTrigger) (admission.Warnings, error) {
+	var (
+		allErrs  field.ErrorList
+		warnings admission.Warnings
+	)
+	specPath := field.NewPath("spec")
+
+	if trigger.Spec.FailureType == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("failureType"), ""))
+	}
+
+	if trigger.Spec.WorkflowTemplate == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("workflowTemplate"), ""))
+	} else {
+		key := client.ObjectKey{Namespace: trigger.Namespace, Name: trigger.Spec.WorkflowTemplate}
+		err := v.Client.Get(ctx, key, &argov1alpha1.WorkflowTemplate{})
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err):
+			allErrs = append(allErrs, field.NotFound(specPath.Child("workflowTemplate"), trigger.Spec.WorkflowTemplate))
+		case meta.IsNoMatchError(err):
+			// Argo may be installed after the trigger is created
+			warnings = append(warnings, "WorkflowTemplate kind is not installed; spec.workflowTemplate was not checked")
+		default:
+			return nil, fmt.Errorf("reading WorkflowTemplate %s: %w", key, err)
+		}
+	}
+
+	seen := map[recoveryv1alpha1.TargetObject]bool{}
+	for i, obj := range trigger.Spec.TargetObjects {
+		objPath := specPath.Child("targetObjects").Index(i)
+		if obj.Kind == "" {
+			allErrs = append(allErrs, field.Required(objPath.Child("kind"), ""))
+		}
+		if obj.Name == "" {
+			allErrs = append(allErrs, field.Required(objPath.Child("name"), ""))
+		}
+		if seen[obj] {
+			allErrs = append(allErrs, field.Duplicate(objPath, fmt.Sprintf("%s/%s", obj.Kind, obj.Name)))
+		}
+		seen[obj] = true
+	}
+	if len(trigger.Spec.TargetObjects) == 0 {
+		warnings = append(warnings, "spec.targetObjects is empty; resource conflicts cannot be detected")
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(
+		recoveryv1alpha1.GroupVersion.WithKind("RecoveryTrigger").GroupKind(), trigger.Name, allErrs)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	recoveryv1alpha1 "github.com/phuongbac/conflictawareworkflowcontroller/api/v1alpha1"
+)
+
+var _ = Describe("RecoveryTrigger Webhook", func() {
+	const (
+		templateName = "node-recovery-template"
+		triggerName  = "webhook-trigger"
+	)
+
+	var (
+		obj       *recoveryv1alpha1.RecoveryTrigger
+		validator RecoveryTriggerCustomValidator
+	)
+
+	BeforeEach(func() {
+		Expect(k8sClient.Create(ctx, &argov1alpha1.WorkflowTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: "default"},
+		})).To(Succeed())
+
+		obj = &recoveryv1alpha1.RecoveryTrigger{
+			ObjectMeta: metav1.ObjectMeta{Name: triggerName, Namespace: "default"},
+			Spec: recoveryv1alpha1.RecoveryTriggerSpec{
+				FailureType:      "NodeFailure",
+				WorkflowTemplate: templateName,
+				TargetObjects:    []recoveryv1alpha1.TargetObject{{Kind: "Node", Name: "worker-1"}},
+			},
+		}
+		validator = RecoveryTriggerCustomValidator{Client: k8sClient}
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &recoveryv1alpha1.RecoveryTrigger{ObjectMeta: metav1.ObjectMeta{Name: triggerName, Namespace: "default"}})
+		_ = k8sClient.Delete(ctx, &argov1alpha1.WorkflowTemplate{ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: "default"}})
+	})
+
+	Context("When creating or updating RecoveryTrigger under Validating Webhook", func() {
+		It("Should admit a trigger whose WorkflowTemplate exists", func() {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		})
+
+		It("Should deny a trigger without a WorkflowTemplate", func() {
+			obj.Spec.WorkflowTemplate = ""
+			err := k8sClient.Create(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("spec.workflowTemplate: Required value")))
+		})
+
+		It("Should deny a WorkflowTemplate that does not exist", func() {
+			obj.Spec.WorkflowTemplate = "does-not-exist"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.workflowTemplate: Not found")))
+		})
+
+		It("Should deny a trigger without a failure type", func() {
+			obj.Spec.FailureType = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.failureType")))
+		})
+
+		It("Should deny incomplete and duplicate target objects", func() {
+			obj.Spec.TargetObjects = append(obj.Spec.TargetObjects,
+				recoveryv1alpha1.TargetObject{Kind: "Node"},
+				recoveryv1alpha1.TargetObject{Kind: "Node", Name: "worker-1"},
+				recoveryv1alpha1.TargetObject{Name: "checkout-0"},
+			)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetObjects[1].name")))
+			Expect(err).To(MatchError(ContainSubstring("spec.targetObjects[2]: Duplicate value")))
+			Expect(err).To(MatchError(ContainSubstring("spec.targetObjects[3].kind: Required value")))
+		})
+
+		It("Should warn about a trigger without target objects", func() {
+			obj.Spec.TargetObjects = nil
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ContainElement(ContainSubstring("spec.targetObjects is empty")))
+		})
+
+		It("Should deny spec changes once the workflow is submitted", func() {
+			oldObj := obj.DeepCopy()
+			oldObj.Status.WorkflowName = "node-recovery-abcde"
+			obj.Status.WorkflowName = oldObj.Status.WorkflowName
+			obj.Spec.FailureType = "DiskPressure"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec is immutable")))
+
+			By("allowing changes before submission")
+			oldObj.Status.WorkflowName = ""
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit metadata edits once the WorkflowTemplate is gone", func() {
+			obj.Spec.WorkflowTemplate = "does-not-exist"
+			oldObj := obj.DeepCopy()
+			obj.Labels = map[string]string{"team": "storage"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+
+			By("still checking spec changes")
+			obj.Spec.FailureType = "DiskPressure"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.workflowTemplate: Not found")))
+		})
+	})
+})
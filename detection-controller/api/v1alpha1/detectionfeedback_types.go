@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FeedbackLabel is the verdict a human gives an anomaly occurrence.
+// +kubebuilder:validation:Enum=TruePositive;FalsePositive
+type FeedbackLabel string
+
+const (
+	FeedbackTruePositive  FeedbackLabel = "TruePositive"
+	FeedbackFalsePositive FeedbackLabel = "FalsePositive"
+)
+
+// DetectionFeedbackSpec labels one anomaly occurrence of a FaultDetection.
+type DetectionFeedbackSpec struct {
+	// FaultDetection in the same namespace
+	FaultDetectionRef string `json:"faultDetectionRef"`
+	// Occurrence being labelled (status.occurrences[].id); the most recent one when empty
+	OccurrenceID string        `json:"occurrenceID,omitempty"`
+	Label        FeedbackLabel `json:"label"`
+	Comment      string        `json:"comment,omitempty"`
+	// Metric history captured on each side of the occurrence start. Defaults to 15m.
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// DetectionFeedbackStatus holds the training sample collected for the label.
+type DetectionFeedbackStatus struct {
+	// True once the snapshot has been collected; it is not refreshed afterwards
+	Collected   bool         `json:"collected,omitempty"`
+	CollectedAt *metav1.Time `json:"collectedAt,omitempty"`
+	Template    string       `json:"template,omitempty"`
+	Target      *ObjectRef   `json:"target,omitempty"`
+	// Copy of the labelled occurrence
+	Occurrence *AnomalyOccurrence `json:"occurrence,omitempty"`
+	// Metric samples around the occurrence
+	Snapshots []MetricSnapshot `json:"snapshots,omitempty"`
+	Message   string           `json:"message,omitempty"`
+}
+
+// MetricSnapshot is the history of one template query around an occurrence.
+type MetricSnapshot struct {
+	Metric  string           `json:"metric"`
+	Samples []SnapshotSample `json:"samples,omitempty"`
+	// Error returned by the data source, if the history could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// SnapshotSample is one timestamped metric value.
+type SnapshotSample struct {
+	Time  metav1.Time `json:"time"`
+	Value string      `json:"value"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="FaultDetection",type=string,JSONPath=`.spec.faultDetectionRef`
+// +kubebuilder:printcolumn:name="Label",type=string,JSONPath=`.spec.label`
+// +kubebuilder:printcolumn:name="Collected",type=boolean,JSONPath=`.status.collected`
+type DetectionFeedback struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DetectionFeedbackSpec   `json:"spec,omitempty"`
+	Status DetectionFeedbackStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type DetectionFeedbackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DetectionFeedback `json:"items"`
+}
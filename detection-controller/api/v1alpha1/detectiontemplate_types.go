@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,25 +30,400 @@ const (
 	ScopeCluster Scope = "Cluster"
 )
 
+// WindowAggregation reduces a range query to a single value.
+// +kubebuilder:validation:Enum=avg;max;min;p95;rateOfChange;countAbove;fractionAbove
+type WindowAggregation string
+
+const (
+	AggregationAvg           WindowAggregation = "avg"
+	AggregationMax           WindowAggregation = "max"
+	AggregationMin           WindowAggregation = "min"
+	AggregationP95           WindowAggregation = "p95"
+	AggregationRateOfChange  WindowAggregation = "rateOfChange"
+	AggregationCountAbove    WindowAggregation = "countAbove"
+	AggregationFractionAbove WindowAggregation = "fractionAbove"
+)
+
+// RangeSpec turns a query into a range query evaluated over a lookback window.
+type RangeSpec struct {
+	// How far back to query (e.g., 10m)
+	Lookback metav1.Duration `json:"lookback"`
+	// Resolution of the range query (defaults to 30s)
+	Step *metav1.Duration `json:"step,omitempty"`
+	// How the samples in the window are reduced to one value
+	Aggregation WindowAggregation `json:"aggregation"`
+	// Threshold for countAbove and fractionAbove (e.g., "90")
+	Threshold string `json:"threshold,omitempty"`
+}
+
 // QuerySpec defines one metric query.
 type QuerySpec struct {
 	// Name of the metric (for status report)
 	Metric string `json:"metric"`
 	// PromQL or API query string
 	Query string `json:"query"`
+	// Optional range evaluation; the aggregated value is what Rule compares
+	Range *RangeSpec `json:"range,omitempty"`
 }
 
+// SecretRef points to a Secret in a given namespace.
+type SecretRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// HTTPClientConfig defines how the controller connects to an HTTP data source.
+// Credentials are read from AuthSecretRef using the keys:
+//   - token: bearer token
+//   - username, password: basic auth
+//   - tls.crt, tls.key: client certificate for mTLS
+//   - ca.crt: CA bundle used to verify the server
+type HTTPClientConfig struct {
+	// Timeout for a single request (defaults to 10s)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Secret holding credentials and certificates. It must be in the
+	// controller namespace (--secret-namespace)
+	AuthSecretRef *SecretRef `json:"authSecretRef,omitempty"`
+	// Skip server certificate verification (testing only)
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// Extra headers sent with every request (e.g., X-Scope-OrgID for multi-tenant backends)
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// InferenceProtocol is the wire format used to call the model.
+// +kubebuilder:validation:Enum=legacy;v1;v2
+type InferenceProtocol string
+
+const (
+	// Bare array of {metric, value}, response {"anomaly": bool}
+	InferenceProtocolLegacy InferenceProtocol = "legacy"
+	// Native batched schema (docs/ml-inference-protocol.md)
+	InferenceProtocolV1 InferenceProtocol = "v1"
+	// KServe v2 / Open Inference Protocol
+	InferenceProtocolV2 InferenceProtocol = "v2"
+)
+
 // MLSpec defines an optional ML model serving config.
 type MLSpec struct {
 	// Model name or identifier in the model store
 	ModelName string `json:"modelName"`
-	// Optional container image for model serving
+	// Optional container image for model serving. When set, the controller
+	// runs it as a Deployment and Service and fills in status.modelEndpoint.
 	Image string `json:"image,omitempty"`
 	// Endpoint (if model already deployed)
 	Endpoint string `json:"endpoint,omitempty"`
+
+	// Replicas of the model server (default 1)
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources of the model server container
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Port the model server listens on (default 8080)
+	Port int32 `json:"port,omitempty"`
+	// HTTP path of the inference API (default /predict)
+	Path string `json:"path,omitempty"`
+
+	// Wire format used to call the model (default legacy)
+	Protocol InferenceProtocol `json:"protocol,omitempty"`
+	// Score at or above which a target is anomalous (e.g., "0.8"). When
+	// unset, or when the model returns no score, as legacy models may not,
+	// the model's own anomaly flag is used.
+	Threshold string `json:"threshold,omitempty"`
+	// Number of top contributing features recorded in status (default 3)
+	TopFeatures int32 `json:"topFeatures,omitempty"`
+	// Connection settings for the model endpoint (auth, TLS, timeout)
+	Client *HTTPClientConfig `json:"client,omitempty"`
+}
+
+// StatisticalAlgorithm selects a built-in anomaly detector.
+// +kubebuilder:validation:Enum=ewma;seasonal;mad;forecast
+type StatisticalAlgorithm string
+
+const (
+	// EWMA/z-score against an exponentially weighted baseline
+	AlgorithmEWMA StatisticalAlgorithm = "ewma"
+	// z-score against the baseline of the same hour of the week
+	AlgorithmSeasonal StatisticalAlgorithm = "seasonal"
+	// Median absolute deviation over a sliding window
+	AlgorithmMAD StatisticalAlgorithm = "mad"
+	// Linear-trend forecast against a limit (e.g., disk full in 4h)
+	AlgorithmForecast StatisticalAlgorithm = "forecast"
+)
+
+// StatisticalSpec configures an in-process anomaly detector that runs on
+// query results without an external ML service.
+type StatisticalSpec struct {
+	Algorithm StatisticalAlgorithm `json:"algorithm"`
+	// Metric (QuerySpec.Metric) to analyse; empty means every query
+	Metric string `json:"metric,omitempty"`
+	// Score above which a sample is anomalous (default "3")
+	Sensitivity string `json:"sensitivity,omitempty"`
+	// EWMA smoothing factor between 0 and 1 (default "0.3")
+	Alpha string `json:"alpha,omitempty"`
+	// Samples collected before the detector may fire (default 10)
+	MinSamples int32 `json:"minSamples,omitempty"`
+	// Samples kept for mad and forecast (default 60)
+	WindowSize int32 `json:"windowSize,omitempty"`
+	// Forecast: value that must not be reached (e.g., "95" for disk usage %)
+	Limit string `json:"limit,omitempty"`
+	// Forecast: how far ahead to look (default 4h)
+	Horizon *metav1.Duration `json:"horizon,omitempty"`
+}
+
+// CompositeOperator combines the verdicts of a composite template's detectors.
+// +kubebuilder:validation:Enum=And;Or;AtLeast
+type CompositeOperator string
+
+const (
+	CompositeAnd CompositeOperator = "And"
+	CompositeOr  CompositeOperator = "Or"
+	// At least MinFiring of the detectors (k-of-n)
+	CompositeAtLeast CompositeOperator = "AtLeast"
+)
+
+// ComparisonOperator compares a query value against a threshold.
+// +kubebuilder:validation:Enum=">";">=";"<";"<=";"==";"!="
+type ComparisonOperator string
+
+// CompositeSpec combines several sub-detectors into one verdict.
+type CompositeSpec struct {
+	Operator CompositeOperator `json:"operator"`
+	// Number of detectors that must fire for AtLeast
+	MinFiring int32 `json:"minFiring,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Detectors []SubDetectorSpec `json:"detectors"`
+}
+
+// SubDetectorSpec is one detector of a composite template. Exactly one of
+// Field, Prometheus, Statistical, ML and Probe must be set.
+type SubDetectorSpec struct {
+	// Name reported in status.compositeResults
+	Name string `json:"name"`
+	// How long the condition must hold before the detector fires (e.g., 2m)
+	For *metav1.Duration `json:"for,omitempty"`
+
+	Field       *FieldCheck       `json:"field,omitempty"`
+	Prometheus  *PrometheusCheck  `json:"prometheus,omitempty"`
+	Statistical *StatisticalCheck `json:"statistical,omitempty"`
+	ML          *MLCheck          `json:"ml,omitempty"`
+	// Fires when the probe fails on any of its endpoints. Its name is the
+	// detector's.
+	Probe *ProbeSpec `json:"probe,omitempty"`
+}
+
+// FieldCheck compares a field of the FaultDetection's target object.
+// Fires when the value differs from Expected.
+type FieldCheck struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Dot notation; list items with a type can be selected with
+	// [Type], e.g. status.conditions[Ready].status
+	FieldPath string `json:"fieldPath"`
+	Expected  string `json:"expected"`
+}
+
+// PrometheusCheck compares a query against a threshold, using the
+// template's PrometheusAPI and PrometheusClient.
+type PrometheusCheck struct {
+	Query string `json:"query"`
+	// Optional range evaluation; the aggregated value is compared
+	Range *RangeSpec `json:"range,omitempty"`
+	// Comparison fired on (default ">")
+	Operator ComparisonOperator `json:"operator,omitempty"`
+	// Empty means the check only fires on missing data
+	Threshold string `json:"threshold,omitempty"`
+	// Fire when the query returns no samples (e.g., metrics absent)
+	FireOnNoData bool `json:"fireOnNoData,omitempty"`
+}
+
+// StatisticalCheck fires when any of the template's statistical detectors
+// flags Metric. Metric may name a QuerySpec or a Prometheus sub-detector.
+type StatisticalCheck struct {
+	Metric string `json:"metric"`
+}
+
+// MLCheck fires on the verdict of the template's ML model.
+type MLCheck struct {
+	// Score at or above which the check fires, overriding spec.ml.threshold
+	Threshold string `json:"threshold,omitempty"`
+}
+
+// EventSpec matches core Events. All set fields must match.
+type EventSpec struct {
+	// Event reasons to match (e.g., OOMKilling, FailedMount); any reason when empty
+	Reasons []string `json:"reasons,omitempty"`
+	// Kind of the involved object (e.g., Pod, Node)
+	InvolvedObjectKind string `json:"involvedObjectKind,omitempty"`
+	// Event type to match
+	// +kubebuilder:validation:Enum=Normal;Warning
+	Type string `json:"type,omitempty"`
+	// Regular expression the event message must match
+	MessagePattern string `json:"messagePattern,omitempty"`
+	// How far back events are counted (default 10m)
+	Window *metav1.Duration `json:"window,omitempty"`
+	// Occurrences within the window at which an object is anomalous (default 1)
+	MinCount int32 `json:"minCount,omitempty"`
+}
+
+// LogPattern matches container log lines.
+type LogPattern struct {
+	// Name reported in status and used as the result metric
+	Name string `json:"name"`
+	// Regular expression matched against the line, or against JSONField when set
+	Regex string `json:"regex"`
+	// Dot-separated field of JSON log lines to match (e.g., "error.kind").
+	// Lines that are not JSON objects never match.
+	JSONField string `json:"jsonField,omitempty"`
+	// Matches within the window at which the pattern fires (default 1)
+	MinCount int32 `json:"minCount,omitempty"`
+}
+
+// LogSpec reads container logs through the Kubernetes API.
+type LogSpec struct {
+	// Pods to read in the FaultDetection's namespace. The FaultDetection's
+	// Pod target is read when unset.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Container to read; every container when empty
+	Container string `json:"container,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Patterns []LogPattern `json:"patterns"`
+	// How far back matches are counted (default 5m)
+	Window *metav1.Duration `json:"window,omitempty"`
+	// Matching lines are truncated to this many bytes in status (default 256)
+	MaxLineLength int32 `json:"maxLineLength,omitempty"`
+}
+
+// ProbeType is the protocol of a synthetic probe.
+// +kubebuilder:validation:Enum=HTTP;TCP;GRPC;DNS
+type ProbeType string
+
+const (
+	// Request a URL and check the status code, body and latency
+	ProbeHTTP ProbeType = "HTTP"
+	// Open a TCP connection
+	ProbeTCP ProbeType = "TCP"
+	// Call the standard grpc.health.v1 health check
+	ProbeGRPC ProbeType = "GRPC"
+	// Resolve a host name
+	ProbeDNS ProbeType = "DNS"
+)
+
+// ProbeSpec actively checks that an endpoint answers. Endpoints come from
+// Address, the pods matching Selector, or else the FaultDetection's Pod or
+// Service target.
+type ProbeSpec struct {
+	// Name reported in status and used as the result metric. Required in
+	// spec.probes; probe detectors of a composite use the detector's name.
+	Name string    `json:"name,omitempty"`
+	Type ProbeType `json:"type"`
+	// A URL for HTTP, host:port for TCP and GRPC, a host name for DNS.
+	// May use template variables (e.g., "http://{{ .Target.Name }}:8080/healthz");
+	// values are URL-escaped for HTTP, and rendered TCP, GRPC and DNS
+	// addresses must be host names or IP addresses.
+	Address string `json:"address,omitempty"`
+	// Pods in the FaultDetection's namespace probed on Port, each reported separately
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Port of the target or selected pods
+	Port int32 `json:"port,omitempty"`
+
+	// HTTP path used with Port (default "/")
+	Path string `json:"path,omitempty"`
+	// Scheme used with Port; https also enables TLS for GRPC probes
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// HTTP method (default GET)
+	Method string `json:"method,omitempty"`
+	// Accepted HTTP status codes (default any 2xx or 3xx)
+	ExpectedStatusCodes []int32 `json:"expectedStatusCodes,omitempty"`
+	// Regular expression the HTTP response body must match
+	BodyRegex string `json:"bodyRegex,omitempty"`
+	// Service checked by GRPC probes; empty checks the whole server
+	GRPCService string `json:"grpcService,omitempty"`
+
+	// Time after which the probe fails (default 5s)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Answers slower than this count as failed
+	MaxLatency *metav1.Duration `json:"maxLatency,omitempty"`
+	// Auth, TLS and headers for HTTP and GRPC probes
+	Client *HTTPClientConfig `json:"client,omitempty"`
+}
+
+// JSONValue extracts one metric from a JSON response.
+type JSONValue struct {
+	// Result name, usable in rule, statistical and ML features
+	Metric string `json:"metric"`
+	// JSONPath of a single value, e.g. "{.checks[?(@.name=='db')].latencyMs}"
+	// or ".status". Numbers, booleans (1/0) and numeric strings are accepted.
+	JSONPath string `json:"jsonPath"`
+	// When set, the value is compared as a string and a mismatch is anomalous
+	// (e.g., "UP"). The metric is then 0 on match and 1 on mismatch.
+	Expected string `json:"expected,omitempty"`
+}
+
+// HTTPSourceSpec polls an HTTP endpoint returning JSON.
+type HTTPSourceSpec struct {
+	// Endpoint URL; may use template variables
+	URL string `json:"url"`
+	// +kubebuilder:validation:Enum=GET;POST
+	Method string `json:"method,omitempty"`
+	// JSON request body sent with POST
+	Body string `json:"body,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Values []JSONValue `json:"values"`
+	// Auth, TLS, headers and timeout
+	Client *HTTPClientConfig `json:"client,omitempty"`
+}
+
+// AlertSpec selects alerts received from Alertmanager.
+type AlertSpec struct {
+	// Labels a firing alert must carry, e.g. alertname: KubeNodeNotReady
+	// +kubebuilder:validation:MinProperties=1
+	Matchers map[string]string `json:"matchers"`
+	// Labels naming the alert's target (default node, pod and namespace)
+	TargetLabels *AlertTargetLabels `json:"targetLabels,omitempty"`
+}
+
+// AlertTargetLabels names the alert labels that identify its target.
+type AlertTargetLabels struct {
+	Node      string `json:"node,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ParameterType restricts the values of a template parameter.
+type ParameterType string
+
+const (
+	// ParameterString accepts any value. PromQL queries may only use it
+	// inside string literals.
+	ParameterString ParameterType = "String"
+	// ParameterNumber accepts a PromQL number (e.g., 0.95)
+	ParameterNumber ParameterType = "Number"
+	// ParameterDuration accepts a PromQL duration (e.g., 5m)
+	ParameterDuration ParameterType = "Duration"
+)
+
+// TemplateParameter declares a value FaultDetections can supply to a template.
+type TemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Values accepted (default String). Number and Duration parameters may
+	// be used outside string literals in PromQL queries.
+	// +kubebuilder:validation:Enum=String;Number;Duration
+	Type ParameterType `json:"type,omitempty"`
+	// Value used when the FaultDetection does not set one
+	Default string `json:"default,omitempty"`
+	// FaultDetections must set the parameter when there is no default
+	Required bool `json:"required,omitempty"`
 }
 
 // DetectionTemplateSpec defines reusable config for detection agents.
+//
+// Queries, Rule, TriggerAPI and TriggerPayload are Go templates rendered for
+// each FaultDetection with {{ .Target.Name }}, {{ .Target.Namespace }},
+// {{ .Target.Kind }}, {{ .Node }}, {{ .Labels.<key> }} and {{ .Params.<name> }}.
+// Values are escaped for PromQL strings, URLs and JSON strings respectively;
+// in PromQL queries, only Number and Duration parameters may be used outside
+// string literals.
 type DetectionTemplateSpec struct {
 	// Scope of monitoring (Pod, Node, Cluster)
 	Scope Scope `json:"scope"`
@@ -55,9 +431,14 @@ type DetectionTemplateSpec struct {
 	// Interval for metric collection
 	Interval metav1.Duration `json:"interval"`
 
+	// Parameters FaultDetections can set, referenced as {{ .Params.<name> }}
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+
 	// === Option A: Prometheus-based detection ===
 	PrometheusAPI string      `json:"prometheusAPI,omitempty"`
 	Queries       []QuerySpec `json:"queries,omitempty"`
+	// Connection settings for PrometheusAPI (auth, TLS, tenant headers)
+	PrometheusClient *HTTPClientConfig `json:"prometheusClient,omitempty"`
 
 	// === Option B: API-based detection ===
 	// K8s resource to watch (e.g., core/v1/nodes, apps/v1/deployments)
@@ -70,20 +451,59 @@ type DetectionTemplateSpec struct {
 	// Expected value (e.g., "True" for Node Ready, "Running" for Pod)
 	Expected string `json:"expected,omitempty"`
 
+	// === Option C: Event-based detection ===
+	// Matching Events are attributed to their involved object. A FaultDetection
+	// with a named target only sees the target's events.
+	Events *EventSpec `json:"events,omitempty"`
+
+	// === Option D: Container log detection ===
+	Logs *LogSpec `json:"logs,omitempty"`
+
+	// === Option E: Synthetic probes ===
+	// Anomalous when any endpoint of any probe fails
+	Probes []ProbeSpec `json:"probes,omitempty"`
+
+	// === Option F: HTTP JSON data source ===
+	// Extracted values are Results like Prometheus queries and feed Rule,
+	// Statistical and ML the same way.
+	HTTP *HTTPSourceSpec `json:"http,omitempty"`
+
+	// === Option G: Alertmanager alerts ===
+	// Anomalous while a matching alert for the target is firing. Requires
+	// the controller's Alertmanager receiver to be enabled.
+	Alerts *AlertSpec `json:"alerts,omitempty"`
+
 	// Rule expression (optional, can combine multiple)
 	Rule string `json:"rule,omitempty"`
 
+	// Built-in statistical detectors applied to query results
+	Statistical []StatisticalSpec `json:"statistical,omitempty"`
+
 	// Optional ML model config
 	ML *MLSpec `json:"ml,omitempty"`
 
+	// Composite detection: when set, Option A and B are ignored and the
+	// verdict combines the sub-detectors. Statistical and ML only count
+	// through Statistical and ML sub-detectors.
+	Composite *CompositeSpec `json:"composite,omitempty"`
+
 	// API endpoint to trigger if anomaly detected
 	TriggerAPI string `json:"triggerAPI,omitempty"`
+	// Body sent to TriggerAPI
+	TriggerPayload string `json:"triggerPayload,omitempty"`
 }
 
 // DetectionTemplateStatus provides registry info.
 type DetectionTemplateStatus struct {
 	Valid   bool   `json:"valid,omitempty"`
 	Message string `json:"message,omitempty"`
+
+	// Endpoint of the model server deployed from spec.ml.image
+	ModelEndpoint string `json:"modelEndpoint,omitempty"`
+	// Whether the deployed model server has all replicas available
+	ModelReady bool `json:"modelReady,omitempty"`
+	// Image the model server currently runs
+	ModelImage string `json:"modelImage,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -26,6 +26,11 @@ type FaultDetectionSpec struct {
 	TemplateRef string `json:"templateRef"`
 	// Target object (optional, based on Scope)
 	Target *ObjectRef `json:"target,omitempty"`
+	// Values for the template's parameters
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Record the trigger an anomaly would fire without sending it. The
+	// controller's --dry-run flag does so for every FaultDetection.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // ObjectRef describes the object being monitored
@@ -36,6 +41,32 @@ type ObjectRef struct {
 	Name       string `json:"name,omitempty"`
 }
 
+// namespacedKinds are the built-in kinds of target that live in a namespace.
+var namespacedKinds = map[string]bool{
+	"Pod": true, "Service": true, "Endpoints": true, "ConfigMap": true, "Secret": true,
+	"PersistentVolumeClaim": true, "Deployment": true, "StatefulSet": true, "DaemonSet": true,
+	"ReplicaSet": true, "Job": true, "CronJob": true, "Ingress": true,
+}
+
+// WithDefaultNamespace returns r with namespace filled in when r is of a
+// namespaced kind and names none.
+func (r ObjectRef) WithDefaultNamespace(namespace string) ObjectRef {
+	if r.Namespace == "" && namespacedKinds[r.Kind] {
+		r.Namespace = namespace
+	}
+	return r
+}
+
+// RootCause links a symptom to the FaultDetection that reports the anomaly
+// of an object its target depends on.
+type RootCause struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Anomalous upstream object the target depends on, directly or
+	// through other objects
+	Object ObjectRef `json:"object"`
+}
+
 // FaultDetectionStatus captures monitoring results.
 type NodeResult struct {
 	NodeName string `json:"nodeName,omitempty"`
@@ -43,20 +74,182 @@ type NodeResult struct {
 	Message  string `json:"message,omitempty"`
 }
 
+// Condition types of FaultDetection.
+const (
+	// ConditionTemplateAvailable is true when the referenced template exists
+	// and renders with the FaultDetection's parameters.
+	ConditionTemplateAvailable = "TemplateAvailable"
+)
+
 type FaultDetectionStatus struct {
-	LastRun     *metav1.Time `json:"lastRun,omitempty"`
-	Results     []Result     `json:"results,omitempty"`
-	NodeResults []NodeResult `json:"nodeResults,omitempty"`
-	Anomalous   bool         `json:"anomalous,omitempty"`
-	Reason      string       `json:"reason,omitempty"`
-	Triggered   bool         `json:"triggered,omitempty"`
-	TriggerMsg  string       `json:"triggerMsg,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+	LastRun     *metav1.Time       `json:"lastRun,omitempty"`
+	Results     []Result           `json:"results,omitempty"`
+	NodeResults []NodeResult       `json:"nodeResults,omitempty"`
+	Anomalous   bool               `json:"anomalous,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+	Triggered   bool               `json:"triggered,omitempty"`
+	TriggerMsg  string             `json:"triggerMsg,omitempty"`
+	// Rendered trigger request of the last anomaly
+	TriggerAPI     string `json:"triggerAPI,omitempty"`
+	TriggerPayload string `json:"triggerPayload,omitempty"`
+	// True when the last trigger was only recorded, in dry run
+	DryRun bool `json:"dryRun,omitempty"`
+	// True when the last anomaly fell into a maintenance window and did not trigger
+	Silenced bool `json:"silenced,omitempty"`
+	// MaintenanceWindow that silenced it
+	SilencedBy string `json:"silencedBy,omitempty"`
+	// True when the last anomaly is explained by an anomalous object the
+	// target depends on, such as the node of a pod, and did not trigger
+	Symptom bool `json:"symptom,omitempty"`
+	// Detection of the upstream anomaly
+	RootCause *RootCause `json:"rootCause,omitempty"`
+	// Incident the current anomaly belongs to
+	Incident string `json:"incident,omitempty"`
+	// Verdicts of the template's statistical detectors
+	StatisticalResults []StatisticalResult `json:"statisticalResults,omitempty"`
+	// ConfigMap holding the detectors' baselines
+	BaselineRef string `json:"baselineRef,omitempty"`
+	// Outcome of the ML model call
+	MLResult *MLResult `json:"mlResult,omitempty"`
+	// Sub-detector verdicts of composite templates
+	CompositeResults []SubDetectorResult `json:"compositeResults,omitempty"`
+	// Objects with matching Events, most frequent first
+	EventResults []EventResult `json:"eventResults,omitempty"`
+	// Log pattern matches per pod and container
+	LogResults []LogResult `json:"logResults,omitempty"`
+	// Outcome of each probe per endpoint
+	ProbeResults []ProbeResult `json:"probeResults,omitempty"`
+	// Firing Alertmanager alerts for the target
+	AlertResults []AlertResult `json:"alertResults,omitempty"`
+	// Recent anomaly occurrences, newest last. DetectionFeedback refers to them by ID.
+	Occurrences []AnomalyOccurrence `json:"occurrences,omitempty"`
+}
+
+// AnomalyOccurrence is one continuous period during which the target was anomalous.
+type AnomalyOccurrence struct {
+	ID        string      `json:"id"`
+	StartTime metav1.Time `json:"startTime"`
+	// Unset while the anomaly is ongoing
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	Reason  string       `json:"reason,omitempty"`
+	// Query results when the anomaly was first detected
+	Results []Result `json:"results,omitempty"`
+}
+
+// EventResult summarises the matching Events of one involved object.
+type EventResult struct {
+	Object ObjectRef `json:"object"`
+	// Reason of the most recent matching event
+	Reason string `json:"reason,omitempty"`
+	// Occurrences within the window
+	Count    int32        `json:"count"`
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// Message of the most recent matching event
+	Message   string `json:"message,omitempty"`
+	Anomalous bool   `json:"anomalous,omitempty"`
+}
+
+// LogResult counts the matches of one pattern in one container.
+type LogResult struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Pattern   string `json:"pattern,omitempty"`
+	// Matches within the window
+	Count     int32 `json:"count,omitempty"`
+	Anomalous bool  `json:"anomalous,omitempty"`
+	// Most recent matching lines, truncated
+	Lines []string `json:"lines,omitempty"`
+	// Set when the container's logs could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// ProbeResult is the outcome of one probe against one endpoint.
+type ProbeResult struct {
+	Probe string `json:"probe"`
+	// Pod name, or the probed address
+	Endpoint string `json:"endpoint"`
+	Success  bool   `json:"success"`
+	// HTTP status code
+	StatusCode int32 `json:"statusCode,omitempty"`
+	// Time to answer, e.g. "12ms"
+	Latency string `json:"latency,omitempty"`
+	// Why the probe failed
+	Message string `json:"message,omitempty"`
+}
+
+// AlertResult is one firing alert.
+type AlertResult struct {
+	Alert       string `json:"alert"`
+	Fingerprint string `json:"fingerprint"`
+	// Node, pod or namespace the alert was mapped to
+	Target   ObjectRef   `json:"target,omitempty"`
+	StartsAt metav1.Time `json:"startsAt"`
+	// summary or description annotation
+	Summary string `json:"summary,omitempty"`
+}
+
+// SubDetectorResult is the verdict of one composite sub-detector.
+type SubDetectorResult struct {
+	Name string `json:"name"`
+	// field, prometheus, statistical, ml or probe
+	Type string `json:"type"`
+	// Whether the condition currently holds
+	Condition bool `json:"condition,omitempty"`
+	// Since when the condition has held
+	Since *metav1.Time `json:"since,omitempty"`
+	// Whether the condition has held for the detector's For duration
+	Firing  bool   `json:"firing,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// MLResult records the model's verdict for the target. When the model
+// scored several targets, such as every node of a cluster-wide check, the
+// verdict is that of the most anomalous one.
+type MLResult struct {
+	Model     string `json:"model,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+	Score     string `json:"score,omitempty"`
+	Threshold string `json:"threshold,omitempty"`
+	Anomalous bool   `json:"anomalous,omitempty"`
+	// Features that contributed most to the score
+	TopFeatures []FeatureContribution `json:"topFeatures,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	// Verdict per target, when the model scored more than one
+	Targets []MLTargetResult `json:"targets,omitempty"`
+}
+
+// MLTargetResult is the model's verdict for one target.
+type MLTargetResult struct {
+	Target    ObjectRef `json:"target"`
+	Score     string    `json:"score,omitempty"`
+	Anomalous bool      `json:"anomalous,omitempty"`
+}
+
+// FeatureContribution is one feature's share of an ML score.
+type FeatureContribution struct {
+	Name         string `json:"name"`
+	Contribution string `json:"contribution"`
+}
+
+// StatisticalResult is the verdict of one statistical detector on one metric.
+type StatisticalResult struct {
+	Metric    string `json:"metric"`
+	Algorithm string `json:"algorithm"`
+	Score     string `json:"score,omitempty"`
+	Anomalous bool   `json:"anomalous,omitempty"`
+	Message   string `json:"message,omitempty"`
 }
 
 // Result stores metric query output
 type Result struct {
 	Metric string `json:"metric"`
 	Value  string `json:"value"`
+	// Error returned by the data source, if the query failed
+	Error string `json:"error,omitempty"`
 }
 
 // +kubebuilder:object:root=true
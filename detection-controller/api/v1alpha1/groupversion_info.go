@@ -40,5 +40,11 @@ func init() {
 		&DetectionTemplateList{},
 		&FaultDetection{},
 		&FaultDetectionList{},
+		&DetectionFeedback{},
+		&DetectionFeedbackList{},
+		&MaintenanceWindow{},
+		&MaintenanceWindowList{},
+		&Incident{},
+		&IncidentList{},
 	)
 }
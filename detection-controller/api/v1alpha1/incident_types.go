@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IncidentPhase is the lifecycle state of an Incident.
+// +kubebuilder:validation:Enum=Open;Recovering;Resolved
+type IncidentPhase string
+
+const (
+	// IncidentOpen incidents have anomalies and no recovery yet
+	IncidentOpen IncidentPhase = "Open"
+	// IncidentRecovering incidents have a recovery workflow running or done
+	IncidentRecovering IncidentPhase = "Recovering"
+	// IncidentResolved incidents have no anomalous detection left
+	IncidentResolved IncidentPhase = "Resolved"
+)
+
+// Sources of incident timeline entries.
+const (
+	IncidentSourceDetection = "detection"
+	IncidentSourceRecovery  = "recovery"
+)
+
+// IncidentSpec tells what the incident is about. Incidents are created by
+// the detection controller.
+type IncidentSpec struct {
+	// Object whose anomaly opened the incident: the target of the
+	// FaultDetection, or the FaultDetection itself if it has no target
+	Root ObjectRef `json:"root"`
+	// Node the root object is or runs on. Anomalies on the same node shortly
+	// after the incident opened are correlated into it.
+	Node string `json:"node,omitempty"`
+}
+
+// IncidentDetection is a FaultDetection that took part in an incident.
+type IncidentDetection struct {
+	Namespace string     `json:"namespace"`
+	Name      string     `json:"name"`
+	Template  string     `json:"template,omitempty"`
+	Target    *ObjectRef `json:"target,omitempty"`
+	// True when the anomaly was a symptom of the root cause
+	Symptom     bool         `json:"symptom,omitempty"`
+	Reason      string       `json:"reason,omitempty"`
+	DetectedAt  metav1.Time  `json:"detectedAt"`
+	RecoveredAt *metav1.Time `json:"recoveredAt,omitempty"`
+}
+
+// IncidentTrigger is a RecoveryTrigger that took part in an incident.
+// The conflict-aware recovery controller keeps it current.
+type IncidentTrigger struct {
+	Namespace    string       `json:"namespace"`
+	Name         string       `json:"name"`
+	FailureType  string       `json:"failureType,omitempty"`
+	State        string       `json:"state,omitempty"`
+	WorkflowName string       `json:"workflowName,omitempty"`
+	StartedAt    *metav1.Time `json:"startedAt,omitempty"`
+	FinishedAt   *metav1.Time `json:"finishedAt,omitempty"`
+}
+
+// IncidentEvent is one state transition on the incident timeline.
+type IncidentEvent struct {
+	Time metav1.Time `json:"time"`
+	// detection or recovery
+	Source string `json:"source"`
+	// Object that changed state, as Kind/namespace/name
+	Object  string `json:"object"`
+	Message string `json:"message,omitempty"`
+}
+
+// IncidentStatus holds the correlated detections and recoveries.
+type IncidentStatus struct {
+	Phase      IncidentPhase       `json:"phase,omitempty"`
+	Detections []IncidentDetection `json:"detections,omitempty"`
+	Triggers   []IncidentTrigger   `json:"triggers,omitempty"`
+	// State transitions, oldest first; only the newest are kept
+	Timeline []IncidentEvent `json:"timeline,omitempty"`
+
+	// Earliest evidence of the fault, such as the start of a firing alert;
+	// the first detection when there is none
+	OnsetTime *metav1.Time `json:"onsetTime,omitempty"`
+	// First anomaly detected
+	DetectedAt *metav1.Time `json:"detectedAt,omitempty"`
+	// First recovery workflow started
+	RecoveryStartedAt *metav1.Time `json:"recoveryStartedAt,omitempty"`
+	// Last detection recovered
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+	// DetectedAt - OnsetTime
+	TimeToDetect *metav1.Duration `json:"timeToDetect,omitempty"`
+	// ResolvedAt - DetectedAt
+	TimeToRecover *metav1.Duration `json:"timeToRecover,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.root.kind`
+// +kubebuilder:printcolumn:name="Root",type=string,JSONPath=`.spec.root.name`
+// +kubebuilder:printcolumn:name="TTD",type=string,JSONPath=`.status.timeToDetect`
+// +kubebuilder:printcolumn:name="TTR",type=string,JSONPath=`.status.timeToRecover`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type Incident struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IncidentSpec   `json:"spec,omitempty"`
+	Status IncidentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type IncidentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Incident `json:"items"`
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenanceWindowSpec selects what is silenced and when. Templates select
+// FaultDetections and FailureTypes select RecoveryTriggers; a window that
+// sets only one of them covers only that kind of object. The other matchers
+// apply to both, and every matcher that is set must match.
+// +kubebuilder:validation:XValidation:rule="has(self.schedule) || has(self.end)",message="either schedule or end must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.schedule) == has(self.duration)",message="schedule and duration must be set together"
+type MaintenanceWindowSpec struct {
+	// DetectionTemplates whose FaultDetections are silenced
+	Templates []string `json:"templates,omitempty"`
+	// Failure types of the RecoveryTriggers that are held
+	FailureTypes []string `json:"failureTypes,omitempty"`
+	// Namespaces of the FaultDetections and RecoveryTriggers
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Labels of the FaultDetection's target or the RecoveryTrigger's target objects
+	TargetSelector *metav1.LabelSelector `json:"targetSelector,omitempty"`
+
+	// Start of the window, or of the recurrence when schedule is set. Defaults to now.
+	Start *metav1.Time `json:"start,omitempty"`
+	// End of the window, or of the recurrence when schedule is set
+	End *metav1.Time `json:"end,omitempty"`
+	// Cron expression (minute hour day-of-month month day-of-week) at which a recurring window opens
+	Schedule string `json:"schedule,omitempty"`
+	// How long each recurring window stays open
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// IANA time zone the schedule is evaluated in. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Why the window exists, e.g. the change ticket
+	Comment string `json:"comment,omitempty"`
+}
+
+// MaintenanceWindowStatus tells whether the window is open.
+type MaintenanceWindowStatus struct {
+	Active bool `json:"active,omitempty"`
+	// Bounds of the open window
+	ActiveSince *metav1.Time `json:"activeSince,omitempty"`
+	ActiveUntil *metav1.Time `json:"activeUntil,omitempty"`
+	// When the window opens next, if it is closed
+	NextStart *metav1.Time `json:"nextStart,omitempty"`
+	// Why the window cannot be evaluated
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Active",type=boolean,JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="Until",type=date,JSONPath=`.status.activeUntil`
+// +kubebuilder:printcolumn:name="Next",type=date,JSONPath=`.status.nextStart`
+type MaintenanceWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MaintenanceWindowSpec   `json:"spec,omitempty"`
+	Status MaintenanceWindowStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type MaintenanceWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MaintenanceWindow `json:"items"`
+}
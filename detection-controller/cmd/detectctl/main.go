@@ -0,0 +1,285 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command detectctl evaluates FaultDetections against fixture data, to test
+// DetectionTemplates before applying them:
+//
+//	detectctl -f template.yaml -f cluster.yaml --fixtures prometheus.yaml --expect trigger
+//
+// The -f files hold the DetectionTemplates, FaultDetections and the objects
+// they read, such as Nodes and Pods. The fixtures file holds recorded
+// Prometheus and ML model responses. Every FaultDetection is evaluated once
+// and its results printed. The exit code is 0 when every FaultDetection
+// evaluated as expected, 1 when one did not, and 2 on invalid input,
+// including objects the admission webhooks would reject.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/harness"
+)
+
+// files collects repeated -f flags.
+type files []string
+
+func (f *files) String() string     { return strings.Join(*f, ",") }
+func (f *files) Set(v string) error { *f = append(*f, v); return nil }
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("detectctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var objectFiles files
+	var fixturesFile, expect, output string
+	var verbose bool
+	fs.Var(&objectFiles, "f", "A YAML file of DetectionTemplates, FaultDetections and fixture objects; repeatable, - for stdin.")
+	fs.StringVar(&fixturesFile, "fixtures", "", "A YAML file of recorded Prometheus and ML model responses.")
+	fs.StringVar(&expect, "expect", "",
+		"The outcome every FaultDetection must have: trigger or none. The "+harness.AnnotationExpect+
+			" annotation overrides it per FaultDetection.")
+	fs.StringVar(&output, "o", "text", "Output format: text or json.")
+	fs.BoolVar(&verbose, "v", false, "Log the evaluation to stderr.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if len(objectFiles) == 0 || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: detectctl -f FILE [-f FILE...] [--fixtures FILE] [--expect trigger|none] [-o text|json]")
+		return 2
+	}
+	if output != "text" && output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", output)
+		return 2
+	}
+	if verbose {
+		ctrl.SetLogger(zap.New(zap.WriteTo(stderr), zap.UseDevMode(true)))
+	}
+
+	scheme := harness.NewScheme()
+	var objs []client.Object
+	for _, name := range objectFiles {
+		loaded, err := loadObjects(scheme, name)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			return 2
+		}
+		objs = append(objs, loaded...)
+	}
+	var fixtures *harness.Fixtures
+	if fixturesFile != "" {
+		data, err := os.ReadFile(fixturesFile)
+		if err == nil {
+			fixtures, err = harness.LoadFixtures(data)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", fixturesFile, err)
+			return 2
+		}
+	}
+
+	results, err := harness.Run(context.Background(), scheme, objs, fixtures)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(stderr, "no FaultDetection to evaluate")
+		return 2
+	}
+
+	code := 0
+	failures := make([]error, len(results))
+	for i := range results {
+		if failures[i] = results[i].Check(expect); failures[i] != nil {
+			code = 1
+		}
+	}
+	if output == "json" {
+		err = printJSON(stdout, results, failures)
+	} else {
+		err = printText(stdout, results, failures)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return code
+}
+
+func loadObjects(scheme *runtime.Scheme, name string) ([]client.Object, error) {
+	if name == "-" {
+		return harness.LoadObjects(scheme, os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return harness.LoadObjects(scheme, f)
+}
+
+// verdict summarizes the outcome of r.
+func verdict(r *harness.Result) string {
+	switch {
+	case r.Err != nil:
+		return "ERROR"
+	case r.Fires():
+		return "TRIGGER"
+	case r.Status.Symptom:
+		return "SYMPTOM"
+	case r.Status.Silenced:
+		return "SILENCED"
+	default:
+		return "OK"
+	}
+}
+
+func printText(w io.Writer, results []harness.Result, failures []error) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i := range results {
+		r := &results[i]
+		st := &r.Status
+		fmt.Fprintf(tw, "%s/%s\ttemplate %s\t%s\n", r.Namespace, r.Name, r.Template, verdict(r))
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(tw, "  error\t%v\n", r.Err)
+		case r.Fires():
+			fmt.Fprintf(tw, "  reason\t%s\n", st.Reason)
+			if st.TriggerAPI != "" {
+				fmt.Fprintf(tw, "  triggerAPI\t%s\n", st.TriggerAPI)
+			}
+			if st.TriggerPayload != "" {
+				fmt.Fprintf(tw, "  triggerPayload\t%s\n", strings.TrimSpace(st.TriggerPayload))
+			}
+		case st.Anomalous:
+			fmt.Fprintf(tw, "  reason\t%s\n", st.Reason)
+			fmt.Fprintf(tw, "  trigger\t%s\n", st.TriggerMsg)
+		}
+		for _, res := range st.Results {
+			if res.Error != "" {
+				fmt.Fprintf(tw, "  metric %s\terror: %s\n", res.Metric, res.Error)
+			} else {
+				fmt.Fprintf(tw, "  metric %s\t%s\n", res.Metric, res.Value)
+			}
+		}
+		for _, n := range st.NodeResults {
+			fmt.Fprintf(tw, "  node %s\t%s\n", n.NodeName, okString(n.Ok, n.Message))
+		}
+		for _, s := range st.StatisticalResults {
+			fmt.Fprintf(tw, "  %s %s\tscore %s\t%s\n", s.Algorithm, s.Metric, s.Score, anomalousString(s.Anomalous, s.Message))
+		}
+		if ml := st.MLResult; ml != nil {
+			msg := ml.Error
+			if msg == "" {
+				msg = "threshold " + ml.Threshold
+			}
+			fmt.Fprintf(tw, "  ml %s\tscore %s\t%s\n", ml.Model, ml.Score, anomalousString(ml.Anomalous, msg))
+			for _, t := range ml.Targets {
+				fmt.Fprintf(tw, "  ml %s %s/%s\tscore %s\t%s\n", ml.Model, t.Target.Kind, t.Target.Name, t.Score, anomalousString(t.Anomalous, ""))
+			}
+		}
+		for _, c := range st.CompositeResults {
+			fmt.Fprintf(tw, "  %s %s\t%s\t%s\n", c.Type, c.Name, c.Value, anomalousString(c.Firing, c.Message))
+		}
+		for _, e := range st.EventResults {
+			fmt.Fprintf(tw, "  event %s %s/%s\t%s x%d\t%s\n", e.Object.Kind, e.Object.Namespace, e.Object.Name,
+				e.Reason, e.Count, anomalousString(e.Anomalous, e.Message))
+		}
+		for _, l := range st.LogResults {
+			msg := l.Error
+			if msg == "" {
+				msg = fmt.Sprintf("%q x%d", l.Pattern, l.Count)
+			}
+			fmt.Fprintf(tw, "  logs %s/%s\t%s\n", l.Pod, l.Container, anomalousString(l.Anomalous, msg))
+		}
+		for _, p := range st.ProbeResults {
+			fmt.Fprintf(tw, "  probe %s %s\t%s\n", p.Probe, p.Endpoint, okString(p.Success, p.Message))
+		}
+		for _, a := range st.AlertResults {
+			fmt.Fprintf(tw, "  alert %s %s/%s\t%s\n", a.Alert, a.Target.Kind, a.Target.Name, a.Summary)
+		}
+		if failures[i] != nil && r.Err == nil {
+			fmt.Fprintf(tw, "  FAIL\t%v\n", failures[i])
+		}
+	}
+	return tw.Flush()
+}
+
+func okString(ok bool, msg string) string {
+	if ok {
+		return strings.TrimSpace("ok " + msg)
+	}
+	return strings.TrimSpace("failing " + msg)
+}
+
+func anomalousString(anomalous bool, msg string) string {
+	if anomalous {
+		return strings.TrimSpace("anomalous " + msg)
+	}
+	return msg
+}
+
+// jsonResult is the -o json form of a result.
+type jsonResult struct {
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Template  string                        `json:"template"`
+	Verdict   string                        `json:"verdict"`
+	Fires     bool                          `json:"fires"`
+	Error     string                        `json:"error,omitempty"`
+	Failure   string                        `json:"failure,omitempty"`
+	Status    detectv1.FaultDetectionStatus `json:"status"`
+}
+
+func printJSON(w io.Writer, results []harness.Result, failures []error) error {
+	out := make([]jsonResult, len(results))
+	for i := range results {
+		r := &results[i]
+		out[i] = jsonResult{
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Template:  r.Template,
+			Verdict:   verdict(r),
+			Fires:     r.Fires(),
+			Status:    r.Status,
+		}
+		if r.Err != nil {
+			out[i].Error = r.Err.Error()
+		}
+		if failures[i] != nil {
+			out[i].Failure = failures[i].Error()
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
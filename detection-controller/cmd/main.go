@@ -18,9 +18,12 @@ package main
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,6 +31,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
@@ -38,7 +42,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/alertmanager"
 	"github.com/phuongbac/detection-controller/internal/controller"
+	"github.com/phuongbac/detection-controller/internal/export"
+	"github.com/phuongbac/detection-controller/internal/logtail"
+	webhookdetectv1alpha1 "github.com/phuongbac/detection-controller/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -63,6 +71,15 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var modelNamespace string
+	var secretNamespace string
+	var incidentRetention time.Duration
+	var feedbackExportAddr, feedbackExportTokenFile, feedbackExportCertPath, feedbackExportClientCA string
+	var feedbackExportInsecure bool
+	var alertmanagerAddr, alertmanagerRules, alertmanagerNamespace string
+	var alertmanagerTokenFile, alertmanagerCertPath, alertmanagerClientCA string
+	var alertmanagerInsecure bool
+	var dryRun bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +98,43 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&modelNamespace, "model-namespace", "detection-controller-system",
+		"The namespace in which model servers for DetectionTemplates with spec.ml.image are deployed.")
+	flag.StringVar(&secretNamespace, "secret-namespace", "detection-controller-system",
+		"The only namespace data source auth Secrets are read from. The manager Role grants reading Secrets "+
+			"in the controller namespace only.")
+	flag.DurationVar(&incidentRetention, "incident-retention", 7*24*time.Hour,
+		"How long resolved Incidents are kept before they are deleted, or 0 to keep them.")
+	flag.StringVar(&feedbackExportAddr, "feedback-export-bind-address", "0",
+		"The address the labelled-detection export endpoint ("+export.Path+") binds to, or 0 to disable it.")
+	flag.StringVar(&feedbackExportTokenFile, "feedback-export-token-file", "",
+		"A file holding the bearer token requests to the feedback export endpoint must carry.")
+	flag.StringVar(&feedbackExportCertPath, "feedback-export-cert-path", "",
+		"The directory that contains the tls.crt and tls.key the feedback export endpoint serves HTTPS with.")
+	flag.StringVar(&feedbackExportClientCA, "feedback-export-client-ca", "",
+		"A PEM file of the CAs whose client certificates the feedback export endpoint accepts. "+
+			"Requires --feedback-export-cert-path.")
+	flag.BoolVar(&feedbackExportInsecure, "feedback-export-insecure", false,
+		"If set, the feedback export endpoint serves requests without a token or client certificate. "+
+			"The export holds labelled detections, metric history and comments of every namespace.")
+	flag.StringVar(&alertmanagerAddr, "alertmanager-bind-address", "0",
+		"The address the Alertmanager webhook receiver ("+alertmanager.Path+") binds to, or 0 to disable it.")
+	flag.StringVar(&alertmanagerRules, "alertmanager-rules", "",
+		"A file of rules that turn received alerts into RecoveryTriggers.")
+	flag.StringVar(&alertmanagerNamespace, "alertmanager-trigger-namespace", "default",
+		"The namespace of RecoveryTriggers whose rule and alert name none.")
+	flag.StringVar(&alertmanagerTokenFile, "alertmanager-token-file", "",
+		"A file holding the bearer token Alertmanager notifications must carry.")
+	flag.StringVar(&alertmanagerCertPath, "alertmanager-cert-path", "",
+		"The directory that contains the tls.crt and tls.key the Alertmanager webhook receiver serves HTTPS with.")
+	flag.StringVar(&alertmanagerClientCA, "alertmanager-client-ca", "",
+		"A PEM file of the CAs whose client certificates the Alertmanager webhook receiver accepts. "+
+			"Requires --alertmanager-cert-path.")
+	flag.BoolVar(&alertmanagerInsecure, "alertmanager-insecure", false,
+		"If set, the Alertmanager webhook receiver accepts notifications without a token or client certificate. "+
+			"Unauthenticated notifications can create RecoveryTriggers, which run recovery workflows.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, anomalies and alerts record the triggers they would fire without sending them.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -202,22 +256,148 @@ func main() {
 		os.Exit(1)
 	}
 
-	// if err := (&controller.DetectionTemplateReconciler{
-	// 	Client: mgr.GetClient(),
-	// 	Scheme: mgr.GetScheme(),
-	// }).SetupWithManager(mgr); err != nil {
-	// 	setupLog.Error(err, "unable to create controller", "controller", "DetectionTemplate")
-	// 	os.Exit(1)
-	// }
+	if err := (&controller.DetectionTemplateReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		ModelNamespace: modelNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DetectionTemplate")
+		os.Exit(1)
+	}
+	// Container logs are streamed, which the controller-runtime client cannot do
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+	// Alerts received from Alertmanager are kept in memory for the FaultDetection controller
+	var alerts *alertmanager.Store
+	if alertmanagerAddr != "0" {
+		alerts = alertmanager.NewStore()
+	}
 	if err := (&controller.FaultDetectionReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		APIReader:       mgr.GetAPIReader(),
+		Logs:            logtail.NewSource(clientset),
+		Alerts:          alerts,
+		DryRun:          dryRun,
+		SecretNamespace: secretNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "FaultDetection")
+		os.Exit(1)
+	}
+	if err := (&controller.DetectionFeedbackReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		APIReader:       mgr.GetAPIReader(),
+		SecretNamespace: secretNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DetectionFeedback")
+		os.Exit(1)
+	}
+	if err := (&controller.IncidentReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Retention: incidentRetention,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Incident")
+		os.Exit(1)
+	}
+	if err := (&controller.MaintenanceWindowReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "FaultDetection")
+		setupLog.Error(err, "unable to create controller", "controller", "MaintenanceWindow")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookdetectv1alpha1.SetupDetectionTemplateWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DetectionTemplate")
+			os.Exit(1)
+		}
+		if err := webhookdetectv1alpha1.SetupFaultDetectionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "FaultDetection")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if feedbackExportAddr != "0" {
+		// The export holds data of every namespace, so its clients must be
+		// authenticated unless explicitly waived
+		if feedbackExportTokenFile == "" && feedbackExportClientCA == "" && !feedbackExportInsecure {
+			setupLog.Error(nil, "the feedback export endpoint requires --feedback-export-token-file, "+
+				"--feedback-export-client-ca or --feedback-export-insecure")
+			os.Exit(1)
+		}
+		if feedbackExportClientCA != "" && feedbackExportCertPath == "" {
+			setupLog.Error(nil, "--feedback-export-client-ca requires --feedback-export-cert-path")
+			os.Exit(1)
+		}
+		exportTLS, err := serverTLSConfig(mgr, feedbackExportCertPath, feedbackExportClientCA, tlsOpts)
+		if err != nil {
+			setupLog.Error(err, "unable to configure TLS for the feedback export endpoint")
+			os.Exit(1)
+		}
+		setupLog.Info("Adding feedback export endpoint", "address", feedbackExportAddr, "path", export.Path,
+			"tls", exportTLS != nil, "token", feedbackExportTokenFile != "")
+		if err := mgr.Add(&export.Server{
+			Addr:      feedbackExportAddr,
+			TLSConfig: exportTLS,
+			Handler:   &export.Handler{Reader: mgr.GetClient(), TokenFile: feedbackExportTokenFile},
+		}); err != nil {
+			setupLog.Error(err, "unable to add feedback export endpoint to manager")
+			os.Exit(1)
+		}
+	}
+
+	if alerts != nil {
+		var rules []alertmanager.Rule
+		if alertmanagerRules != "" {
+			cfg, err := alertmanager.LoadConfig(alertmanagerRules)
+			if err != nil {
+				setupLog.Error(err, "unable to load Alertmanager receiver rules")
+				os.Exit(1)
+			}
+			rules = cfg.Rules
+		}
+		// Notifications create RecoveryTriggers, so their sender must be
+		// authenticated unless explicitly waived
+		if alertmanagerTokenFile == "" && alertmanagerClientCA == "" && !alertmanagerInsecure {
+			setupLog.Error(nil, "the Alertmanager webhook receiver requires --alertmanager-token-file, "+
+				"--alertmanager-client-ca or --alertmanager-insecure")
+			os.Exit(1)
+		}
+		if alertmanagerClientCA != "" && alertmanagerCertPath == "" {
+			setupLog.Error(nil, "--alertmanager-client-ca requires --alertmanager-cert-path")
+			os.Exit(1)
+		}
+		alertmanagerTLS, err := serverTLSConfig(mgr, alertmanagerCertPath, alertmanagerClientCA, tlsOpts)
+		if err != nil {
+			setupLog.Error(err, "unable to configure TLS for the Alertmanager webhook receiver")
+			os.Exit(1)
+		}
+		setupLog.Info("Adding Alertmanager webhook receiver", "address", alertmanagerAddr, "path", alertmanager.Path,
+			"rules", len(rules), "tls", alertmanagerTLS != nil, "token", alertmanagerTokenFile != "")
+		if err := mgr.Add(&alertmanager.Server{
+			Addr:      alertmanagerAddr,
+			TLSConfig: alertmanagerTLS,
+			Handler: &alertmanager.Receiver{
+				Client:           mgr.GetClient(),
+				Store:            alerts,
+				Rules:            rules,
+				DefaultNamespace: alertmanagerNamespace,
+				DryRun:           dryRun,
+				TokenFile:        alertmanagerTokenFile,
+			},
+		}); err != nil {
+			setupLog.Error(err, "unable to add Alertmanager webhook receiver to manager")
+			os.Exit(1)
+		}
+	}
+
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -249,3 +429,36 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// serverTLSConfig returns the TLS configuration of an endpoint serving the
+// tls.crt and tls.key of certPath, or nil without certPath. With clientCA it
+// requires client certificates signed by one of its CAs.
+func serverTLSConfig(mgr ctrl.Manager, certPath, clientCA string, tlsOpts []func(*tls.Config)) (*tls.Config, error) {
+	if certPath == "" {
+		return nil, nil
+	}
+	certWatcher, err := certwatcher.New(filepath.Join(certPath, "tls.crt"), filepath.Join(certPath, "tls.key"))
+	if err != nil {
+		return nil, fmt.Errorf("initializing certificate watcher: %w", err)
+	}
+	if err := mgr.Add(certWatcher); err != nil {
+		return nil, fmt.Errorf("adding certificate watcher to manager: %w", err)
+	}
+	cfg := &tls.Config{GetCertificate: certWatcher.GetCertificate, MinVersion: tls.VersionTLS12}
+	for _, opt := range tlsOpts {
+		opt(cfg)
+	}
+	if clientCA != "" {
+		pem, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in client CA %s", clientCA)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package alertmanager receives Alertmanager webhook notifications. Firing
+// alerts are kept in a Store that alert-based DetectionTemplates read, and
+// receiver rules can turn them into RecoveryTriggers directly.
+package alertmanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// Alert statuses.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// DefaultStaleAfter is how long a firing alert is kept without being
+// received again. Alertmanager repeats firing alerts every repeat_interval
+// (4h by default), so this should be longer.
+const DefaultStaleAfter = 12 * time.Hour
+
+// Message is the Alertmanager webhook payload (version 4).
+type Message struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is one alert of a notification.
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Name returns the alertname label.
+func (a *Alert) Name() string {
+	return a.Labels["alertname"]
+}
+
+// Summary returns the summary annotation, or else the description.
+func (a *Alert) Summary() string {
+	if s := a.Annotations["summary"]; s != "" {
+		return s
+	}
+	return a.Annotations["description"]
+}
+
+// Key identifies the alert: its fingerprint, or a hash of its labels when
+// the sender left the fingerprint out.
+func (a *Alert) Key() string {
+	if a.Fingerprint != "" {
+		return a.Fingerprint
+	}
+	names := make([]string, 0, len(a.Labels))
+	for k := range a.Labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, k := range names {
+		h.Write([]byte(k + "\x00" + a.Labels[k] + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Matches reports whether labels carry every matcher.
+func Matches(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Target maps an alert to the pod, node or namespace its labels name, in
+// that order of preference.
+func Target(a *Alert, labels *detectv1.AlertTargetLabels) (detectv1.ObjectRef, bool) {
+	node, pod, namespace := "node", "pod", "namespace"
+	if labels != nil {
+		if labels.Node != "" {
+			node = labels.Node
+		}
+		if labels.Pod != "" {
+			pod = labels.Pod
+		}
+		if labels.Namespace != "" {
+			namespace = labels.Namespace
+		}
+	}
+	ns := a.Labels[namespace]
+	switch {
+	case a.Labels[pod] != "" && ns != "":
+		return detectv1.ObjectRef{Kind: "Pod", Namespace: ns, Name: a.Labels[pod]}, true
+	case a.Labels[node] != "":
+		return detectv1.ObjectRef{Kind: "Node", Name: a.Labels[node]}, true
+	case ns != "":
+		return detectv1.ObjectRef{Kind: "Namespace", Name: ns}, true
+	}
+	return detectv1.ObjectRef{}, false
+}
+
+// Transition is a change of an alert between firing and resolved.
+type Transition struct {
+	Alert    Alert
+	Resolved bool
+}
+
+type storedAlert struct {
+	alert    Alert
+	lastSeen time.Time
+}
+
+// Store keeps the firing alerts. Alertmanager sends the whole group on
+// every notification, so only changes are reported as transitions, except
+// for resolutions: the store may have lost the firing alert to a restart or
+// a leader change, so every resolved alert is reported.
+type Store struct {
+	// StaleAfter drops firing alerts not received again for this long;
+	// DefaultStaleAfter when zero
+	StaleAfter time.Duration
+
+	mu     sync.Mutex
+	firing map[string]storedAlert
+	events chan event.GenericEvent
+	now    func() time.Time
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{
+		firing: map[string]storedAlert{},
+		events: make(chan event.GenericEvent, 128),
+		now:    time.Now,
+	}
+}
+
+// Apply records alerts and returns the transitions they cause. An alert
+// that fires again with a new start time is a new transition; resolved
+// alerts are always transitions.
+func (s *Store) Apply(alerts []Alert) []Transition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	// Grouped notifications may carry the same alert more than once
+	latest := map[string]Alert{}
+	var keys []string
+	for _, a := range alerts {
+		k := a.Key()
+		if _, ok := latest[k]; !ok {
+			keys = append(keys, k)
+		}
+		latest[k] = a
+	}
+
+	var out []Transition
+	for _, k := range keys {
+		a := latest[k]
+		a.Fingerprint = k
+		prev, firing := s.firing[k]
+		if strings.EqualFold(a.Status, StatusResolved) {
+			delete(s.firing, k)
+			out = append(out, Transition{Alert: a, Resolved: true})
+			if firing {
+				s.notify(a)
+			}
+			continue
+		}
+		s.firing[k] = storedAlert{alert: a, lastSeen: now}
+		if !firing || !prev.alert.StartsAt.Equal(a.StartsAt) {
+			out = append(out, Transition{Alert: a})
+			s.notify(a)
+		}
+	}
+	return out
+}
+
+// Revert undoes a firing transition, so that receiving the alert again
+// repeats it. Resolutions are repeated anyway.
+func (s *Store) Revert(t Transition) {
+	if t.Resolved {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.firing, t.Alert.Key())
+}
+
+// Firing returns the firing alerts, oldest first.
+func (s *Store) Firing() []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	staleAfter := s.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+	now := s.now()
+	out := make([]Alert, 0, len(s.firing))
+	for k, sa := range s.firing {
+		if now.Sub(sa.lastSeen) > staleAfter {
+			delete(s.firing, k)
+			continue
+		}
+		out = append(out, sa.alert)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartsAt.Equal(out[j].StartsAt) {
+			return out[i].StartsAt.Before(out[j].StartsAt)
+		}
+		return out[i].Fingerprint < out[j].Fingerprint
+	})
+	return out
+}
+
+// Events delivers an event for every transition, carrying the alert's
+// labels, so that controllers can re-evaluate right away.
+func (s *Store) Events() <-chan event.GenericEvent {
+	return s.events
+}
+
+// notify never blocks the receiver; a dropped event only delays detection
+// until the next interval.
+func (s *Store) notify(a Alert) {
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: a.Fingerprint, Labels: a.Labels}}
+	select {
+	case s.events <- event.GenericEvent{Object: obj}:
+	default:
+	}
+}
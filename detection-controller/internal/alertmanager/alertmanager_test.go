@@ -0,0 +1,345 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+var start = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func nodeAlert(status, node string) Alert {
+	return Alert{
+		Status:      status,
+		Labels:      map[string]string{"alertname": "KubeNodeNotReady", "node": node, "severity": "critical"},
+		Annotations: map[string]string{"summary": node + " is not ready"},
+		StartsAt:    start,
+		Fingerprint: "fp-" + node,
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore()
+	now := start
+	s.now = func() time.Time { return now }
+
+	// The group repeats an alert; it is applied once
+	got := s.Apply([]Alert{nodeAlert(StatusFiring, "a"), nodeAlert(StatusFiring, "b"), nodeAlert(StatusFiring, "a")})
+	if len(got) != 2 || got[0].Resolved || got[1].Resolved {
+		t.Fatalf("transitions = %+v, want a and b firing", got)
+	}
+	if n := len(s.Events()); n != 2 {
+		t.Errorf("events = %d, want one per transition", n)
+	}
+
+	// Alertmanager resends the whole group on every group_interval
+	if got := s.Apply([]Alert{nodeAlert(StatusFiring, "a"), nodeAlert(StatusFiring, "b")}); len(got) != 0 {
+		t.Errorf("transitions = %+v, want none for a repeated notification", got)
+	}
+
+	got = s.Apply([]Alert{nodeAlert(StatusResolved, "a"), nodeAlert(StatusFiring, "b")})
+	if len(got) != 1 || !got[0].Resolved || got[0].Alert.Fingerprint != "fp-a" {
+		t.Errorf("transitions = %+v, want a resolved", got)
+	}
+	if firing := s.Firing(); len(firing) != 1 || firing[0].Labels["node"] != "b" {
+		t.Errorf("firing = %+v, want b", firing)
+	}
+
+	// A failed transition is applied again on the retried notification
+	s.Revert(got[0])
+	if got := s.Apply([]Alert{nodeAlert(StatusResolved, "a")}); len(got) != 1 {
+		t.Errorf("transitions = %+v, want the reverted resolution again", got)
+	}
+
+	// The store may have lost the alert to a restart; it is still resolved
+	if got := NewStore().Apply([]Alert{nodeAlert(StatusResolved, "c")}); len(got) != 1 || !got[0].Resolved {
+		t.Errorf("transitions = %+v, want an unknown alert resolved", got)
+	}
+
+	// A new firing episode of the same alert is a new transition
+	again := nodeAlert(StatusFiring, "b")
+	again.StartsAt = start.Add(time.Hour)
+	if got := s.Apply([]Alert{again}); len(got) != 1 {
+		t.Errorf("transitions = %+v, want b firing again", got)
+	}
+
+	now = now.Add(DefaultStaleAfter + time.Minute)
+	if firing := s.Firing(); len(firing) != 0 {
+		t.Errorf("firing = %+v, want stale alerts dropped", firing)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		custom *detectv1.AlertTargetLabels
+		want   detectv1.ObjectRef
+	}{
+		{
+			labels: map[string]string{"pod": "checkout-0", "namespace": "shop", "node": "node-a"},
+			want:   detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-0"},
+		},
+		{
+			labels: map[string]string{"node": "node-a"},
+			want:   detectv1.ObjectRef{Kind: "Node", Name: "node-a"},
+		},
+		{
+			labels: map[string]string{"namespace": "shop"},
+			want:   detectv1.ObjectRef{Kind: "Namespace", Name: "shop"},
+		},
+		{
+			labels: map[string]string{"instance": "node-a", "node": "ignored"},
+			custom: &detectv1.AlertTargetLabels{Node: "instance"},
+			want:   detectv1.ObjectRef{Kind: "Node", Name: "node-a"},
+		},
+	}
+	for _, tt := range tests {
+		got, ok := Target(&Alert{Labels: tt.labels}, tt.custom)
+		if !ok || got != tt.want {
+			t.Errorf("Target(%v) = %+v, %v; want %+v", tt.labels, got, ok, tt.want)
+		}
+	}
+	if _, ok := Target(&Alert{Labels: map[string]string{"alertname": "Watchdog"}}, nil); ok {
+		t.Error("expected no target without node, pod or namespace labels")
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	rc := &Receiver{
+		Client: c,
+		Store:  NewStore(),
+		Rules: []Rule{{
+			Name:             "node-not-ready",
+			Matchers:         map[string]string{"alertname": "KubeNodeNotReady"},
+			FailureType:      "NodeNotReady",
+			WorkflowTemplate: "node-recovery",
+			Namespace:        "recovery",
+		}},
+		DefaultNamespace: "default",
+	}
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		rc.ServeHTTP(w, req)
+		return w.Code
+	}
+	triggers := func() []unstructured.Unstructured {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(RecoveryTriggerGVK.GroupVersion().WithKind("RecoveryTriggerList"))
+		if err := c.List(context.Background(), list, client.InNamespace("recovery")); err != nil {
+			t.Fatal(err)
+		}
+		return list.Items
+	}
+
+	firing := `{"version":"4","status":"firing","alerts":[
+		{"status":"firing","labels":{"alertname":"KubeNodeNotReady","node":"node-a"},"startsAt":"2025-06-01T12:00:00Z","fingerprint":"aaa"},
+		{"status":"firing","labels":{"alertname":"KubeNodeNotReady","node":"node-b"},"startsAt":"2025-06-01T12:00:00Z","fingerprint":"bbb"},
+		{"status":"firing","labels":{"alertname":"Watchdog"},"startsAt":"2025-06-01T12:00:00Z","fingerprint":"ccc"}]}`
+	if code := post(firing); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if code := post(firing); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	items := triggers()
+	if len(items) != 2 {
+		t.Fatalf("triggers = %d, want one per node", len(items))
+	}
+	for _, u := range items {
+		spec, _, _ := unstructured.NestedMap(u.Object, "spec")
+		if spec["failureType"] != "NodeNotReady" || spec["workflowTemplate"] != "node-recovery" {
+			t.Errorf("spec = %v", spec)
+		}
+		objs, _, _ := unstructured.NestedSlice(u.Object, "spec", "targetObjects")
+		if len(objs) != 1 || objs[0].(map[string]interface{})["kind"] != "Node" {
+			t.Errorf("targetObjects = %v", objs)
+		}
+		if u.GetLabels()[LabelRule] != "node-not-ready" {
+			t.Errorf("labels = %v", u.GetLabels())
+		}
+	}
+
+	// node-b's workflow was submitted before it resolved; it is kept
+	for i := range items {
+		if items[i].GetLabels()[LabelFingerprint] == "bbb" {
+			_ = unstructured.SetNestedField(items[i].Object, "node-recovery-x1", "status", "workflowName")
+			if err := c.Update(context.Background(), &items[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	resolved := strings.ReplaceAll(firing, `"status":"firing","labels"`, `"status":"resolved","labels"`)
+	if code := post(resolved); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if items := triggers(); len(items) != 1 || items[0].GetLabels()[LabelFingerprint] != "bbb" {
+		t.Errorf("triggers = %v, want only the submitted one left", items)
+	}
+
+	// After a restart the store no longer knows the alert, but its trigger
+	// is still deleted when it resolves
+	if code := post(strings.ReplaceAll(firing, "node-a", "node-d")); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	rc.Store = NewStore()
+	if code := post(strings.ReplaceAll(resolved, "node-a", "node-d")); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if items := triggers(); len(items) != 1 || items[0].GetLabels()[LabelFingerprint] != "bbb" {
+		t.Errorf("triggers = %v, want the trigger of the restarted alert deleted", items)
+	}
+
+	// In dry run alerts are recorded, but no trigger is created or deleted
+	rc.DryRun = true
+	if code := post(strings.ReplaceAll(firing, "node-a", "node-c")); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if code := post(strings.ReplaceAll(resolved, "node-a", "node-c")); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if items := triggers(); len(items) != 1 || items[0].GetLabels()[LabelFingerprint] != "bbb" {
+		t.Errorf("triggers = %v, want dry run to leave them alone", items)
+	}
+
+	if code := post(`{"version":"3"}`); code != http.StatusBadRequest {
+		t.Errorf("status = %d, want unsupported versions rejected", code)
+	}
+}
+
+func TestReceiverCancelRace(t *testing.T) {
+	ctx := context.Background()
+	rule := &Rule{
+		Name:             "node-not-ready",
+		Matchers:         map[string]string{"alertname": "KubeNodeNotReady"},
+		FailureType:      "NodeNotReady",
+		WorkflowTemplate: "node-recovery",
+		Namespace:        "recovery",
+	}
+	alert := nodeAlert("firing", "node-a")
+
+	// The trigger controller submits the workflow right after the receiver
+	// read the trigger
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			submitted := obj.DeepCopyObject().(*unstructured.Unstructured)
+			_ = unstructured.SetNestedField(submitted.Object, "node-recovery-x1", "status", "workflowName")
+			return c.Update(ctx, submitted)
+		},
+	}).Build()
+	rc := &Receiver{Client: c, Store: NewStore(), Rules: []Rule{*rule}}
+	if err := rc.trigger(ctx, rule, &alert); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.cancel(ctx, rule, &alert); err != nil {
+		t.Fatal(err)
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(RecoveryTriggerGVK.GroupVersion().WithKind("RecoveryTriggerList"))
+	if err := c.List(ctx, list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Errorf("triggers = %d, want the started one kept", len(list.Items))
+	}
+}
+
+func TestReceiverToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rc := &Receiver{
+		Client:    fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(),
+		Store:     NewStore(),
+		TokenFile: tokenFile,
+	}
+	post := func(auth string) int {
+		req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(`{"version":"4","alerts":[]}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		rc.ServeHTTP(w, req)
+		return w.Code
+	}
+	for auth, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Basic s3cret":  http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		if code := post(auth); code != want {
+			t.Errorf("Authorization %q: status = %d, want %d", auth, code, want)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "rules.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write(`
+rules:
+- name: node-not-ready
+  matchers: {alertname: KubeNodeNotReady}
+  failureType: NodeNotReady
+  workflowTemplate: node-recovery
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Rules) != 1 || cfg.Rules[0].Matchers["alertname"] != "KubeNodeNotReady" {
+		t.Errorf("rules = %+v", cfg.Rules)
+	}
+
+	for _, bad := range []string{
+		"rules:\n- name: Node_Ready\n  matchers: {a: b}\n  failureType: x\n  workflowTemplate: y\n",
+		"rules:\n- name: ready\n  failureType: x\n  workflowTemplate: y\n",
+		"rules:\n- name: ready\n  matchers: {a: b}\n",
+		"rules:\n- name: ready\n  matcher: {a: b}\n",
+	} {
+		if _, err := LoadConfig(write(bad)); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// Path is where Alertmanager posts notifications.
+const Path = "/alertmanager/webhook"
+
+// maxMessageBytes bounds a notification body.
+const maxMessageBytes = 4 << 20
+
+// Labels set on the RecoveryTriggers the receiver creates.
+const (
+	LabelRule        = "detect.failure-recovery.io/alert-rule"
+	LabelFingerprint = "detect.failure-recovery.io/alert-fingerprint"
+)
+
+// RecoveryTriggerGVK is the kind the conflict-aware controller recovers from.
+var RecoveryTriggerGVK = schema.GroupVersionKind{
+	Group:   "recovery.workflow-recovery.io",
+	Version: "v1alpha1",
+	Kind:    "RecoveryTrigger",
+}
+
+// Rule turns matching alerts into RecoveryTriggers.
+type Rule struct {
+	// Prefix of the RecoveryTrigger names
+	Name string `json:"name"`
+	// Labels an alert must carry
+	Matchers         map[string]string `json:"matchers"`
+	FailureType      string            `json:"failureType"`
+	WorkflowTemplate string            `json:"workflowTemplate"`
+	// Namespace of the RecoveryTrigger; defaults to the alert's namespace,
+	// then to the receiver's default namespace
+	Namespace    string                      `json:"namespace,omitempty"`
+	TargetLabels *detectv1.AlertTargetLabels `json:"targetLabels,omitempty"`
+	// Log the RecoveryTriggers the rule would create and delete instead
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Config is the receiver's rule file.
+type Config struct {
+	// The first matching rule applies to an alert
+	Rules []Rule `json:"rules"`
+}
+
+// LoadConfig reads and checks a rule file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the rules.
+func (c *Config) Validate() error {
+	seen := map[string]bool{}
+	for i, r := range c.Rules {
+		// The name is followed by a 17 character suffix in trigger names
+		if errs := validation.IsDNS1123Label(r.Name); len(errs) > 0 || len(r.Name) > 46 {
+			return fmt.Errorf("rules[%d]: name %q must be a DNS label of at most 46 characters", i, r.Name)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("rules[%d]: name %q is used twice", i, r.Name)
+		}
+		seen[r.Name] = true
+		if len(r.Matchers) == 0 {
+			return fmt.Errorf("rules[%d]: matchers are required", i)
+		}
+		if r.FailureType == "" || r.WorkflowTemplate == "" {
+			return fmt.Errorf("rules[%d]: failureType and workflowTemplate are required", i)
+		}
+	}
+	return nil
+}
+
+// Receiver handles Alertmanager notifications. Alerts are recorded in
+// Store; alerts matching a rule create a RecoveryTrigger when they fire and
+// delete it when they resolve before its workflow was submitted.
+type Receiver struct {
+	Client client.Client
+	Store  *Store
+	Rules  []Rule
+	// Namespace of RecoveryTriggers whose rule and alert name none
+	DefaultNamespace string
+	// DryRun applies every rule as if it were a dry-run rule
+	DryRun bool
+	// TokenFile holds the bearer token notifications must carry. It is read
+	// on every request, so a rotated Secret applies without a restart.
+	// Empty accepts any notification, for servers that authenticate
+	// Alertmanager by TLS client certificate.
+	TokenFile string
+}
+
+// +kubebuilder:rbac:groups=recovery.workflow-recovery.io,resources=recoverytriggers,verbs=get;create;delete
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rc.TokenFile != "" {
+		ok, err := rc.authorized(req)
+		if err != nil {
+			logf.FromContext(req.Context()).WithName("alertmanager").Error(err, "unable to read token file")
+			http.Error(w, "unable to authenticate", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	var msg Message
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxMessageBytes)).Decode(&msg); err != nil {
+		http.Error(w, fmt.Sprintf("decoding notification: %v", err), http.StatusBadRequest)
+		return
+	}
+	if msg.Version != "" && msg.Version != "4" {
+		http.Error(w, fmt.Sprintf("unsupported notification version %q", msg.Version), http.StatusBadRequest)
+		return
+	}
+
+	if err := rc.Handle(req.Context(), &msg); err != nil {
+		// Alertmanager retries the notification; the failed transitions
+		// were reverted so they are applied again
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized reports whether req carries the bearer token of TokenFile.
+func (rc *Receiver) authorized(req *http.Request) (bool, error) {
+	data, err := os.ReadFile(rc.TokenFile)
+	if err != nil {
+		return false, err
+	}
+	want := strings.TrimSpace(string(data))
+	if want == "" {
+		return false, fmt.Errorf("token file %s is empty", rc.TokenFile)
+	}
+	got, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1, nil
+}
+
+// Handle records the alerts of msg and applies the rules to their transitions.
+func (rc *Receiver) Handle(ctx context.Context, msg *Message) error {
+	logger := logf.FromContext(ctx).WithName("alertmanager")
+	var errs []error
+	for _, t := range rc.Store.Apply(msg.Alerts) {
+		rule := rc.match(&t.Alert)
+		if rule == nil {
+			continue
+		}
+		var err error
+		if t.Resolved {
+			err = rc.cancel(ctx, rule, &t.Alert)
+		} else {
+			err = rc.trigger(ctx, rule, &t.Alert)
+		}
+		if err != nil {
+			logger.Error(err, "failed applying alert rule", "rule", rule.Name, "alert", t.Alert.Name())
+			rc.Store.Revert(t)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (rc *Receiver) match(a *Alert) *Rule {
+	for i := range rc.Rules {
+		if Matches(a.Labels, rc.Rules[i].Matchers) {
+			return &rc.Rules[i]
+		}
+	}
+	return nil
+}
+
+// trigger creates the RecoveryTrigger of a firing alert. The name is
+// derived from the alert and its start time, so a repeated notification
+// cannot create a second trigger.
+func (rc *Receiver) trigger(ctx context.Context, rule *Rule, a *Alert) error {
+	u := rc.triggerObject(rule, a)
+	u.SetLabels(map[string]string{LabelRule: rule.Name, LabelFingerprint: a.Key()})
+	spec := map[string]interface{}{
+		"failureType":      rule.FailureType,
+		"workflowTemplate": rule.WorkflowTemplate,
+	}
+	if target, ok := Target(a, rule.TargetLabels); ok {
+		spec["targetObjects"] = []interface{}{
+			map[string]interface{}{"kind": target.Kind, "name": target.Name},
+		}
+	}
+	if err := unstructured.SetNestedMap(u.Object, spec, "spec"); err != nil {
+		return err
+	}
+	if rc.DryRun || rule.DryRun {
+		logf.FromContext(ctx).WithName("alertmanager").Info("Dry run: not creating RecoveryTrigger",
+			"rule", rule.Name, "alert", a.Name(), "namespace", u.GetNamespace(), "name", u.GetName(), "spec", spec)
+		return nil
+	}
+	if err := rc.Client.Create(ctx, u); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating RecoveryTrigger %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+	}
+	return nil
+}
+
+// cancel deletes the RecoveryTrigger of a resolved alert unless its
+// workflow was already submitted.
+func (rc *Receiver) cancel(ctx context.Context, rule *Rule, a *Alert) error {
+	u := rc.triggerObject(rule, a)
+	if rc.DryRun || rule.DryRun {
+		logf.FromContext(ctx).WithName("alertmanager").Info("Dry run: not deleting RecoveryTrigger",
+			"rule", rule.Name, "alert", a.Name(), "namespace", u.GetNamespace(), "name", u.GetName())
+		return nil
+	}
+	if err := rc.Client.Get(ctx, client.ObjectKeyFromObject(u), u); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if name, _, _ := unstructured.NestedString(u.Object, "status", "workflowName"); name != "" {
+		return nil
+	}
+	// The workflow may be submitted since the trigger was read; deleting the
+	// trigger then would garbage collect the running workflow
+	err := rc.Client.Delete(ctx, u, client.Preconditions{ResourceVersion: ptr.To(u.GetResourceVersion())})
+	if apierrors.IsConflict(err) {
+		logf.FromContext(ctx).WithName("alertmanager").Info("RecoveryTrigger changed since it was read, keeping it",
+			"rule", rule.Name, "alert", a.Name(), "namespace", u.GetNamespace(), "name", u.GetName())
+		return nil
+	}
+	return client.IgnoreNotFound(err)
+}
+
+func (rc *Receiver) triggerObject(rule *Rule, a *Alert) *unstructured.Unstructured {
+	ns := rule.Namespace
+	if target, ok := Target(a, rule.TargetLabels); ns == "" && ok {
+		ns = target.Namespace
+		if target.Kind == "Namespace" {
+			ns = target.Name
+		}
+	}
+	if ns == "" {
+		ns = rc.DefaultNamespace
+	}
+	h := sha256.Sum256([]byte(a.Key() + "/" + a.StartsAt.UTC().Format(time.RFC3339)))
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(RecoveryTriggerGVK)
+	u.SetNamespace(ns)
+	u.SetName(rule.Name + "-" + hex.EncodeToString(h[:])[:16])
+	return u
+}
+
+// Server runs the receiver as a manager Runnable.
+type Server struct {
+	Addr    string
+	Handler http.Handler
+	// TLSConfig serves HTTPS when set. Requiring verified client
+	// certificates in it authenticates Alertmanager.
+	TLSConfig *tls.Config
+}
+
+// Start serves until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, s.Handler)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("alertmanager receiver listener: %w", err)
+	}
+	if s.TLSConfig != nil {
+		ln = tls.NewListener(ln, s.TLSConfig)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection reports true: the alerts are kept in memory for the
+// FaultDetection controller, which only runs on the leader.
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package anomaly implements statistical anomaly detectors that run inside
+// the controller. Every detector scores a new sample against a baseline held
+// in State and then folds the sample into that baseline. State is plain JSON
+// so the caller can persist it between reconciles and across restarts.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Algorithm names a detector.
+type Algorithm string
+
+const (
+	// EWMA scores samples by z-score against an exponentially weighted mean and variance.
+	EWMA Algorithm = "ewma"
+	// Seasonal scores samples against the mean and variance seen in the same hour of the week.
+	Seasonal Algorithm = "seasonal"
+	// MAD scores samples by modified z-score against the median of a sliding window.
+	MAD Algorithm = "mad"
+	// Forecast fits a linear trend and fires when Limit will be crossed within Horizon.
+	Forecast Algorithm = "forecast"
+)
+
+// Defaults applied by Config.withDefaults.
+const (
+	DefaultSensitivity = 3.0
+	DefaultAlpha       = 0.3
+	DefaultMinSamples  = 10
+	DefaultWindowSize  = 60
+	DefaultHorizon     = 4 * time.Hour
+)
+
+// Config parameterises a detector.
+type Config struct {
+	Algorithm Algorithm
+	// Score above which a sample is anomalous (EWMA, Seasonal, MAD)
+	Sensitivity float64
+	// Smoothing factor for EWMA, in (0, 1]
+	Alpha float64
+	// Samples needed before the detector may fire
+	MinSamples int
+	// Number of recent samples kept for MAD and Forecast
+	WindowSize int
+	// Value the forecast must not reach, and how far ahead to look
+	Limit   float64
+	Horizon time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Sensitivity <= 0 {
+		c.Sensitivity = DefaultSensitivity
+	}
+	if c.Alpha <= 0 || c.Alpha > 1 {
+		c.Alpha = DefaultAlpha
+	}
+	if c.MinSamples <= 0 {
+		c.MinSamples = DefaultMinSamples
+	}
+	if c.WindowSize <= 0 {
+		c.WindowSize = DefaultWindowSize
+	}
+	if c.MinSamples > c.WindowSize && (c.Algorithm == MAD || c.Algorithm == Forecast) {
+		c.WindowSize = c.MinSamples
+	}
+	if c.Horizon <= 0 {
+		c.Horizon = DefaultHorizon
+	}
+	return c
+}
+
+// Moments is a running mean and variance.
+type Moments struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Var   float64 `json:"var"`
+}
+
+// Sample is a timestamped value kept in the sliding window.
+type Sample struct {
+	// Unix seconds
+	Time  int64   `json:"t"`
+	Value float64 `json:"v"`
+}
+
+// State is the persisted baseline of one detector for one target and metric.
+type State struct {
+	Algorithm Algorithm `json:"algorithm"`
+	// EWMA moments
+	EWMA *Moments `json:"ewma,omitempty"`
+	// Seasonal moments keyed by hour of week (0 = Sunday 00:00 UTC)
+	Seasonal map[int]*Moments `json:"seasonal,omitempty"`
+	// Recent samples for MAD and Forecast
+	Window []Sample `json:"window,omitempty"`
+	// Unix seconds of the last sample folded in
+	Last int64 `json:"last,omitempty"`
+}
+
+// Verdict is the outcome of scoring one sample.
+type Verdict struct {
+	Anomalous bool
+	Score     float64
+	Message   string
+	// Warming is true while the baseline has too few samples to judge
+	Warming bool
+}
+
+// Detect scores value observed at now against st and then updates st.
+// A nil or mismatched state is reset, so changing the algorithm of a
+// template starts a fresh baseline.
+func Detect(cfg Config, st *State, now time.Time, value float64) (Verdict, error) {
+	cfg = cfg.withDefaults()
+	if st.Algorithm != cfg.Algorithm {
+		*st = State{Algorithm: cfg.Algorithm}
+	}
+
+	var v Verdict
+	switch cfg.Algorithm {
+	case EWMA:
+		v = detectEWMA(cfg, st, value)
+	case Seasonal:
+		v = detectSeasonal(cfg, st, now, value)
+	case MAD:
+		v = detectMAD(cfg, st, now, value)
+	case Forecast:
+		v = detectForecast(cfg, st, now, value)
+	default:
+		return Verdict{}, fmt.Errorf("unknown algorithm %q", cfg.Algorithm)
+	}
+	st.Last = now.Unix()
+	return v, nil
+}
+
+func detectEWMA(cfg Config, st *State, value float64) Verdict {
+	if st.EWMA == nil {
+		st.EWMA = &Moments{}
+	}
+	m := st.EWMA
+
+	v := scoreMoments(cfg, m, value, "ewma z-score")
+
+	if m.Count == 0 {
+		m.Mean = value
+	} else {
+		diff := value - m.Mean
+		incr := cfg.Alpha * diff
+		m.Mean += incr
+		m.Var = (1 - cfg.Alpha) * (m.Var + diff*incr)
+	}
+	m.Count++
+	return v
+}
+
+func detectSeasonal(cfg Config, st *State, now time.Time, value float64) Verdict {
+	if st.Seasonal == nil {
+		st.Seasonal = map[int]*Moments{}
+	}
+	utc := now.UTC()
+	bucket := int(utc.Weekday())*24 + utc.Hour()
+	m, ok := st.Seasonal[bucket]
+	if !ok {
+		m = &Moments{}
+		st.Seasonal[bucket] = m
+	}
+
+	v := scoreMoments(cfg, m, value, fmt.Sprintf("seasonal z-score (hour-of-week %d)", bucket))
+
+	// Welford's online update
+	m.Count++
+	delta := value - m.Mean
+	m.Mean += delta / float64(m.Count)
+	m.Var += delta * (value - m.Mean)
+	return v
+}
+
+// scoreMoments computes a z-score against m. For Seasonal, m.Var holds the
+// sum of squared differences, so it is normalised by Count first.
+func scoreMoments(cfg Config, m *Moments, value float64, label string) Verdict {
+	if m.Count < cfg.MinSamples {
+		return Verdict{Warming: true, Message: fmt.Sprintf("baseline warming up (%d/%d samples)", m.Count, cfg.MinSamples)}
+	}
+	variance := m.Var
+	if cfg.Algorithm == Seasonal {
+		variance = m.Var / float64(m.Count)
+	}
+	score := zScore(value, m.Mean, math.Sqrt(variance))
+	return Verdict{
+		Anomalous: math.Abs(score) > cfg.Sensitivity,
+		Score:     score,
+		Message:   fmt.Sprintf("%s %.2f (mean %.4g)", label, score, m.Mean),
+	}
+}
+
+func detectMAD(cfg Config, st *State, now time.Time, value float64) Verdict {
+	defer appendWindow(cfg, st, now, value)
+
+	if len(st.Window) < cfg.MinSamples {
+		return Verdict{Warming: true, Message: fmt.Sprintf("baseline warming up (%d/%d samples)", len(st.Window), cfg.MinSamples)}
+	}
+
+	values := make([]float64, len(st.Window))
+	for i, s := range st.Window {
+		values[i] = s.Value
+	}
+	med := median(values)
+	for i := range values {
+		values[i] = math.Abs(values[i] - med)
+	}
+	mad := median(values)
+
+	// 0.6745 makes the MAD a consistent estimator of the standard deviation
+	score := zScore(value, med, mad/0.6745)
+	return Verdict{
+		Anomalous: math.Abs(score) > cfg.Sensitivity,
+		Score:     score,
+		Message:   fmt.Sprintf("modified z-score %.2f (median %.4g)", score, med),
+	}
+}
+
+func detectForecast(cfg Config, st *State, now time.Time, value float64) Verdict {
+	appendWindow(cfg, st, now, value)
+
+	if len(st.Window) < cfg.MinSamples {
+		return Verdict{Warming: true, Message: fmt.Sprintf("trend warming up (%d/%d samples)", len(st.Window), cfg.MinSamples)}
+	}
+
+	slope, intercept := linearFit(st.Window)
+	current := slope*float64(now.Unix()) + intercept
+	first := slope*float64(st.Window[0].Time) + intercept
+	// A rising trend at or over the limit has reached it, as has a falling
+	// one that crossed it within the window. A falling trend that stayed
+	// below the limit, such as a draining disk, is moving away from it.
+	if slope > 0 && current >= cfg.Limit || slope < 0 && current <= cfg.Limit && first > cfg.Limit {
+		return Verdict{
+			Anomalous: true,
+			Message:   fmt.Sprintf("trend %.4g/s has reached limit %.4g (at %.4g)", slope, cfg.Limit, current),
+		}
+	}
+	if slope == 0 || (cfg.Limit-current)/slope < 0 {
+		// Flat, or moving away from the limit
+		return Verdict{Message: fmt.Sprintf("trend %.4g/s does not approach limit %.4g", slope, cfg.Limit)}
+	}
+
+	eta := time.Duration((cfg.Limit - current) / slope * float64(time.Second))
+	return Verdict{
+		Anomalous: eta <= cfg.Horizon,
+		Score:     eta.Seconds(),
+		Message:   fmt.Sprintf("forecast to reach %.4g in %s", cfg.Limit, eta.Round(time.Minute)),
+	}
+}
+
+func appendWindow(cfg Config, st *State, now time.Time, value float64) {
+	st.Window = append(st.Window, Sample{Time: now.Unix(), Value: value})
+	if over := len(st.Window) - cfg.WindowSize; over > 0 {
+		st.Window = append([]Sample(nil), st.Window[over:]...)
+	}
+}
+
+// zScore returns how many deviations value is from mean. A flat baseline
+// makes any change infinitely surprising.
+func zScore(value, mean, stddev float64) float64 {
+	if stddev == 0 {
+		switch {
+		case value > mean:
+			return math.Inf(1)
+		case value < mean:
+			return math.Inf(-1)
+		default:
+			return 0
+		}
+	}
+	return (value - mean) / stddev
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// linearFit returns the least-squares slope (per second) and intercept.
+func linearFit(window []Sample) (float64, float64) {
+	n := float64(len(window))
+	// Centre timestamps to keep the sums well conditioned
+	t0 := float64(window[0].Time)
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range window {
+		x := float64(s.Time) - t0
+		sumX += x
+		sumY += s.Value
+		sumXY += x * s.Value
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, sumY / n
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept - slope*t0
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package anomaly
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC) // a Monday
+
+// feed runs values through the detector one minute apart and returns the last verdict.
+func feed(t *testing.T, cfg Config, st *State, start time.Time, values ...float64) Verdict {
+	t.Helper()
+	var v Verdict
+	for i, value := range values {
+		var err error
+		v, err = Detect(cfg, st, start.Add(time.Duration(i)*time.Minute), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return v
+}
+
+func noisy(n int, base float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = base + float64(i%5) - 2
+	}
+	return out
+}
+
+func TestEWMA(t *testing.T) {
+	cfg := Config{Algorithm: EWMA}
+	st := &State{}
+
+	if v := feed(t, cfg, st, t0, 50); !v.Warming {
+		t.Error("first sample should warm up the baseline")
+	}
+	if v := feed(t, cfg, st, t0, noisy(30, 50)...); v.Anomalous {
+		t.Errorf("steady series flagged: %+v", v)
+	}
+	if v := feed(t, cfg, st, t0.Add(time.Hour), 90); !v.Anomalous {
+		t.Errorf("spike not flagged: %+v", v)
+	}
+}
+
+func TestSeasonalUsesHourOfWeek(t *testing.T) {
+	cfg := Config{Algorithm: Seasonal, MinSamples: 3}
+	st := &State{}
+
+	// Mondays at 10:00 run hot, Mondays at 03:00 idle, for four weeks
+	for week := 0; week < 4; week++ {
+		monday := t0.AddDate(0, 0, 7*week)
+		feed(t, cfg, st, monday, 80+float64(week%2))
+		feed(t, cfg, st, monday.Add(-7*time.Hour), 10+float64(week%2))
+	}
+
+	next := t0.AddDate(0, 0, 28)
+	if v := feed(t, cfg, st, next, 81); v.Anomalous {
+		t.Errorf("usual Monday 10:00 load flagged: %+v", v)
+	}
+	if v := feed(t, cfg, st, next.Add(-7*time.Hour), 80); !v.Anomalous {
+		t.Errorf("Monday 03:00 load spike not flagged: %+v", v)
+	}
+}
+
+func TestMAD(t *testing.T) {
+	cfg := Config{Algorithm: MAD, WindowSize: 20}
+	st := &State{}
+
+	values := noisy(25, 100)
+	values[12] = 1000 // an outlier in the history must not inflate the baseline
+	if v := feed(t, cfg, st, t0, values...); v.Anomalous {
+		t.Errorf("normal sample flagged: %+v", v)
+	}
+	if len(st.Window) != 20 {
+		t.Errorf("window = %d samples, want 20", len(st.Window))
+	}
+	if v := feed(t, cfg, st, t0.Add(time.Hour), 115); !v.Anomalous {
+		t.Errorf("outlier not flagged: %+v", v)
+	}
+}
+
+func TestForecastDiskFull(t *testing.T) {
+	cfg := Config{Algorithm: Forecast, Limit: 100, Horizon: 4 * time.Hour}
+
+	// Filling 0.1%/min: from 70% reaches 100% in ~5h, not yet within 4h
+	st := &State{}
+	var filling []float64
+	for i := 0; i < 10; i++ {
+		filling = append(filling, 70+0.1*float64(i))
+	}
+	if v := feed(t, cfg, st, t0, filling...); v.Anomalous {
+		t.Errorf("5h away flagged: %+v", v)
+	}
+
+	// Filling 0.5%/min from 70%: full in about an hour
+	st = &State{}
+	filling = filling[:0]
+	for i := 0; i < 10; i++ {
+		filling = append(filling, 70+0.5*float64(i))
+	}
+	v := feed(t, cfg, st, t0, filling...)
+	if !v.Anomalous {
+		t.Fatalf("imminent disk full not flagged: %+v", v)
+	}
+	if eta := time.Duration(v.Score * float64(time.Second)); math.Abs(eta.Minutes()-51) > 1 {
+		t.Errorf("eta = %s, want ~51m", eta)
+	}
+
+	// A disk already over the limit and still filling is due now
+	st = &State{}
+	v = feed(t, cfg, st, t0, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110)
+	if !v.Anomalous || v.Score != 0 {
+		t.Errorf("disk over the limit = %+v, want anomalous with eta 0", v)
+	}
+
+	// As is a falling series that crossed a lower limit
+	low := Config{Algorithm: Forecast, Limit: 10, Horizon: 4 * time.Hour}
+	st = &State{}
+	if v := feed(t, low, st, t0, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5); !v.Anomalous || v.Score != 0 {
+		t.Errorf("crossed lower limit = %+v, want anomalous with eta 0", v)
+	}
+
+	// Draining disk never reaches the limit
+	st = &State{}
+	if v := feed(t, cfg, st, t0, 90, 89, 88, 87, 86, 85, 84, 83, 82, 81); v.Anomalous {
+		t.Errorf("draining disk flagged: %+v", v)
+	}
+}
+
+func TestStateRoundTripsThroughJSON(t *testing.T) {
+	cfg := Config{Algorithm: Seasonal, MinSamples: 1}
+	st := &State{}
+	feed(t, cfg, st, t0, 1, 2, 3)
+
+	raw, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored State
+	if err := json.Unmarshal(raw, &restored); err != nil {
+		t.Fatal(err)
+	}
+	bucket := int(t0.Weekday())*24 + t0.Hour()
+	if restored.Seasonal[bucket] == nil || restored.Seasonal[bucket].Count != 3 {
+		t.Errorf("restored state = %+v", restored.Seasonal)
+	}
+
+	// Switching algorithm starts a fresh baseline
+	if _, err := Detect(Config{Algorithm: EWMA}, &restored, t0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Seasonal != nil || restored.Algorithm != EWMA {
+		t.Errorf("state not reset: %+v", restored)
+	}
+}
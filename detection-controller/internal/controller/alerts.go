@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/alertmanager"
+	"github.com/phuongbac/detection-controller/internal/detector"
+)
+
+// alertsMetric names the number of firing alerts in query results.
+const alertsMetric = "alerts"
+
+// alertsDetector reports the firing Alertmanager alerts of the target
+// (Option G).
+type alertsDetector struct {
+	store *alertmanager.Store
+}
+
+func (d *alertsDetector) Name() string        { return alertsMetric }
+func (d *alertsDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *alertsDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.Alerts != nil
+}
+
+func (d *alertsDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	if len(spec.Alerts.Matchers) == 0 {
+		return errors.New("alerts need at least one matcher")
+	}
+	return nil
+}
+
+func (d *alertsDetector) Evaluate(_ context.Context, in detector.Input) (*detector.Outcome, error) {
+	if d.store == nil {
+		return nil, errors.New("the Alertmanager receiver is not enabled")
+	}
+	spec := in.Template.Spec.Alerts
+	out := &detector.Outcome{}
+	for _, a := range d.store.Firing() {
+		if !alertmanager.Matches(a.Labels, spec.Matchers) {
+			continue
+		}
+		target, _ := alertmanager.Target(&a, spec.TargetLabels)
+		if !alertTargetMatches(in.FaultDetection.Spec.Target, target) {
+			continue
+		}
+		out.Targets.Alerts = append(out.Targets.Alerts, detectv1.AlertResult{
+			Alert:       a.Name(),
+			Fingerprint: a.Key(),
+			Target:      target,
+			StartsAt:    metav1.NewTime(a.StartsAt),
+			Summary:     a.Summary(),
+		})
+	}
+	out.Results = []detectv1.Result{{Metric: alertsMetric, Value: strconv.Itoa(len(out.Targets.Alerts))}}
+	if len(out.Targets.Alerts) > 0 {
+		first := out.Targets.Alerts[0]
+		out.Anomalous = true
+		out.Reason = fmt.Sprintf("alert %s firing for %s %s since %s", first.Alert,
+			first.Target.Kind, objectKey(first.Target), first.StartsAt.UTC().Format("15:04:05"))
+	}
+	return out, nil
+}
+
+// alertTargetMatches reports whether an alert's target is the
+// FaultDetection's target. Namespace targets match everything inside them;
+// FaultDetections without a target match every alert.
+func alertTargetMatches(fdTarget *detectv1.ObjectRef, t detectv1.ObjectRef) bool {
+	if fdTarget == nil || fdTarget.Name == "" {
+		return true
+	}
+	if fdTarget.Kind == "Namespace" {
+		return t.Namespace == fdTarget.Name || (t.Kind == "Namespace" && t.Name == fdTarget.Name)
+	}
+	return t.Kind == fdTarget.Kind && t.Name == fdTarget.Name &&
+		(fdTarget.Namespace == "" || t.Namespace == fdTarget.Namespace)
+}
+
+// faultDetectionsForAlert maps an alert transition, delivered as an object
+// carrying the alert's labels, to the FaultDetections it concerns.
+func (r *FaultDetectionReconciler) faultDetectionsForAlert(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	alert := alertmanager.Alert{Labels: obj.GetLabels()}
+
+	var templates detectv1.DetectionTemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		logger.Error(err, "unable to list DetectionTemplates for alert")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, tmpl := range templates.Items {
+		spec := tmpl.Spec.Alerts
+		if spec == nil || !alertmanager.Matches(alert.Labels, spec.Matchers) {
+			continue
+		}
+		target, _ := alertmanager.Target(&alert, spec.TargetLabels)
+		var list detectv1.FaultDetectionList
+		if err := r.List(ctx, &list, client.MatchingFields{templateRefIndex: tmpl.Name}); err != nil {
+			logger.Error(err, "unable to list FaultDetections for template", "template", tmpl.Name)
+			continue
+		}
+		for _, fd := range list.Items {
+			if alertTargetMatches(fd.Spec.Target, target) {
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&fd)})
+			}
+		}
+	}
+	return reqs
+}
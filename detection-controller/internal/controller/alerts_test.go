@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/alertmanager"
+	"github.com/phuongbac/detection-controller/internal/detector"
+)
+
+func firingAlert(name string, labels map[string]string) alertmanager.Alert {
+	labels["alertname"] = name
+	return alertmanager.Alert{
+		Status:      alertmanager.StatusFiring,
+		Labels:      labels,
+		Annotations: map[string]string{"summary": name + " firing"},
+		StartsAt:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAlertsDetector(t *testing.T) {
+	store := alertmanager.NewStore()
+	store.Apply([]alertmanager.Alert{
+		firingAlert("KubePodCrashLooping", map[string]string{"namespace": "shop", "pod": "checkout-0"}),
+		firingAlert("KubePodCrashLooping", map[string]string{"namespace": "shop", "pod": "checkout-1"}),
+		firingAlert("KubePodCrashLooping", map[string]string{"namespace": "billing", "pod": "invoice-0"}),
+		firingAlert("KubeNodeNotReady", map[string]string{"node": "worker-1"}),
+	})
+	d := &alertsDetector{store: store}
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Alerts: &detectv1.AlertSpec{Matchers: map[string]string{"alertname": "KubePodCrashLooping"}},
+	}}
+	evaluate := func(target *detectv1.ObjectRef) *detector.Outcome {
+		t.Helper()
+		fd := &detectv1.FaultDetection{Spec: detectv1.FaultDetectionSpec{Target: target}}
+		out, err := d.Evaluate(context.Background(), detector.Input{FaultDetection: fd, Template: tmpl})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := evaluate(nil); len(out.Targets.Alerts) != 3 || out.Results[0].Value != "3" {
+		t.Errorf("alerts = %+v, want every crash looping pod", out.Targets.Alerts)
+	}
+	out := evaluate(&detectv1.ObjectRef{Kind: "Namespace", Name: "shop"})
+	if len(out.Targets.Alerts) != 2 || !out.Anomalous {
+		t.Errorf("alerts = %+v, want the shop pods", out.Targets.Alerts)
+	}
+	if !strings.HasPrefix(out.Reason, "alert KubePodCrashLooping firing for Pod shop/checkout-") {
+		t.Errorf("reason = %q", out.Reason)
+	}
+	out = evaluate(&detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-1"})
+	if len(out.Targets.Alerts) != 1 || out.Targets.Alerts[0].Summary != "KubePodCrashLooping firing" {
+		t.Errorf("alerts = %+v, want checkout-1", out.Targets.Alerts)
+	}
+	if out := evaluate(&detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-2"}); out.Anomalous {
+		t.Errorf("outcome = %+v, want no alert for checkout-2", out)
+	}
+
+	if _, err := (&alertsDetector{}).Evaluate(context.Background(), detector.Input{Template: tmpl}); err == nil {
+		t.Error("expected an error without the receiver")
+	}
+}
+
+func TestFaultDetectionsForAlert(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := detectv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &detectv1.DetectionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-not-ready"},
+		Spec: detectv1.DetectionTemplateSpec{
+			Alerts: &detectv1.AlertSpec{Matchers: map[string]string{"alertname": "KubeNodeNotReady"}},
+		},
+	}
+	fd := func(name string, target *detectv1.ObjectRef) *detectv1.FaultDetection {
+		return &detectv1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: name},
+			Spec:       detectv1.FaultDetectionSpec{TemplateRef: tmpl.Name, Target: target},
+		}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			tmpl,
+			fd("all-nodes", nil),
+			fd("worker-1", &detectv1.ObjectRef{Kind: "Node", Name: "worker-1"}),
+			fd("worker-2", &detectv1.ObjectRef{Kind: "Node", Name: "worker-2"}),
+		).
+		WithIndex(&detectv1.FaultDetection{}, templateRefIndex, func(obj client.Object) []string {
+			return []string{obj.(*detectv1.FaultDetection).Spec.TemplateRef}
+		}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{"alertname": "KubeNodeNotReady", "node": "worker-1"},
+	}}
+	got := map[string]bool{}
+	for _, req := range r.faultDetectionsForAlert(context.Background(), obj) {
+		got[req.Name] = true
+	}
+	if len(got) != 2 || !got["all-nodes"] || !got["worker-1"] {
+		t.Errorf("requests = %v, want all-nodes and worker-1", got)
+	}
+}
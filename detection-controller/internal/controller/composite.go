@@ -0,0 +1,398 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+// Sub-detector types reported in status.
+const (
+	subDetectorField       = "field"
+	subDetectorPrometheus  = "prometheus"
+	subDetectorStatistical = "statistical"
+	subDetectorML          = "ml"
+	subDetectorProbe       = "probe"
+)
+
+// fieldSelector matches a path segment such as conditions[Ready].
+var fieldSelector = regexp.MustCompile(`^([^\[\]]+)\[([^\[\]]+)\]$`)
+
+// evaluateCompositeSources runs the field, Prometheus and probe
+// sub-detectors. Statistical and ML sub-detectors depend on the other steps
+// of the evaluation and are filled in by combineComposite. Prometheus values
+// and probe totals are also returned as results, named after their
+// sub-detector, so statistical detectors and the ML model see them.
+func (r *FaultDetectionReconciler) evaluateCompositeSources(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	tmpl *detectv1.DetectionTemplate,
+	series map[string][]float64,
+) ([]detectv1.SubDetectorResult, []detectv1.Result) {
+	var (
+		subs       []detectv1.SubDetectorResult
+		results    []detectv1.Result
+		promClient prometheusQuerier
+		promErr    error
+		promReady  bool
+	)
+	for _, d := range tmpl.Spec.Composite.Detectors {
+		sub := detectv1.SubDetectorResult{Name: d.Name}
+		switch {
+		case d.Field != nil:
+			sub.Type = subDetectorField
+			r.checkField(ctx, fd, tmpl, d.Field, &sub)
+		case d.Prometheus != nil:
+			sub.Type = subDetectorPrometheus
+			if !promReady {
+				promReady = true
+				if tmpl.Spec.PrometheusAPI == "" {
+					promErr = errors.New("template has no prometheusAPI")
+				} else {
+					promClient, promErr = newPrometheusClient(ctx, r.apiReader(), r.SecretNamespace, tmpl)
+				}
+			}
+			if promErr != nil {
+				dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourcePrometheus).Inc()
+				sub.Message = promErr.Error()
+				results = append(results, detectv1.Result{Metric: d.Name, Error: promErr.Error()})
+				break
+			}
+			if res := checkPrometheus(ctx, promClient, tmpl, d, &sub, series); res != nil {
+				results = append(results, *res)
+			}
+		case d.Probe != nil:
+			sub.Type = subDetectorProbe
+			results = append(results, r.checkProbe(ctx, fd, d, &sub)...)
+		case d.Statistical != nil:
+			sub.Type = subDetectorStatistical
+		case d.ML != nil:
+			sub.Type = subDetectorML
+		default:
+			sub.Message = "no detector configured"
+		}
+		subs = append(subs, sub)
+	}
+	return subs, results
+}
+
+// checkField reads the target object and compares one of its fields.
+func (r *FaultDetectionReconciler) checkField(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	tmpl *detectv1.DetectionTemplate,
+	check *detectv1.FieldCheck,
+	sub *detectv1.SubDetectorResult,
+) {
+	if fd.Spec.Target == nil || fd.Spec.Target.Name == "" {
+		sub.Message = "FaultDetection has no target"
+		return
+	}
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(check.APIVersion)
+	u.SetKind(check.Kind)
+	key := client.ObjectKey{Namespace: fd.Spec.Target.Namespace, Name: fd.Spec.Target.Name}
+	if err := r.Get(ctx, key, u); err != nil {
+		if !apierrors.IsNotFound(err) {
+			dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourceKubernetes).Inc()
+		}
+		// An unreachable target counts as a failed check, as in Option B
+		sub.Condition = true
+		sub.Message = "Target resource not found or unreachable"
+		return
+	}
+	actual, found := readField(u.Object, check.FieldPath)
+	if !found {
+		sub.Condition = true
+		sub.Message = fmt.Sprintf("Field %s not found in resource", check.FieldPath)
+		return
+	}
+	sub.Value = actual
+	if actual != check.Expected {
+		sub.Condition = true
+		sub.Message = fmt.Sprintf("Expected %s=%s but got %s", check.FieldPath, check.Expected, actual)
+	}
+}
+
+// checkPrometheus evaluates a Prometheus sub-detector and returns its value
+// as a query result, or nil when there is no value to report.
+func checkPrometheus(
+	ctx context.Context,
+	c prometheusQuerier,
+	tmpl *detectv1.DetectionTemplate,
+	d detectv1.SubDetectorSpec,
+	sub *detectv1.SubDetectorResult,
+	series map[string][]float64,
+) *detectv1.Result {
+	check := d.Prometheus
+	q := detectv1.QuerySpec{Metric: d.Name, Query: check.Query, Range: check.Range}
+
+	queryCtx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout(tmpl))
+	value, points, err := evaluateQuery(queryCtx, c, q)
+	cancel()
+	if errors.Is(err, promclient.ErrNoData) {
+		sub.Condition = check.FireOnNoData
+		sub.Message = "no data"
+		return nil
+	}
+	if err != nil {
+		dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourcePrometheus).Inc()
+		sub.Message = err.Error()
+		return &detectv1.Result{Metric: d.Name, Error: err.Error()}
+	}
+
+	sub.Value = fmt.Sprintf("%f", value)
+	if len(points) > 0 {
+		series[d.Name] = pointValues(points)
+	}
+	if check.Threshold != "" {
+		threshold, perr := strconv.ParseFloat(check.Threshold, 64)
+		if perr != nil {
+			sub.Message = fmt.Sprintf("invalid threshold %q", check.Threshold)
+		} else {
+			op := check.Operator
+			if op == "" {
+				op = ">"
+			}
+			sub.Condition, perr = compare(value, op, threshold)
+			if perr != nil {
+				sub.Message = perr.Error()
+			} else if sub.Condition {
+				sub.Message = fmt.Sprintf("value %f %s %s", value, op, check.Threshold)
+			}
+		}
+	}
+	return &detectv1.Result{Metric: d.Name, Value: sub.Value}
+}
+
+// checkProbe runs a probe sub-detector, which fires when any endpoint
+// fails. Like spec.probes it returns the number of failed endpoints and the
+// slowest answer as results.
+func (r *FaultDetectionReconciler) checkProbe(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	d detectv1.SubDetectorSpec,
+	sub *detectv1.SubDetectorResult,
+) []detectv1.Result {
+	p := *d.Probe
+	p.Name = d.Name
+	probeResults, results, err := r.evaluateProbes(ctx, fd, []detectv1.ProbeSpec{p})
+	if err != nil {
+		sub.Message = err.Error()
+		return []detectv1.Result{{Metric: d.Name, Error: err.Error()}}
+	}
+	failed := 0
+	for _, res := range probeResults {
+		if !res.Success {
+			failed++
+		}
+	}
+	sub.Value = strconv.Itoa(failed)
+	if reason := probesReason(probeResults); reason != "" {
+		sub.Condition = true
+		sub.Message = fmt.Sprintf("%s (%d of %d endpoints failed)", reason, failed, len(probeResults))
+	}
+	return results
+}
+
+// combineComposite fills in the statistical and ML sub-detectors, applies
+// each detector's For duration using the previous evaluation, and returns
+// the composite verdict with a reason naming the detectors that fired. subs
+// must hold one result per detector of spec.
+func combineComposite(
+	spec *detectv1.CompositeSpec,
+	subs []detectv1.SubDetectorResult,
+	statResults []detectv1.StatisticalResult,
+	mlResult *detectv1.MLResult,
+	previous []detectv1.SubDetectorResult,
+	now metav1.Time,
+) (bool, string, error) {
+	if len(subs) != len(spec.Detectors) {
+		return false, "", fmt.Errorf("composite returned %d results for %d detectors", len(subs), len(spec.Detectors))
+	}
+	prev := map[string]detectv1.SubDetectorResult{}
+	for _, p := range previous {
+		prev[p.Name] = p
+	}
+
+	var firing []string
+	for i, d := range spec.Detectors {
+		sub := &subs[i]
+		switch {
+		case d.Statistical != nil:
+			statisticalCondition(d.Statistical, statResults, sub)
+		case d.ML != nil:
+			mlCondition(d.ML, mlResult, sub)
+		}
+
+		if !sub.Condition {
+			continue
+		}
+		sub.Since = &now
+		if p, ok := prev[sub.Name]; ok && p.Condition && p.Since != nil {
+			sub.Since = p.Since
+		}
+		sub.Firing = d.For == nil || now.Sub(sub.Since.Time) >= d.For.Duration
+		if sub.Firing {
+			firing = append(firing, sub.Name)
+		}
+	}
+
+	var fired bool
+	switch spec.Operator {
+	case detectv1.CompositeAnd:
+		fired = len(firing) == len(spec.Detectors)
+	case detectv1.CompositeOr:
+		fired = len(firing) > 0
+	case detectv1.CompositeAtLeast:
+		fired = spec.MinFiring > 0 && len(firing) >= int(spec.MinFiring)
+	}
+	if !fired {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("composite %s: %s firing", spec.Operator, strings.Join(firing, ", ")), nil
+}
+
+func statisticalCondition(check *detectv1.StatisticalCheck, statResults []detectv1.StatisticalResult, sub *detectv1.SubDetectorResult) {
+	found := false
+	for _, sr := range statResults {
+		if sr.Metric != check.Metric {
+			continue
+		}
+		found = true
+		if sr.Anomalous {
+			sub.Condition = true
+			sub.Value = sr.Score
+			sub.Message = fmt.Sprintf("%s %s", sr.Algorithm, sr.Message)
+			return
+		}
+	}
+	if !found {
+		sub.Message = fmt.Sprintf("no statistical result for metric %s", check.Metric)
+	}
+}
+
+func mlCondition(check *detectv1.MLCheck, mlResult *detectv1.MLResult, sub *detectv1.SubDetectorResult) {
+	if mlResult == nil {
+		sub.Message = "no ML model configured or ready"
+		return
+	}
+	if mlResult.Error != "" {
+		sub.Message = mlResult.Error
+		return
+	}
+	sub.Value = mlResult.Score
+	sub.Condition = mlResult.Anomalous
+	if check.Threshold != "" {
+		threshold, err := strconv.ParseFloat(check.Threshold, 64)
+		score, serr := strconv.ParseFloat(mlResult.Score, 64)
+		if err != nil || serr != nil {
+			sub.Condition = false
+			sub.Message = fmt.Sprintf("cannot compare score %q with threshold %q", mlResult.Score, check.Threshold)
+			return
+		}
+		sub.Condition = score >= threshold
+	}
+}
+
+// compare applies op to value and threshold.
+func compare(value float64, op detectv1.ComparisonOperator, threshold float64) (bool, error) {
+	switch op {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", op)
+}
+
+// readField reads a string field in dot notation. A segment of the form
+// name[Type] selects the item of list name whose "type" is Type.
+func readField(obj map[string]interface{}, path string) (string, bool) {
+	var cur interface{} = obj
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		sel := fieldSelector.FindStringSubmatch(part)
+		if sel == nil {
+			if cur, ok = m[part]; !ok {
+				return "", false
+			}
+			continue
+		}
+		items, ok := m[sel[1]].([]interface{})
+		if !ok {
+			return "", false
+		}
+		cur = nil
+		for _, item := range items {
+			if im, ok := item.(map[string]interface{}); ok && im["type"] == sel[2] {
+				cur = im
+				break
+			}
+		}
+		if cur == nil {
+			return "", false
+		}
+	}
+	s, ok := cur.(string)
+	return s, ok
+}
+
+// compositeRequeue returns how long until the next pending detector reaches
+// its For duration, or 0 if none is pending, so it fires on time.
+func compositeRequeue(spec *detectv1.CompositeSpec, subs []detectv1.SubDetectorResult, now time.Time) time.Duration {
+	if len(subs) != len(spec.Detectors) {
+		return 0
+	}
+	var next time.Duration
+	for i, d := range spec.Detectors {
+		sub := subs[i]
+		if d.For == nil || !sub.Condition || sub.Firing || sub.Since == nil {
+			continue
+		}
+		wait := sub.Since.Add(d.For.Duration).Sub(now)
+		if wait > 0 && (next == 0 || wait < next) {
+			next = wait
+		}
+	}
+	return next
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestCombineCompositeForDuration(t *testing.T) {
+	spec := &detectv1.CompositeSpec{
+		Operator: detectv1.CompositeAnd,
+		Detectors: []detectv1.SubDetectorSpec{
+			{Name: "node-not-ready", Field: &detectv1.FieldCheck{}},
+			{Name: "kubelet-absent", For: &metav1.Duration{Duration: 2 * time.Minute}, Prometheus: &detectv1.PrometheusCheck{}},
+		},
+	}
+	subs := func() []detectv1.SubDetectorResult {
+		return []detectv1.SubDetectorResult{
+			{Name: "node-not-ready", Type: subDetectorField, Condition: true},
+			{Name: "kubelet-absent", Type: subDetectorPrometheus, Condition: true},
+		}
+	}
+
+	t0 := metav1.NewTime(time.Unix(1700000000, 0))
+	first := subs()
+	fired, _, err := combineComposite(spec, first, nil, nil, nil, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired {
+		t.Fatal("fired before kubelet-absent held for 2m")
+	}
+	if !first[0].Firing || first[1].Firing {
+		t.Errorf("firing = %v/%v, want true/false", first[0].Firing, first[1].Firing)
+	}
+	if got := compositeRequeue(spec, first, t0.Time); got != 2*time.Minute {
+		t.Errorf("requeue = %v, want 2m", got)
+	}
+
+	t1 := metav1.NewTime(t0.Add(2 * time.Minute))
+	second := subs()
+	fired, reason, err := combineComposite(spec, second, nil, nil, first, t1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fired {
+		t.Fatal("expected composite to fire once both detectors fire")
+	}
+	if !second[1].Since.Equal(&t0) {
+		t.Errorf("since = %v, want carried over %v", second[1].Since, t0)
+	}
+	if reason != "composite And: node-not-ready, kubelet-absent firing" {
+		t.Errorf("reason = %q", reason)
+	}
+}
+
+func TestCombineCompositeResultMismatch(t *testing.T) {
+	spec := &detectv1.CompositeSpec{
+		Operator: detectv1.CompositeOr,
+		Detectors: []detectv1.SubDetectorSpec{
+			{Name: "a", Field: &detectv1.FieldCheck{}},
+			{Name: "b", Field: &detectv1.FieldCheck{}},
+		},
+	}
+	// A failed source returns no sub-detector results
+	subs := []detectv1.SubDetectorResult{{Name: "a", Type: subDetectorField, Condition: true}}
+	fired, _, err := combineComposite(spec, subs, nil, nil, nil, metav1.Now())
+	if err == nil || fired {
+		t.Errorf("fired = %v, err = %v; want an error", fired, err)
+	}
+	if got := compositeRequeue(spec, nil, time.Now()); got != 0 {
+		t.Errorf("requeue = %v, want none", got)
+	}
+}
+
+func TestCombineCompositeOperators(t *testing.T) {
+	detectors := []detectv1.SubDetectorSpec{
+		{Name: "a", Field: &detectv1.FieldCheck{}},
+		{Name: "latency", Statistical: &detectv1.StatisticalCheck{Metric: "p99"}},
+		{Name: "model", ML: &detectv1.MLCheck{Threshold: "0.9"}},
+	}
+	stat := []detectv1.StatisticalResult{{Metric: "p99", Algorithm: "ewma", Anomalous: true, Score: "4.2"}}
+	ml := &detectv1.MLResult{Score: "0.8500", Anomalous: true}
+	now := metav1.Now()
+
+	tests := []struct {
+		op        detectv1.CompositeOperator
+		minFiring int32
+		want      bool
+	}{
+		{op: detectv1.CompositeAnd, want: false},
+		{op: detectv1.CompositeOr, want: true},
+		{op: detectv1.CompositeAtLeast, minFiring: 2, want: true},
+		{op: detectv1.CompositeAtLeast, minFiring: 3, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			subs := []detectv1.SubDetectorResult{
+				{Name: "a", Type: subDetectorField, Condition: true},
+				{Name: "latency", Type: subDetectorStatistical},
+				{Name: "model", Type: subDetectorML},
+			}
+			spec := &detectv1.CompositeSpec{Operator: tt.op, MinFiring: tt.minFiring, Detectors: detectors}
+			got, _, err := combineComposite(spec, subs, stat, ml, nil, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("fired = %v, want %v", got, tt.want)
+			}
+			if !subs[1].Firing {
+				t.Error("statistical detector should fire")
+			}
+			if subs[2].Firing {
+				t.Error("ML detector should not fire below its own threshold")
+			}
+		})
+	}
+}
+
+func TestReadField(t *testing.T) {
+	node := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "MemoryPressure", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "Unknown"},
+			},
+		},
+	}
+	if v, ok := readField(node, "status.conditions[Ready].status"); !ok || v != "Unknown" {
+		t.Errorf("conditions[Ready].status = %q, %v", v, ok)
+	}
+	if v, ok := readField(node, "status.phase"); !ok || v != "Running" {
+		t.Errorf("phase = %q, %v", v, ok)
+	}
+	if _, ok := readField(node, "status.conditions[DiskPressure].status"); ok {
+		t.Error("expected missing condition to be not found")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/httpclient"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+// Keys read from a data source auth Secret.
+const (
+	secretKeyToken    = "token"
+	secretKeyUsername = "username"
+	secretKeyPassword = "password"
+	secretKeyCA       = "ca.crt"
+	secretKeyCert     = "tls.crt"
+	secretKeyKey      = "tls.key"
+)
+
+// newHTTPClient resolves cfg, including any referenced Secret, into an
+// http.Client. Clients are cached across reconciles, so evaluations reuse
+// connections instead of opening a pool each time.
+func newHTTPClient(ctx context.Context, c client.Reader, secretNamespace string, cfg *detectv1.HTTPClientConfig) (*http.Client, error) {
+	resolved, err := resolveHTTPClientConfig(ctx, c, secretNamespace, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return httpclient.Cached(resolved)
+}
+
+// resolveHTTPClientConfig reads the Secret referenced by cfg into an
+// httpclient.Config. Templates are cluster-scoped and anyone who can create
+// one would otherwise read any Secret through the controller, so the Secret
+// must be in secretNamespace unless that is empty.
+func resolveHTTPClientConfig(ctx context.Context, c client.Reader, secretNamespace string, cfg *detectv1.HTTPClientConfig) (httpclient.Config, error) {
+	if cfg == nil {
+		return httpclient.Config{}, nil
+	}
+
+	resolved := httpclient.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		Headers:            cfg.Headers,
+	}
+	if cfg.Timeout != nil {
+		resolved.Timeout = cfg.Timeout.Duration
+	}
+
+	if ref := cfg.AuthSecretRef; ref != nil {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = secretNamespace
+		}
+		if secretNamespace != "" && namespace != secretNamespace {
+			return resolved, fmt.Errorf("auth secret %s/%s is outside the controller namespace %s",
+				namespace, ref.Name, secretNamespace)
+		}
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+			return resolved, fmt.Errorf("reading auth secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		resolved.BearerToken = string(secret.Data[secretKeyToken])
+		resolved.Username = string(secret.Data[secretKeyUsername])
+		resolved.Password = string(secret.Data[secretKeyPassword])
+		resolved.CAData = secret.Data[secretKeyCA]
+		resolved.CertData = secret.Data[secretKeyCert]
+		resolved.KeyData = secret.Data[secretKeyKey]
+	}
+	return resolved, nil
+}
+
+// newPrometheusClient builds a client for the template's Prometheus API.
+func newPrometheusClient(ctx context.Context, c client.Reader, secretNamespace string, tmpl *detectv1.DetectionTemplate) (*promclient.Client, error) {
+	httpClient, err := newHTTPClient(ctx, c, secretNamespace, tmpl.Spec.PrometheusClient)
+	if err != nil {
+		return nil, err
+	}
+	return promclient.New(tmpl.Spec.PrometheusAPI, httpClient)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+const (
+	// defaultFeedbackWindow is the history captured on each side of an occurrence.
+	defaultFeedbackWindow = 15 * time.Minute
+	// feedbackSnapshotPoints is roughly how many samples a snapshot holds per metric.
+	feedbackSnapshotPoints = 60
+	// feedbackRetryInterval is how often unresolved feedback is retried.
+	feedbackRetryInterval = time.Minute
+)
+
+// DetectionFeedbackReconciler reconciles a DetectionFeedback object
+type DetectionFeedbackReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// APIReader reads objects that are not cached by the manager, such as
+	// data source Secrets. Falls back to Client when nil.
+	APIReader client.Reader
+	// SecretNamespace is the only namespace data source Secrets are read
+	// from. Any namespace when empty.
+	SecretNamespace string
+}
+
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectionfeedbacks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectionfeedbacks/status,verbs=get;update;patch
+
+// Reconcile collects the training sample for a label: the labelled occurrence
+// and the template's metric history around it. Collection waits until the
+// window after the occurrence has passed, and happens once.
+func (r *DetectionFeedbackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var fb detectv1.DetectionFeedback
+	if err := r.Get(ctx, req.NamespacedName, &fb); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if fb.Status.Collected {
+		return ctrl.Result{}, nil
+	}
+
+	var fd detectv1.FaultDetection
+	if err := r.Get(ctx, client.ObjectKey{Namespace: fb.Namespace, Name: fb.Spec.FaultDetectionRef}, &fd); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return r.pending(ctx, &fb, fmt.Sprintf("FaultDetection %q not found", fb.Spec.FaultDetectionRef))
+	}
+
+	id := fb.Spec.OccurrenceID
+	if id == "" && fb.Status.Occurrence != nil {
+		// Keep labelling the occurrence resolved on the first pass
+		id = fb.Status.Occurrence.ID
+	}
+	occ := findOccurrence(fd.Status.Occurrences, id)
+	if occ == nil && id != "" && fb.Status.Occurrence != nil && fb.Status.Occurrence.ID == id {
+		// Aged out of the FaultDetection's history while waiting
+		occ = fb.Status.Occurrence
+	}
+	if occ == nil {
+		if fb.Spec.OccurrenceID == "" {
+			return r.pending(ctx, &fb, "FaultDetection has no anomaly occurrences")
+		}
+		return r.pending(ctx, &fb, fmt.Sprintf("occurrence %q not found", fb.Spec.OccurrenceID))
+	}
+
+	window := defaultFeedbackWindow
+	if fb.Spec.Window != nil && fb.Spec.Window.Duration > 0 {
+		window = fb.Spec.Window.Duration
+	}
+	start := occ.StartTime.Add(-window)
+	end := occ.StartTime.Add(window)
+	if wait := time.Until(end); wait > 0 {
+		fb.Status.Occurrence = occ.DeepCopy()
+		fb.Status.Message = fmt.Sprintf("waiting until %s to capture the window after the occurrence", end.UTC().Format(time.RFC3339))
+		if err := r.Status().Update(ctx, &fb); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	var tmpl detectv1.DetectionTemplate
+	if err := r.Get(ctx, client.ObjectKey{Name: fd.Spec.TemplateRef}, &tmpl); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return r.pending(ctx, &fb, fmt.Sprintf("DetectionTemplate %q not found", fd.Spec.TemplateRef))
+	}
+	// Query what the FaultDetection evaluated, not the template's raw text
+	rendered, err := renderTemplate(ctx, r.apiReader(), &fd, &tmpl)
+	if err != nil {
+		return r.pending(ctx, &fb, fmt.Sprintf("invalid template parameters: %v", err))
+	}
+
+	now := metav1.Now()
+	fb.Status.Collected = true
+	fb.Status.CollectedAt = &now
+	fb.Status.Template = tmpl.Name
+	fb.Status.Target = fd.Spec.Target
+	fb.Status.Occurrence = occ.DeepCopy()
+	fb.Status.Snapshots = r.snapshotMetrics(ctx, rendered, start, end, window)
+	fb.Status.Message = ""
+	if err := r.Status().Update(ctx, &fb); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("Collected feedback snapshot", "faultDetection", fd.Name, "occurrence", occ.ID, "label", fb.Spec.Label)
+	return ctrl.Result{}, nil
+}
+
+// pending records why the snapshot cannot be collected yet and retries later.
+func (r *DetectionFeedbackReconciler) pending(ctx context.Context, fb *detectv1.DetectionFeedback, msg string) (ctrl.Result, error) {
+	if fb.Status.Message != msg {
+		fb.Status.Message = msg
+		if err := r.Status().Update(ctx, fb); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: feedbackRetryInterval}, nil
+}
+
+// snapshotMetrics reads the history of every template query and composite
+// Prometheus sub-detector between start and end. Templates without a
+// Prometheus data source have no history; the occurrence's own results are
+// the only sample then.
+func (r *DetectionFeedbackReconciler) snapshotMetrics(
+	ctx context.Context,
+	tmpl *detectv1.DetectionTemplate,
+	start, end time.Time,
+	window time.Duration,
+) []detectv1.MetricSnapshot {
+	queries := snapshotQueries(&tmpl.Spec)
+	if tmpl.Spec.PrometheusAPI == "" || len(queries) == 0 {
+		return nil
+	}
+
+	step := 2 * window / feedbackSnapshotPoints
+	if step < time.Second {
+		step = time.Second
+	}
+	promClient, err := newPrometheusClient(ctx, r.apiReader(), r.SecretNamespace, tmpl)
+	out := make([]detectv1.MetricSnapshot, 0, len(queries))
+	for _, q := range queries {
+		snap := detectv1.MetricSnapshot{Metric: q.Metric}
+		if err != nil {
+			snap.Error = err.Error()
+			out = append(out, snap)
+			continue
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout(tmpl))
+		points, qerr := promClient.QueryRange(queryCtx, q.Query, start, end, step)
+		cancel()
+		if qerr != nil {
+			dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourcePrometheus).Inc()
+			snap.Error = qerr.Error()
+			out = append(out, snap)
+			continue
+		}
+		for _, p := range points {
+			snap.Samples = append(snap.Samples, detectv1.SnapshotSample{
+				Time:  metav1.NewTime(p.Time),
+				Value: strconv.FormatFloat(p.Value, 'f', -1, 64),
+			})
+		}
+		out = append(out, snap)
+	}
+	return out
+}
+
+// snapshotQueries returns the Prometheus queries whose history is kept: the
+// template queries and the Prometheus sub-detectors of a composite, named
+// like their results.
+func snapshotQueries(spec *detectv1.DetectionTemplateSpec) []detectv1.QuerySpec {
+	queries := spec.Queries
+	if spec.Composite != nil {
+		queries = append([]detectv1.QuerySpec(nil), queries...)
+		for _, d := range spec.Composite.Detectors {
+			if d.Prometheus != nil {
+				queries = append(queries, detectv1.QuerySpec{Metric: d.Name, Query: d.Prometheus.Query})
+			}
+		}
+	}
+	return queries
+}
+
+// findOccurrence returns the occurrence with the given ID, or the most recent
+// one when id is empty.
+func findOccurrence(occurrences []detectv1.AnomalyOccurrence, id string) *detectv1.AnomalyOccurrence {
+	if id == "" {
+		if len(occurrences) == 0 {
+			return nil
+		}
+		return &occurrences[len(occurrences)-1]
+	}
+	for i := range occurrences {
+		if occurrences[i].ID == id {
+			return &occurrences[i]
+		}
+	}
+	return nil
+}
+
+// apiReader returns the uncached reader, or the cached client if none is set.
+func (r *DetectionFeedbackReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DetectionFeedbackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&detectv1.DetectionFeedback{}).
+		Named("detectionfeedback").
+		Complete(r)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+var _ = Describe("DetectionFeedback Controller", func() {
+	Context("When labelling an anomaly occurrence", func() {
+		const (
+			templateName = "feedback-template"
+			fdName       = "feedback-fd"
+			feedbackName = "feedback-sample"
+		)
+
+		ctx := context.Background()
+		feedbackKey := types.NamespacedName{Name: feedbackName, Namespace: "default"}
+
+		reconcileFeedback := func() reconcile.Result {
+			r := &DetectionFeedbackReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: feedbackKey})
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: templateName},
+				Spec:       detectv1alpha1.DetectionTemplateSpec{Scope: detectv1alpha1.ScopeCluster},
+			})).To(Succeed())
+
+			fd := &detectv1alpha1.FaultDetection{
+				ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"},
+				Spec:       detectv1alpha1.FaultDetectionSpec{TemplateRef: templateName},
+			}
+			Expect(k8sClient.Create(ctx, fd)).To(Succeed())
+
+			started := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			ended := metav1.NewTime(started.Add(10 * time.Minute))
+			fd.Status.Occurrences = []detectv1alpha1.AnomalyOccurrence{
+				{ID: "old", StartTime: metav1.NewTime(started.Add(-time.Hour)), EndTime: &started},
+				{
+					ID:        "recent",
+					StartTime: started,
+					EndTime:   &ended,
+					Reason:    "metric p99 value 1.5 exceeded threshold 1",
+					Results:   []detectv1alpha1.Result{{Metric: "p99", Value: "1.500000"}},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, fd)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionFeedback{ObjectMeta: metav1.ObjectMeta{Name: feedbackName, Namespace: "default"}})
+			_ = k8sClient.Delete(ctx, &detectv1alpha1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"}})
+			_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: templateName}})
+		})
+
+		It("collects the most recent occurrence once", func() {
+			Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionFeedback{
+				ObjectMeta: metav1.ObjectMeta{Name: feedbackName, Namespace: "default"},
+				Spec: detectv1alpha1.DetectionFeedbackSpec{
+					FaultDetectionRef: fdName,
+					Label:             detectv1alpha1.FeedbackFalsePositive,
+					Window:            &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			})).To(Succeed())
+
+			res := reconcileFeedback()
+			Expect(res.RequeueAfter).To(BeZero())
+
+			fb := &detectv1alpha1.DetectionFeedback{}
+			Expect(k8sClient.Get(ctx, feedbackKey, fb)).To(Succeed())
+			Expect(fb.Status.Collected).To(BeTrue())
+			Expect(fb.Status.Template).To(Equal(templateName))
+			Expect(fb.Status.Occurrence).NotTo(BeNil())
+			Expect(fb.Status.Occurrence.ID).To(Equal("recent"))
+			Expect(fb.Status.Occurrence.Results).To(HaveLen(1))
+			collectedAt := fb.Status.CollectedAt
+
+			By("not collecting again on later reconciles")
+			reconcileFeedback()
+			Expect(k8sClient.Get(ctx, feedbackKey, fb)).To(Succeed())
+			Expect(fb.Status.CollectedAt.Equal(collectedAt)).To(BeTrue())
+		})
+
+		It("reports an unknown occurrence and retries", func() {
+			Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionFeedback{
+				ObjectMeta: metav1.ObjectMeta{Name: feedbackName, Namespace: "default"},
+				Spec: detectv1alpha1.DetectionFeedbackSpec{
+					FaultDetectionRef: fdName,
+					OccurrenceID:      "missing",
+					Label:             detectv1alpha1.FeedbackTruePositive,
+				},
+			})).To(Succeed())
+
+			res := reconcileFeedback()
+			Expect(res.RequeueAfter).To(Equal(feedbackRetryInterval))
+
+			fb := &detectv1alpha1.DetectionFeedback{}
+			Expect(k8sClient.Get(ctx, feedbackKey, fb)).To(Succeed())
+			Expect(fb.Status.Collected).To(BeFalse())
+			Expect(fb.Status.Message).To(ContainSubstring(`occurrence "missing" not found`))
+		})
+	})
+})
@@ -18,46 +18,272 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
 )
 
+// Defaults for model servers deployed from MLSpec.Image.
+const (
+	defaultModelPort = 8080
+	defaultModelPath = "/predict"
+	// modelReadyRequeue is how often a rolling model server is re-checked.
+	modelReadyRequeue = 10 * time.Second
+	// templateLabel marks objects created on behalf of a DetectionTemplate.
+	templateLabel = "detect.failure-recovery.io/template"
+)
+
 // DetectionTemplateReconciler reconciles a DetectionTemplate object
 type DetectionTemplateReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// ModelNamespace is where model server Deployments and Services are created.
+	ModelNamespace string
 }
 
 // +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectiontemplates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectiontemplates/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectiontemplates/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the DetectionTemplate object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.21.0/pkg/reconcile
+// Reconcile manages the model server of a DetectionTemplate. When spec.ml.image
+// is set, a Deployment and Service are created in ModelNamespace, and the
+// resulting endpoint is published in status once all replicas are available.
+// Both objects are owned by the template, so they are garbage-collected with it.
 func (r *DetectionTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = logf.FromContext(ctx)
+	logger := logf.FromContext(ctx)
+
+	var tmpl detectv1alpha1.DetectionTemplate
+	if err := r.Get(ctx, req.NamespacedName, &tmpl); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	original := tmpl.Status.DeepCopy()
+	name := modelServerName(&tmpl)
+
+	if tmpl.Spec.ML == nil || tmpl.Spec.ML.Image == "" {
+		// Image was removed: tear down anything deployed earlier
+		if tmpl.Status.ModelImage != "" {
+			if err := r.deleteModelServer(ctx, name); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		tmpl.Status.ModelEndpoint = ""
+		tmpl.Status.ModelReady = false
+		tmpl.Status.ModelImage = ""
+		return ctrl.Result{}, r.updateStatusIfChanged(ctx, &tmpl, original)
+	}
+
+	ml := tmpl.Spec.ML
+	port := ml.Port
+	if port == 0 {
+		port = defaultModelPort
+	}
+	templateValue := tmpl.Name
+	if len(validation.IsValidLabelValue(templateValue)) > 0 {
+		// Too long for a label value; the server name is unique as well
+		templateValue = name
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "model-server",
+		"app.kubernetes.io/managed-by": "detection-controller",
+		templateLabel:                  templateValue,
+	}
 
-	// TODO(user): your logic here
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.ModelNamespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, deploy, func() error {
+		deploy.Labels = labels
+		deploy.Spec.Replicas = ml.Replicas
+		if deploy.Spec.Replicas == nil {
+			deploy.Spec.Replicas = ptrInt32(1)
+		}
+		// The selector is immutable, so only set it on creation
+		if deploy.Spec.Selector == nil {
+			deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		}
+		deploy.Spec.Template.Labels = labels
+		setModelContainer(&deploy.Spec.Template.Spec, ml, port)
+		return ctrl.SetControllerReference(&tmpl, deploy, r.Scheme)
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconciling model server deployment: %w", err)
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Model server deployment reconciled", "deployment", name, "operation", op, "image", ml.Image)
+	}
 
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.ModelNamespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		svc.Labels = labels
+		svc.Spec.Selector = labels
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       port,
+			TargetPort: intstr.FromString("http"),
+		}}
+		return ctrl.SetControllerReference(&tmpl, svc, r.Scheme)
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconciling model server service: %w", err)
+	}
+
+	path := ml.Path
+	if path == "" && ml.Protocol != detectv1alpha1.InferenceProtocolV2 {
+		// v2 clients derive /v2/models/<model>/infer from the server root
+		path = defaultModelPath
+	}
+	tmpl.Status.ModelEndpoint = fmt.Sprintf("http://%s.%s.svc:%d%s", name, r.ModelNamespace, port, path)
+	tmpl.Status.ModelImage = ml.Image
+	tmpl.Status.ModelReady = deploymentReady(deploy)
+
+	if err := r.updateStatusIfChanged(ctx, &tmpl, original); err != nil {
+		return ctrl.Result{}, err
+	}
+	if !tmpl.Status.ModelReady {
+		return ctrl.Result{RequeueAfter: modelReadyRequeue}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// setModelContainer sets the fields the controller owns on the "model"
+// container of spec, adding it when missing. Fields the API server defaults,
+// such as the port protocol or probe periods, are left alone so that an
+// unchanged template does not update the Deployment.
+func setModelContainer(spec *corev1.PodSpec, ml *detectv1alpha1.MLSpec, port int32) {
+	var c *corev1.Container
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == "model" {
+			c = &spec.Containers[i]
+			break
+		}
+	}
+	if c == nil {
+		spec.Containers = append(spec.Containers, corev1.Container{Name: "model"})
+		c = &spec.Containers[len(spec.Containers)-1]
+	}
+	c.Image = ml.Image
+	c.Resources = ml.Resources
+
+	httpPort := -1
+	for i := range c.Ports {
+		if c.Ports[i].Name == "http" {
+			httpPort = i
+			break
+		}
+	}
+	if httpPort < 0 {
+		c.Ports = append(c.Ports, corev1.ContainerPort{Name: "http"})
+		httpPort = len(c.Ports) - 1
+	}
+	c.Ports[httpPort].ContainerPort = port
+
+	modelName := -1
+	for i := range c.Env {
+		if c.Env[i].Name == "MODEL_NAME" {
+			modelName = i
+			break
+		}
+	}
+	if modelName < 0 {
+		c.Env = append(c.Env, corev1.EnvVar{Name: "MODEL_NAME"})
+		modelName = len(c.Env) - 1
+	}
+	c.Env[modelName].Value = ml.ModelName
+
+	if c.ReadinessProbe == nil {
+		c.ReadinessProbe = &corev1.Probe{}
+	}
+	c.ReadinessProbe.ProbeHandler = corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")},
+	}
+}
+
+func (r *DetectionTemplateReconciler) deleteModelServer(ctx context.Context, name string) error {
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.ModelNamespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.ModelNamespace}},
+	} {
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DetectionTemplateReconciler) updateStatusIfChanged(
+	ctx context.Context,
+	tmpl *detectv1alpha1.DetectionTemplate,
+	original *detectv1alpha1.DetectionTemplateStatus,
+) error {
+	if equality.Semantic.DeepEqual(tmpl.Status, *original) {
+		return nil
+	}
+	return r.Status().Update(ctx, tmpl)
+}
+
+// modelServerName is the name of the Deployment and Service for a template.
+// Template names that do not make a valid Service name, being too long or
+// containing dots, are shortened and suffixed with a hash of the full name
+// to keep them unique.
+func modelServerName(tmpl *detectv1alpha1.DetectionTemplate) string {
+	name := "model-" + tmpl.Name
+	if len(validation.IsDNS1035Label(name)) == 0 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(tmpl.Name))
+	suffix := "-" + hex.EncodeToString(sum[:5])
+	name = strings.ReplaceAll(name, ".", "-")
+	if n := validation.DNS1035LabelMaxLength - len(suffix); len(name) > n {
+		name = name[:n]
+	}
+	return strings.TrimRight(name, "-") + suffix
+}
+
+// deploymentReady reports whether the current generation is fully rolled out
+// to at least one replica.
+func deploymentReady(d *appsv1.Deployment) bool {
+	if d.Generation != d.Status.ObservedGeneration {
+		return false
+	}
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	// A scaled down model server answers nothing
+	if want == 0 {
+		return false
+	}
+	return d.Status.UpdatedReplicas >= want && d.Status.AvailableReplicas >= want
+}
+
+func ptrInt32(v int32) *int32 {
+	return &v
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DetectionTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&detectv1alpha1.DetectionTemplate{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
 		Named("detectiontemplate").
 		Complete(r)
 }
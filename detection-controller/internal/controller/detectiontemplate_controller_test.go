@@ -18,11 +18,17 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -81,4 +87,142 @@ var _ = Describe("DetectionTemplate Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When spec.ml.image is set", func() {
+		const templateName = "ml-template"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: templateName}
+		serverKey := types.NamespacedName{Name: "model-" + templateName, Namespace: "default"}
+
+		reconcileTemplate := func() reconcile.Result {
+			r := &DetectionTemplateReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				ModelNamespace: "default",
+			}
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}
+
+		BeforeEach(func() {
+			tmpl := &detectv1alpha1.DetectionTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: templateName},
+				Spec: detectv1alpha1.DetectionTemplateSpec{
+					Scope: detectv1alpha1.ScopeCluster,
+					ML: &detectv1alpha1.MLSpec{
+						ModelName: "isolation-forest",
+						Image:     "example.com/models/iforest:v1",
+						Port:      9000,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tmpl)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			tmpl := &detectv1alpha1.DetectionTemplate{}
+			Expect(k8sClient.Get(ctx, key, tmpl)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tmpl)).To(Succeed())
+		})
+
+		It("deploys, rolls and removes the model server", func() {
+			By("creating a Deployment and Service owned by the template")
+			res := reconcileTemplate()
+			Expect(res.RequeueAfter).To(Equal(modelReadyRequeue))
+
+			deploy := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, serverKey, deploy)).To(Succeed())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/models/iforest:v1"))
+			Expect(deploy.OwnerReferences).To(HaveLen(1))
+			Expect(deploy.OwnerReferences[0].Name).To(Equal(templateName))
+			Expect(k8sClient.Get(ctx, serverKey, &corev1.Service{})).To(Succeed())
+
+			tmpl := &detectv1alpha1.DetectionTemplate{}
+			Expect(k8sClient.Get(ctx, key, tmpl)).To(Succeed())
+			Expect(tmpl.Status.ModelEndpoint).To(Equal("http://model-ml-template.default.svc:9000/predict"))
+			Expect(tmpl.Status.ModelReady).To(BeFalse())
+
+			By("rolling the Deployment when the image changes")
+			tmpl.Spec.ML.Image = "example.com/models/iforest:v2"
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+			reconcileTemplate()
+			Expect(k8sClient.Get(ctx, serverKey, deploy)).To(Succeed())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/models/iforest:v2"))
+
+			By("removing the model server when the image is unset")
+			Expect(k8sClient.Get(ctx, key, tmpl)).To(Succeed())
+			tmpl.Spec.ML.Image = ""
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+			reconcileTemplate()
+			err := k8sClient.Get(ctx, serverKey, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, tmpl)).To(Succeed())
+			Expect(tmpl.Status.ModelEndpoint).To(BeEmpty())
+		})
+	})
 })
+
+func TestModelServerName(t *testing.T) {
+	name := func(template string) string {
+		return modelServerName(&detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: template}})
+	}
+	if got := name("latency"); got != "model-latency" {
+		t.Errorf("name = %s, want the template name kept", got)
+	}
+	long := strings.Repeat("a", 60)
+	for _, template := range []string{long, long + "b", "ml.latency.v2"} {
+		got := name(template)
+		if errs := validation.IsDNS1035Label(got); len(errs) > 0 {
+			t.Errorf("name(%s) = %s: %v", template, got, errs)
+		}
+	}
+	if name(long) == name(long+"b") {
+		t.Error("templates with a shared prefix got the same name")
+	}
+}
+
+func TestSetModelContainerKeepsDefaults(t *testing.T) {
+	ml := &detectv1alpha1.MLSpec{Image: "example.com/models/iforest:v1", ModelName: "iforest"}
+	var spec corev1.PodSpec
+	setModelContainer(&spec, ml, 8080)
+
+	// The API server defaults fields, and other controllers add sidecars
+	spec.Containers[0].Ports[0].Protocol = corev1.ProtocolTCP
+	spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	spec.Containers[0].ReadinessProbe.PeriodSeconds = 10
+	spec.Containers[0].ReadinessProbe.TimeoutSeconds = 1
+	spec.Containers = append(spec.Containers, corev1.Container{Name: "istio-proxy", Image: "istio/proxyv2"})
+	existing := spec.DeepCopy()
+
+	setModelContainer(&spec, ml, 8080)
+	if !equality.Semantic.DeepEqual(&spec, existing) {
+		t.Errorf("spec = %+v, want it unchanged", spec)
+	}
+
+	ml.Image = "example.com/models/iforest:v2"
+	setModelContainer(&spec, ml, 9090)
+	c := spec.Containers[0]
+	if c.Image != ml.Image || c.Ports[0].ContainerPort != 9090 || c.Ports[0].Protocol != corev1.ProtocolTCP {
+		t.Errorf("container = %+v, want the image and port updated", c)
+	}
+	if len(spec.Containers) != 2 {
+		t.Errorf("containers = %d, want the sidecar kept", len(spec.Containers))
+	}
+}
+
+func TestDeploymentReady(t *testing.T) {
+	replicas := func(n int32) *appsv1.Deployment {
+		d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &n}}
+		d.Status.UpdatedReplicas, d.Status.AvailableReplicas = n, n
+		return d
+	}
+	if !deploymentReady(replicas(2)) {
+		t.Error("want a rolled out deployment ready")
+	}
+	if deploymentReady(replicas(0)) {
+		t.Error("want a deployment scaled to zero not ready")
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/detector"
+	"github.com/phuongbac/detection-controller/internal/jsonsource"
+	"github.com/phuongbac/detection-controller/internal/logtail"
+)
+
+// Names of the source detectors that have no sub-detector type.
+const (
+	detectorComposite = "composite"
+	detectorHTTP      = "http"
+)
+
+// NewDetectorRegistry returns the built-in detectors of r. Source detectors
+// are registered in the order they take precedence when a template
+// configures more than one.
+func NewDetectorRegistry(r *FaultDetectionReconciler) (*detector.Registry, error) {
+	return detector.NewRegistry(
+		&compositeDetector{r: r},
+		&fieldDetector{reader: r.Client},
+		&prometheusDetector{connect: func(ctx context.Context, tmpl *detectv1.DetectionTemplate) (prometheusQuerier, error) {
+			c, err := newPrometheusClient(ctx, r.apiReader(), r.SecretNamespace, tmpl)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		}},
+		&eventsDetector{r: r},
+		&logsDetector{r: r},
+		&probesDetector{r: r},
+		&httpDetector{r: r},
+		&alertsDetector{store: r.Alerts},
+		&statisticalDetector{r: r},
+		&mlDetector{reader: r.apiReader(), secretNamespace: r.SecretNamespace},
+	)
+}
+
+// compositeDetector runs the field and Prometheus sub-detectors of a
+// composite template. The reconciler combines them into a verdict once the
+// analyzers have run.
+type compositeDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *compositeDetector) Name() string        { return detectorComposite }
+func (d *compositeDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *compositeDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.Composite != nil
+}
+
+func (d *compositeDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	if len(spec.Composite.Detectors) == 0 {
+		return errors.New("composite has no detectors")
+	}
+	return nil
+}
+
+func (d *compositeDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	out := &detector.Outcome{Series: map[string][]float64{}}
+	out.Targets.Composite, out.Results = d.r.evaluateCompositeSources(ctx, in.FaultDetection, in.Template, out.Series)
+	return out, nil
+}
+
+// eventsDetector counts matching Kubernetes Events (Option C).
+type eventsDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *eventsDetector) Name() string        { return eventsMetric }
+func (d *eventsDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *eventsDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.Events != nil
+}
+
+func (d *eventsDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	if p := spec.Events.MessagePattern; p != "" {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid messagePattern: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *eventsDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	spec := in.Template.Spec.Events
+	eventResults, total, err := d.r.evaluateEvents(ctx, in.FaultDetection, spec, in.Now)
+	if err != nil {
+		dataSourceErrorsTotal.WithLabelValues(in.Template.Name, sourceKubernetes).Inc()
+		return nil, err
+	}
+	out := &detector.Outcome{
+		Results: []detectv1.Result{{Metric: eventsMetric, Value: strconv.Itoa(int(total))}},
+		Reason:  eventsReason(eventResults, spec),
+	}
+	out.Anomalous = out.Reason != ""
+	if len(eventResults) > maxEventResults {
+		eventResults = eventResults[:maxEventResults]
+	}
+	out.Targets.Events = eventResults
+	return out, nil
+}
+
+// logsDetector matches patterns in container logs (Option D).
+type logsDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *logsDetector) Name() string        { return logsMetric }
+func (d *logsDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *logsDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.Logs != nil
+}
+
+func (d *logsDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	_, err := logtail.Compile(spec.Logs.Patterns)
+	return err
+}
+
+func (d *logsDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	spec := in.Template.Spec.Logs
+	logResults, totals, err := d.r.evaluateLogs(ctx, in.FaultDetection, spec, in.Now)
+	if err != nil {
+		dataSourceErrorsTotal.WithLabelValues(in.Template.Name, sourceKubernetes).Inc()
+		return nil, err
+	}
+	out := &detector.Outcome{Results: totals, Reason: logsReason(logResults, spec)}
+	out.Anomalous = out.Reason != ""
+	if len(logResults) > maxLogResults {
+		logResults = logResults[:maxLogResults]
+	}
+	out.Targets.Logs = logResults
+	return out, nil
+}
+
+// probesDetector runs synthetic probes (Option E).
+type probesDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *probesDetector) Name() string        { return probesMetric }
+func (d *probesDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *probesDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return len(spec.Probes) > 0
+}
+
+func (d *probesDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	seen := map[string]bool{}
+	for _, p := range spec.Probes {
+		if seen[p.Name] {
+			return fmt.Errorf("probe %q is defined twice", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
+
+func (d *probesDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	probeResults, totals, err := d.r.evaluateProbes(ctx, in.FaultDetection, in.Template.Spec.Probes)
+	if err != nil {
+		return nil, err
+	}
+	out := &detector.Outcome{Results: totals, Reason: probesReason(probeResults)}
+	out.Anomalous = out.Reason != ""
+	out.Targets.Probes = probeResults
+	return out, nil
+}
+
+// httpDetector extracts values from a JSON endpoint and applies the
+// template's rule (Option F).
+type httpDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *httpDetector) Name() string        { return detectorHTTP }
+func (d *httpDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *httpDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.HTTP != nil
+}
+
+func (d *httpDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	for _, v := range spec.HTTP.Values {
+		if err := jsonsource.Parse(v.JSONPath); err != nil {
+			return fmt.Errorf("value %s: invalid jsonPath: %w", v.Metric, err)
+		}
+	}
+	return nil
+}
+
+func (d *httpDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	tmpl := in.Template
+	results, values, reason := d.r.evaluateHTTPSource(ctx, tmpl)
+	out := &detector.Outcome{Results: results, Anomalous: reason != "", Reason: reason}
+	for _, v := range tmpl.Spec.HTTP.Values {
+		if value, ok := values[v.Metric]; ok && v.Expected == "" {
+			applyRule(tmpl.Spec.Rule, v.Metric, value, out)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/detector"
+	"github.com/phuongbac/detection-controller/internal/inference"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+// fakeQuerier answers queries from fixed values.
+type fakeQuerier struct {
+	values map[string]float64
+	points map[string][]promclient.Point
+}
+
+func (f *fakeQuerier) Query(_ context.Context, query string) (float64, error) {
+	v, ok := f.values[query]
+	if !ok {
+		return 0, promclient.ErrNoData
+	}
+	return v, nil
+}
+
+func (f *fakeQuerier) QueryRange(_ context.Context, query string, _, _ time.Time, _ time.Duration) ([]promclient.Point, error) {
+	return f.points[query], nil
+}
+
+func TestPrometheusDetector(t *testing.T) {
+	q := &fakeQuerier{
+		values: map[string]float64{"errors": 7},
+		points: map[string][]promclient.Point{"latency": {{Value: 1}, {Value: 3}}},
+	}
+	d := &prometheusDetector{connect: func(context.Context, *detectv1.DetectionTemplate) (prometheusQuerier, error) {
+		return q, nil
+	}}
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		PrometheusAPI: "http://prometheus:9090",
+		Queries: []detectv1.QuerySpec{
+			{Metric: "error_count", Query: "errors"},
+			{Metric: "latency", Query: "latency", Range: &detectv1.RangeSpec{
+				Lookback:    metav1.Duration{Duration: 5 * time.Minute},
+				Aggregation: detectv1.AggregationMax,
+			}},
+			{Metric: "missing", Query: "absent"},
+		},
+		Rule: "error_count > 5",
+	}}
+	if !d.Enabled(&tmpl.Spec) {
+		t.Fatal("expected the detector to be enabled")
+	}
+	if err := d.Validate(&tmpl.Spec); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := d.Evaluate(context.Background(), detector.Input{FaultDetection: &detectv1.FaultDetection{}, Template: tmpl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Results) != 3 || out.Results[0].Value != "7.000000" || out.Results[1].Value != "3.000000" {
+		t.Errorf("results = %+v", out.Results)
+	}
+	if out.Results[2].Error == "" {
+		t.Errorf("missing = %+v, want the no data error", out.Results[2])
+	}
+	if len(out.Series["latency"]) != 2 {
+		t.Errorf("series = %v, want the latency samples", out.Series)
+	}
+	if !out.Anomalous || !strings.Contains(out.Reason, "error_count") {
+		t.Errorf("verdict = %v %q, want the rule to fire", out.Anomalous, out.Reason)
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		d := &prometheusDetector{connect: func(context.Context, *detectv1.DetectionTemplate) (prometheusQuerier, error) {
+			return nil, errors.New("bad address")
+		}}
+		out, err := d.Evaluate(context.Background(), detector.Input{FaultDetection: &detectv1.FaultDetection{}, Template: tmpl})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, res := range out.Results {
+			if res.Error != "bad address" {
+				t.Errorf("result = %+v, want the connection error on every query", res)
+			}
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		spec := tmpl.Spec.DeepCopy()
+		spec.Queries[2].Metric = "latency"
+		if err := d.Validate(spec); err == nil {
+			t.Error("expected a duplicate metric to be rejected")
+		}
+	})
+}
+
+func node(name, ready string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionStatus(ready)},
+		}},
+	}
+}
+
+func TestFieldDetector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			node("node-a", "True"),
+			node("node-b", "False"),
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout-0"},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			},
+		).
+		Build()
+	d := &fieldDetector{reader: c}
+	fd := &detectv1.FaultDetection{}
+
+	nodes := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Scope:      "Node",
+		APIVersion: "v1",
+		Kind:       "Node",
+		FieldPath:  nodeReadyPath,
+		Expected:   "True",
+	}}
+	out, err := d.Evaluate(context.Background(), detector.Input{FaultDetection: fd, Template: nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Targets.Nodes) != 2 || !out.Targets.Nodes[0].Ok || out.Targets.Nodes[1].Ok {
+		t.Errorf("nodes = %+v, want node-a ready and node-b not", out.Targets.Nodes)
+	}
+	if !out.Anomalous || out.Reason != "Node node-b Expected Ready=True but got False" {
+		t.Errorf("verdict = %v %q", out.Anomalous, out.Reason)
+	}
+
+	pod := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Scope:      "Pod",
+		APIVersion: "v1",
+		Kind:       "Pod",
+		FieldPath:  "status.phase",
+		Expected:   "Running",
+	}}
+	fd.Spec.Target = &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-0"}
+	out, err = d.Evaluate(context.Background(), detector.Input{FaultDetection: fd, Template: pod})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Anomalous || out.Reason != "Expected status.phase=Running but got Pending" {
+		t.Errorf("verdict = %v %q", out.Anomalous, out.Reason)
+	}
+
+	fd.Spec.Target.Name = "checkout-9"
+	out, _ = d.Evaluate(context.Background(), detector.Input{FaultDetection: fd, Template: pod})
+	if !out.Anomalous || out.Reason != "Target resource not found or unreachable" {
+		t.Errorf("verdict = %v %q, want a missing target", out.Anomalous, out.Reason)
+	}
+
+	pod.Spec.FieldPath = "status..phase"
+	if err := d.Validate(&pod.Spec); err == nil {
+		t.Error("expected an empty path segment to be rejected")
+	}
+}
+
+func TestMLDetector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"anomaly":true,"score":0.93}`))
+	}))
+	defer srv.Close()
+
+	d := &mlDetector{}
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		ML: &detectv1.MLSpec{ModelName: "latency", Endpoint: srv.URL, Threshold: "0.9"},
+	}}
+	if err := d.Validate(&tmpl.Spec); err != nil {
+		t.Fatal(err)
+	}
+	in := detector.Input{
+		FaultDetection: &detectv1.FaultDetection{},
+		Template:       tmpl,
+		Results:        []detectv1.Result{{Metric: "latency", Value: "0.250000"}},
+	}
+	out, err := d.Evaluate(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Targets.ML == nil || out.Targets.ML.Score != "0.9300" {
+		t.Fatalf("ml = %+v", out.Targets.ML)
+	}
+	if !out.Anomalous || out.Reason != "ML model detected anomaly (score 0.9300)" {
+		t.Errorf("verdict = %v %q", out.Anomalous, out.Reason)
+	}
+
+	t.Run("not ready", func(t *testing.T) {
+		tmpl := tmpl.DeepCopy()
+		tmpl.Spec.ML.Endpoint = ""
+		out, err := d.Evaluate(context.Background(), detector.Input{FaultDetection: &detectv1.FaultDetection{}, Template: tmpl})
+		if err != nil || out.Targets.ML != nil || out.Anomalous {
+			t.Errorf("outcome = %+v, %v; want nothing until the model is ready", out, err)
+		}
+	})
+
+	t.Run("legacy flag only", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"anomaly":true}`))
+		}))
+		defer srv.Close()
+		tmpl := tmpl.DeepCopy()
+		tmpl.Spec.ML.Endpoint = srv.URL
+		tmpl.Spec.ML.Threshold = "1.5"
+		in := in
+		in.Template = tmpl
+		if out, err := d.Evaluate(context.Background(), in); err != nil || !out.Anomalous {
+			t.Errorf("outcome = %+v, %v; want the model's flag used without a score", out, err)
+		}
+	})
+
+	t.Run("per node", func(t *testing.T) {
+		var got struct {
+			Instances []struct {
+				Target       inference.Target `json:"target"`
+				FeatureNames []string         `json:"featureNames"`
+				Features     []float64        `json:"features"`
+			} `json:"instances"`
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			_, _ = w.Write([]byte(`{"predictions":[{"score":0.2},{"score":0.95}]}`))
+		}))
+		defer srv.Close()
+		tmpl := tmpl.DeepCopy()
+		tmpl.Spec.ML.Endpoint = srv.URL
+		tmpl.Spec.ML.Protocol = detectv1.InferenceProtocolV1
+		in := in
+		in.Template = tmpl
+		in.Targets.Nodes = []detectv1.NodeResult{{NodeName: "worker-1", Ok: true}, {NodeName: "worker-2"}}
+		out, err := d.Evaluate(context.Background(), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Instances) != 2 || got.Instances[1].Target.Name != "worker-2" ||
+			strings.Join(got.Instances[1].FeatureNames, ",") != "latency,ok" || got.Instances[1].Features[1] != 0 {
+			t.Errorf("request = %+v, want one instance per node", got)
+		}
+		ml := out.Targets.ML
+		if ml == nil || len(ml.Targets) != 2 || ml.Targets[0].Anomalous || !ml.Targets[1].Anomalous || ml.Score != "0.9500" {
+			t.Fatalf("ml = %+v, want worker-2 anomalous", ml)
+		}
+		if out.Reason != "ML model detected anomaly on Node worker-2 (score 0.9500)" {
+			t.Errorf("reason = %q", out.Reason)
+		}
+	})
+
+	tmpl.Spec.ML.Threshold = "high"
+	if err := d.Validate(&tmpl.Spec); err == nil {
+		t.Error("expected an invalid threshold to be rejected")
+	}
+}
+
+func TestNewDetectorRegistry(t *testing.T) {
+	detectors, err := NewDetectorRegistry(&FaultDetectionReconciler{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Composite templates may also set prometheusAPI for their sub-detectors
+	spec := &detectv1.DetectionTemplateSpec{
+		PrometheusAPI: "http://prometheus:9090",
+		Queries:       []detectv1.QuerySpec{{Metric: "m", Query: "q"}},
+		Composite:     &detectv1.CompositeSpec{},
+	}
+	if d := detectors.Source(spec); d == nil || d.Name() != detectorComposite {
+		t.Errorf("source = %v, want composite first", d)
+	}
+	spec.Composite = nil
+	if d := detectors.Source(spec); d == nil || d.Name() != subDetectorPrometheus {
+		t.Errorf("source = %v, want prometheus", d)
+	}
+	if d := detectors.Source(&detectv1.DetectionTemplateSpec{}); d != nil {
+		t.Errorf("source = %v, want none", d.Name())
+	}
+
+	// Statistical detectors run before the ML model
+	spec.Statistical = []detectv1.StatisticalSpec{{Algorithm: detectv1.AlgorithmEWMA}}
+	spec.ML = &detectv1.MLSpec{ModelName: "iforest"}
+	var names []string
+	for _, d := range detectors.Analyzers(spec) {
+		names = append(names, d.Name())
+	}
+	if strings.Join(names, ",") != subDetectorStatistical+","+subDetectorML {
+		t.Errorf("analyzers = %v, want statistical then ml", names)
+	}
+}
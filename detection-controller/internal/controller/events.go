@@ -0,0 +1,275 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+const (
+	// defaultEventWindow is how far back events are counted.
+	defaultEventWindow = 10 * time.Minute
+	// maxEventResults bounds the objects reported in status.
+	maxEventResults = 20
+	// eventsMetric names the matched event count in query results, so
+	// statistical detectors and the ML model can use it.
+	eventsMetric = "events"
+)
+
+// evaluateEvents lists the Events matching spec and attributes them to
+// their involved objects. It also returns the total number of occurrences.
+func (r *FaultDetectionReconciler) evaluateEvents(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	spec *detectv1.EventSpec,
+	now time.Time,
+) ([]detectv1.EventResult, int32, error) {
+	var opts []client.ListOption
+	if t := fd.Spec.Target; t != nil && t.Name != "" && t.Namespace != "" {
+		opts = append(opts, client.InNamespace(t.Namespace))
+	}
+	var list corev1.EventList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return nil, 0, err
+	}
+	return matchEvents(list.Items, spec, fd.Spec.Target, now)
+}
+
+// matchEvents groups the events matching spec by involved object and counts
+// their occurrences within the window, most frequent first.
+func matchEvents(
+	events []corev1.Event,
+	spec *detectv1.EventSpec,
+	target *detectv1.ObjectRef,
+	now time.Time,
+) ([]detectv1.EventResult, int32, error) {
+	var pattern *regexp.Regexp
+	if spec.MessagePattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(spec.MessagePattern); err != nil {
+			return nil, 0, fmt.Errorf("invalid messagePattern: %w", err)
+		}
+	}
+	window := defaultEventWindow
+	if spec.Window != nil && spec.Window.Duration > 0 {
+		window = spec.Window.Duration
+	}
+	minCount := spec.MinCount
+	if minCount <= 0 {
+		minCount = 1
+	}
+	since := now.Add(-window)
+
+	byObject := map[detectv1.ObjectRef]*detectv1.EventResult{}
+	var total int32
+	for i := range events {
+		e := &events[i]
+		if !eventMatches(e, spec) || !targetMatches(target, e) {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(e.Message) {
+			continue
+		}
+		last := eventLastSeen(e)
+		if last.Before(since) {
+			continue
+		}
+
+		// Earlier repeats of an event that started before the window are not
+		// known to fall inside it, so only its latest occurrence counts
+		count := int32(1)
+		if !eventFirstSeen(e).Before(since) {
+			count = eventCount(e)
+		}
+		total += count
+
+		obj := detectv1.ObjectRef{
+			APIVersion: e.InvolvedObject.APIVersion,
+			Kind:       e.InvolvedObject.Kind,
+			Namespace:  e.InvolvedObject.Namespace,
+			Name:       e.InvolvedObject.Name,
+		}
+		res, ok := byObject[obj]
+		if !ok {
+			res = &detectv1.EventResult{Object: obj}
+			byObject[obj] = res
+		}
+		res.Count += count
+		if res.LastSeen == nil || last.After(res.LastSeen.Time) {
+			seen := metav1.NewTime(last)
+			res.LastSeen = &seen
+			res.Reason = e.Reason
+			res.Message = e.Message
+		}
+	}
+
+	results := make([]detectv1.EventResult, 0, len(byObject))
+	for _, res := range byObject {
+		res.Anomalous = res.Count >= minCount
+		results = append(results, *res)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return b.LastSeen.Before(a.LastSeen)
+		}
+		return objectKey(a.Object) < objectKey(b.Object)
+	})
+	return results, total, nil
+}
+
+// eventsReason describes the most frequent anomalous object, or returns ""
+// when no object reached the minimum count.
+func eventsReason(results []detectv1.EventResult, spec *detectv1.EventSpec) string {
+	window := defaultEventWindow
+	if spec.Window != nil && spec.Window.Duration > 0 {
+		window = spec.Window.Duration
+	}
+	for _, res := range results {
+		if res.Anomalous {
+			return fmt.Sprintf("event %s on %s %s seen %d times in %s",
+				res.Reason, res.Object.Kind, objectKey(res.Object), res.Count, window)
+		}
+	}
+	return ""
+}
+
+// eventMatches applies the reason, kind and type filters of spec. The
+// message pattern is left to the caller, which compiles it once.
+func eventMatches(e *corev1.Event, spec *detectv1.EventSpec) bool {
+	if len(spec.Reasons) > 0 && !slices.Contains(spec.Reasons, e.Reason) {
+		return false
+	}
+	if spec.InvolvedObjectKind != "" && e.InvolvedObject.Kind != spec.InvolvedObjectKind {
+		return false
+	}
+	return spec.Type == "" || e.Type == spec.Type
+}
+
+// targetMatches reports whether the event concerns the FaultDetection's
+// target. Every event matches when the target has no name.
+func targetMatches(target *detectv1.ObjectRef, e *corev1.Event) bool {
+	if target == nil || target.Name == "" {
+		return true
+	}
+	obj := e.InvolvedObject
+	return obj.Name == target.Name &&
+		(target.Kind == "" || obj.Kind == target.Kind) &&
+		(target.Namespace == "" || obj.Namespace == target.Namespace)
+}
+
+// eventLastSeen returns when the event last occurred. Events recorded
+// through events.k8s.io only set eventTime and series.
+func eventLastSeen(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func eventFirstSeen(e *corev1.Event) time.Time {
+	switch {
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func eventCount(e *corev1.Event) int32 {
+	switch {
+	case e.Series != nil && e.Series.Count > 0:
+		return e.Series.Count
+	case e.Count > 0:
+		return e.Count
+	}
+	return 1
+}
+
+func objectKey(o detectv1.ObjectRef) string {
+	if o.Namespace == "" {
+		return o.Name
+	}
+	return o.Namespace + "/" + o.Name
+}
+
+// faultDetectionsForEvent maps an Event to the FaultDetections whose
+// template and target it matches, so events are picked up without waiting
+// for the next interval. The message pattern is not checked here; the
+// reconcile applies it.
+func (r *FaultDetectionReconciler) faultDetectionsForEvent(ctx context.Context, obj client.Object) []reconcile.Request {
+	e, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	var templates detectv1.DetectionTemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		logger.Error(err, "unable to list DetectionTemplates for event")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, tmpl := range templates.Items {
+		if tmpl.Spec.Events == nil || !eventMatches(e, tmpl.Spec.Events) {
+			continue
+		}
+		var list detectv1.FaultDetectionList
+		if err := r.List(ctx, &list, client.MatchingFields{templateRefIndex: tmpl.Name}); err != nil {
+			logger.Error(err, "unable to list FaultDetections for template", "template", tmpl.Name)
+			continue
+		}
+		for _, fd := range list.Items {
+			if targetMatches(fd.Spec.Target, e) {
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&fd)})
+			}
+		}
+	}
+	return reqs
+}
+
+// eventRecorded passes new and repeated events; deleted events only expire.
+var eventRecorded = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return true },
+	UpdateFunc:  func(event.UpdateEvent) bool { return true },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
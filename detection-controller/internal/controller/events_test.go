@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func testEvent(name, kind, ns, obj, reason, msg string, count int32, first, last time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: ns, Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: ns, Name: obj},
+		Reason:         reason,
+		Message:        msg,
+		Type:           corev1.EventTypeWarning,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(first),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestMatchEvents(t *testing.T) {
+	now := time.Now()
+	events := []corev1.Event{
+		testEvent("a", "Pod", "shop", "checkout-0", "BackOff", "Back-off restarting failed container", 4, now.Add(-5*time.Minute), now.Add(-time.Minute)),
+		// Started before the window: only the latest repeat counts
+		testEvent("b", "Pod", "shop", "checkout-1", "BackOff", "Back-off restarting failed container", 9, now.Add(-time.Hour), now.Add(-2*time.Minute)),
+		// Outside the window
+		testEvent("c", "Pod", "shop", "checkout-2", "BackOff", "Back-off restarting failed container", 5, now.Add(-time.Hour), now.Add(-30*time.Minute)),
+		testEvent("d", "Pod", "shop", "checkout-0", "FailedMount", "MountVolume.SetUp failed", 1, now, now),
+		testEvent("e", "Node", "", "worker-1", "BackOff", "unrelated", 1, now, now),
+	}
+	spec := &detectv1.EventSpec{
+		Reasons:            []string{"BackOff"},
+		InvolvedObjectKind: "Pod",
+		Window:             &metav1.Duration{Duration: 10 * time.Minute},
+		MinCount:           3,
+	}
+
+	results, total, err := matchEvents(events, spec, nil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 {
+		t.Errorf("total = %d, want 5", total)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want checkout-0 and checkout-1", results)
+	}
+	if results[0].Object.Name != "checkout-0" || results[0].Count != 4 || !results[0].Anomalous {
+		t.Errorf("results[0] = %+v, want anomalous checkout-0 with 4 events", results[0])
+	}
+	if results[1].Object.Name != "checkout-1" || results[1].Count != 1 || results[1].Anomalous {
+		t.Errorf("results[1] = %+v, want healthy checkout-1 with 1 event", results[1])
+	}
+	if got := eventsReason(results, spec); !strings.Contains(got, "BackOff on Pod shop/checkout-0 seen 4 times") {
+		t.Errorf("reason = %q", got)
+	}
+
+	t.Run("target", func(t *testing.T) {
+		target := &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-1"}
+		results, _, err := matchEvents(events, spec, target, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Object.Name != "checkout-1" {
+			t.Errorf("results = %+v, want only checkout-1", results)
+		}
+	})
+
+	t.Run("message pattern", func(t *testing.T) {
+		spec := &detectv1.EventSpec{MessagePattern: `^MountVolume\.SetUp failed`}
+		results, _, err := matchEvents(events, spec, nil, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Reason != "FailedMount" || !results[0].Anomalous {
+			t.Errorf("results = %+v, want anomalous FailedMount", results)
+		}
+		if _, _, err := matchEvents(events, &detectv1.EventSpec{MessagePattern: "("}, nil, now); err == nil {
+			t.Error("expected invalid pattern error")
+		}
+	})
+}
+
+func TestEventTimes(t *testing.T) {
+	observed := time.Now().Truncate(time.Second)
+	e := &corev1.Event{
+		EventTime: metav1.NewMicroTime(observed.Add(-time.Minute)),
+		Series:    &corev1.EventSeries{Count: 7, LastObservedTime: metav1.NewMicroTime(observed)},
+	}
+	if got := eventLastSeen(e); !got.Equal(observed) {
+		t.Errorf("last seen = %v, want series last observed time %v", got, observed)
+	}
+	if got := eventFirstSeen(e); !got.Equal(observed.Add(-time.Minute)) {
+		t.Errorf("first seen = %v, want event time", got)
+	}
+	if got := eventCount(e); got != 7 {
+		t.Errorf("count = %d, want series count 7", got)
+	}
+	if got := eventCount(&corev1.Event{}); got != 1 {
+		t.Errorf("count = %d, want 1 for an event without count", got)
+	}
+}
+
+func TestFaultDetectionsForEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := detectv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := func(name string, events *detectv1.EventSpec) *detectv1.DetectionTemplate {
+		return &detectv1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: detectv1.DetectionTemplateSpec{Events: events}}
+	}
+	fd := func(name, tmpl string, target *detectv1.ObjectRef) *detectv1.FaultDetection {
+		return &detectv1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       detectv1.FaultDetectionSpec{TemplateRef: tmpl, Target: target},
+		}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			tmpl("oom", &detectv1.EventSpec{Reasons: []string{"OOMKilling"}}),
+			tmpl("mount", &detectv1.EventSpec{Reasons: []string{"FailedMount"}}),
+			tmpl("metrics", nil),
+			fd("all-nodes", "oom", nil),
+			fd("worker-1", "oom", &detectv1.ObjectRef{Kind: "Node", Name: "worker-1"}),
+			fd("worker-2", "oom", &detectv1.ObjectRef{Kind: "Node", Name: "worker-2"}),
+			fd("mounts", "mount", nil),
+			fd("cpu", "metrics", nil),
+		).
+		WithIndex(&detectv1.FaultDetection{}, templateRefIndex, func(obj client.Object) []string {
+			return []string{obj.(*detectv1.FaultDetection).Spec.TemplateRef}
+		}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	e := testEvent("oom", "Node", "", "worker-1", "OOMKilling", "Memory cgroup out of memory", 1, time.Now(), time.Now())
+	got := map[string]bool{}
+	for _, req := range r.faultDetectionsForEvent(context.Background(), &e) {
+		got[req.Name] = true
+	}
+	if len(got) != 2 || !got["all-nodes"] || !got["worker-1"] {
+		t.Errorf("requests = %v, want all-nodes and worker-1", got)
+	}
+}
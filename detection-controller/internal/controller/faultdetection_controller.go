@@ -18,33 +18,85 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/alertmanager"
+	"github.com/phuongbac/detection-controller/internal/detector"
+	"github.com/phuongbac/detection-controller/internal/logtail"
+)
+
+const (
+	// maxOccurrences bounds the anomaly history kept in FaultDetection status.
+	maxOccurrences = 20
+	// templateRetryInterval is how often a missing template is looked up again.
+	templateRetryInterval = 30 * time.Second
+	// templateRefIndex indexes FaultDetections by spec.templateRef.
+	templateRefIndex = "spec.templateRef"
+)
+
+// Reasons of the TemplateAvailable condition.
+const (
+	reasonTemplateResolved  = "TemplateResolved"
+	reasonTemplateNotFound  = "TemplateNotFound"
+	reasonInvalidParameters = "InvalidParameters"
+	reasonInvalidTemplate   = "InvalidTemplate"
 )
 
 // FaultDetectionReconciler reconciles a FaultDetection object
 type FaultDetectionReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads objects that are not cached by the manager, such as
+	// data source Secrets. Falls back to Client when nil.
+	APIReader client.Reader
+	// SecretNamespace is the only namespace data source Secrets are read
+	// from. Any namespace when empty.
+	SecretNamespace string
+	// Logs streams container logs for log-based templates.
+	Logs logtail.Source
+	// Alerts holds the alerts received from Alertmanager for alert-based
+	// templates. Nil when the receiver is disabled.
+	Alerts *alertmanager.Store
+	// Detectors evaluates templates. Defaults to NewDetectorRegistry.
+	Detectors *detector.Registry
+	// DryRun records the trigger of every anomaly without sending it, as
+	// spec.dryRun does for a single FaultDetection.
+	DryRun bool
+
+	detectorsOnce sync.Once
+	detectorsErr  error
+	logWatches    logWatches
 }
 
 //+kubebuilder:rbac:groups=detect.failure-recovery.io,resources=faultdetections,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=detect.failure-recovery.io,resources=faultdetections/status,verbs=update;patch
 //+kubebuilder:rbac:groups=detect.failure-recovery.io,resources=detectiontemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=detect.failure-recovery.io,resources=maintenancewindows,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="",namespace=system,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
 
 func (r *FaultDetectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -52,185 +104,212 @@ func (r *FaultDetectionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	// 1. Get FaultDetection CR
 	var fd detectv1.FaultDetection
 	if err := r.Get(ctx, req.NamespacedName, &fd); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		forgetFaultDetection(req.Namespace, req.Name)
+		r.logWatches.forget(req.NamespacedName)
+		// A detection deleted while anomalous would keep its incident open
+		return ctrl.Result{}, r.releaseIncidents(ctx, req.NamespacedName, metav1.Now())
 	}
 
-	// 2. Get DetectionTemplate
+	// 2. Get DetectionTemplate. A missing template is retried; the template
+	// watch also requeues this FaultDetection as soon as it is created.
 	var tmpl detectv1.DetectionTemplate
 	if err := r.Get(ctx, client.ObjectKey{Name: fd.Spec.TemplateRef}, &tmpl); err != nil {
-		logger.Error(err, "unable to fetch DetectionTemplate", "template", fd.Spec.TemplateRef)
+		// The client rejects an empty name without a NotFound error
+		if fd.Spec.TemplateRef != "" && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		logger.Info("DetectionTemplate not found, retrying", "template", fd.Spec.TemplateRef)
+		msg := fmt.Sprintf("DetectionTemplate %q not found", fd.Spec.TemplateRef)
+		if err := r.setTemplateCondition(ctx, &fd, metav1.ConditionFalse, reasonTemplateNotFound, msg); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: templateRetryInterval}, nil
+	}
+
+	// 2b. Substitute parameters and target variables into the template
+	rendered, err := renderTemplate(ctx, r.Client, &fd, &tmpl)
+	if err != nil {
+		logger.Error(err, "unable to render DetectionTemplate", "template", tmpl.Name)
+		msg := fmt.Sprintf("invalid template parameters: %v", err)
+		if err := r.setTemplateCondition(ctx, &fd, metav1.ConditionFalse, reasonInvalidParameters, msg); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
-	results := []detectv1.Result{}
-	anomaly := false
-	reason := ""
-
-	// reset NodeResults each reconcile
-	fd.Status.NodeResults = []detectv1.NodeResult{}
-
-	// --- Option B: API-based detection ---
-	if tmpl.Spec.APIVersion != "" && tmpl.Spec.Kind != "" && tmpl.Spec.FieldPath != "" {
-		if tmpl.Spec.Scope == "Node" && fd.Spec.Target.Name == "" {
-			var nodeList unstructured.UnstructuredList
-			nodeList.SetAPIVersion("v1")
-			nodeList.SetKind("NodeList")
-
-			if err := r.List(ctx, &nodeList); err != nil {
-				anomaly = true
-				reason = "Failed to list nodes"
-			} else {
-				for _, node := range nodeList.Items {
-					// Special handling for Node Ready
-					if tmpl.Spec.FieldPath == "status.conditions[Ready].status" {
-						conditions, found, _ := unstructured.NestedSlice(node.Object, "status", "conditions")
-						if !found {
-							anomaly = true
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       false,
-								Message:  "status.conditions not found",
-							})
-							continue
-						}
-
-						readyStatus := ""
-						for _, c := range conditions {
-							if cond, ok := c.(map[string]interface{}); ok {
-								if cond["type"] == "Ready" {
-									if s, ok := cond["status"].(string); ok {
-										readyStatus = s
-									}
-								}
-							}
-						}
-
-						if readyStatus == "" {
-							anomaly = true
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       false,
-								Message:  "Ready condition not found",
-							})
-						} else if readyStatus != tmpl.Spec.Expected {
-							anomaly = true
-							reason = fmt.Sprintf("Node %s Expected Ready=%s but got %s", node.GetName(), tmpl.Spec.Expected, readyStatus)
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       false,
-								Message:  reason,
-							})
-						} else {
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       true,
-								Message:  "Node Ready",
-							})
-						}
-					} else {
-						// fallback generic path
-						parts := strings.Split(tmpl.Spec.FieldPath, ".")
-						actual, found, _ := unstructured.NestedString(node.Object, parts...)
-						if !found {
-							anomaly = true
-							msg := fmt.Sprintf("Field %s not found", tmpl.Spec.FieldPath)
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       false,
-								Message:  msg,
-							})
-							continue
-						}
-						if actual != tmpl.Spec.Expected {
-							anomaly = true
-							msg := fmt.Sprintf("Expected %s=%s but got %s", tmpl.Spec.FieldPath, tmpl.Spec.Expected, actual)
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       false,
-								Message:  msg,
-							})
-						} else {
-							fd.Status.NodeResults = append(fd.Status.NodeResults, detectv1.NodeResult{
-								NodeName: node.GetName(),
-								Ok:       true,
-								Message:  "OK",
-							})
-						}
-					}
-				}
-			}
-		} else {
-			// Single target object
-			u := &unstructured.Unstructured{}
-			u.SetAPIVersion(tmpl.Spec.APIVersion)
-			u.SetKind(tmpl.Spec.Kind)
-
-			key := client.ObjectKey{
-				Name:      fd.Spec.Target.Name,
-				Namespace: fd.Spec.Target.Namespace,
-			}
-
-			if err := r.Get(ctx, key, u); err != nil {
-				anomaly = true
-				reason = "Target resource not found or unreachable"
-			} else {
-				parts := strings.Split(tmpl.Spec.FieldPath, ".")
-				actual, found, _ := unstructured.NestedString(u.Object, parts...)
-				if !found {
-					anomaly = true
-					reason = fmt.Sprintf("Field %s not found in resource", tmpl.Spec.FieldPath)
-				} else if actual != tmpl.Spec.Expected {
-					anomaly = true
-					reason = fmt.Sprintf("Expected %s=%s but got %s", tmpl.Spec.FieldPath, tmpl.Spec.Expected, actual)
-				}
-			}
+	// 2c. Check the rendered template with the detectors it configures
+	detectors, err := r.detectors()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := detectors.Validate(&rendered.Spec); err != nil {
+		logger.Error(err, "invalid DetectionTemplate", "template", tmpl.Name)
+		msg := fmt.Sprintf("invalid template: %v", err)
+		if err := r.setTemplateCondition(ctx, &fd, metav1.ConditionFalse, reasonInvalidTemplate, msg); err != nil {
+			return ctrl.Result{}, err
 		}
-		// --- Option A: Prometheus-based detection ---
-	} else if tmpl.Spec.PrometheusAPI != "" && len(tmpl.Spec.Queries) > 0 {
-		for _, q := range tmpl.Spec.Queries {
-			value, err := queryPrometheus(tmpl.Spec.PrometheusAPI, q.Query)
-			if err != nil {
-				logger.Error(err, "failed querying prometheus", "metric", q.Metric)
-				continue
-			}
-
-			results = append(results, detectv1.Result{
-				Metric: q.Metric,
-				Value:  fmt.Sprintf("%f", value),
-			})
-
-			if tmpl.Spec.Rule != "" && strings.Contains(tmpl.Spec.Rule, q.Metric) {
-				threshold := parseThreshold(tmpl.Spec.Rule)
-				if value > threshold {
-					anomaly = true
-					reason = fmt.Sprintf("metric %s value %f exceeded threshold %f", q.Metric, value, threshold)
-				}
-			}
+		return ctrl.Result{}, nil
+	}
+	meta.SetStatusCondition(&fd.Status.Conditions, metav1.Condition{
+		Type:               detectv1.ConditionTemplateAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonTemplateResolved,
+		ObservedGeneration: fd.Generation,
+	})
+	tmpl = *rendered
+	composite := tmpl.Spec.Composite
+
+	// 3. Source detector: composite, field, Prometheus, events, logs,
+	// probes, HTTP or alerts, whichever the template configures first
+	start := time.Now()
+	in := detector.Input{FaultDetection: &fd, Template: &tmpl, Now: start}
+	source := &detector.Outcome{}
+	if d := detectors.Source(&tmpl.Spec); d != nil {
+		out, err := d.Evaluate(ctx, in)
+		if err != nil {
+			logger.Error(err, "detector failed", "detector", d.Name())
+			out = &detector.Outcome{Results: []detectv1.Result{{Metric: d.Name(), Error: err.Error()}}}
 		}
+		source = out
 	}
-
-	// 4. Optional ML check
-	if tmpl.Spec.ML != nil && tmpl.Spec.ML.Endpoint != "" {
-		mlResult, err := callMLModel(tmpl.Spec.ML.Endpoint, results)
+	results := append([]detectv1.Result{}, source.Results...)
+	anomaly := source.Anomalous
+	reason := source.Reason
+	fd.Status.NodeResults = source.Targets.Nodes
+	fd.Status.EventResults = source.Targets.Events
+	fd.Status.LogResults = source.Targets.Logs
+	fd.Status.ProbeResults = source.Targets.Probes
+	fd.Status.AlertResults = source.Targets.Alerts
+
+	// 4. Analyzers, the statistical detectors and the ML model, on the
+	// source results
+	fd.Status.StatisticalResults = nil
+	fd.Status.MLResult = nil
+	in.Results, in.Series, in.Targets = results, source.Series, source.Targets
+	for _, d := range detectors.Analyzers(&tmpl.Spec) {
+		out, err := d.Evaluate(ctx, in)
 		if err != nil {
-			logger.Error(err, "failed calling ML model")
-		} else if mlResult {
+			logger.Error(err, "detector failed", "detector", d.Name())
+			results = append(results, detectv1.Result{Metric: d.Name(), Error: err.Error()})
+			continue
+		}
+		if out.Targets.Statistical != nil {
+			fd.Status.StatisticalResults = out.Targets.Statistical
+		}
+		if out.Targets.Baseline != "" {
+			fd.Status.BaselineRef = out.Targets.Baseline
+		}
+		if out.Targets.ML != nil {
+			fd.Status.MLResult = out.Targets.ML
+		}
+		if out.Anomalous && composite == nil {
 			anomaly = true
-			reason = "ML model detected anomaly"
+			reason = out.Reason
 		}
 	}
 
-	// 5. Update status
+	// 4b. Composite verdict
 	now := metav1.Now()
+	requeueAfter := tmpl.Spec.Interval.Duration
+	previousSubs := fd.Status.CompositeResults
+	fd.Status.CompositeResults = nil
+	if composite != nil {
+		compositeSubs := source.Targets.Composite
+		anomaly, reason, err = combineComposite(composite, compositeSubs, fd.Status.StatisticalResults,
+			fd.Status.MLResult, previousSubs, now)
+		if err != nil {
+			logger.Error(err, "detector failed", "detector", detectorComposite)
+			results = append(results, detectv1.Result{Metric: detectorComposite, Error: err.Error()})
+		}
+		fd.Status.CompositeResults = compositeSubs
+		if wait := compositeRequeue(composite, compositeSubs, now.Time); wait > 0 && wait < requeueAfter {
+			requeueAfter = wait
+		}
+	}
+
+	evaluationDuration.WithLabelValues(tmpl.Name).Observe(time.Since(start).Seconds())
+	anomalousTargets.WithLabelValues(tmpl.Name, fd.Namespace, fd.Name).Set(float64(countAnomalousTargets(&fd.Status, anomaly)))
+
+	// 5. Update status
 	fd.Status.LastRun = &now
 	fd.Status.Results = results
 	fd.Status.Anomalous = anomaly
 	fd.Status.Reason = reason
-
+	recordOccurrence(&fd.Status, anomaly, reason, results, now)
+
+	// Counters below count transitions into each outcome, not evaluations
+	wasTriggered, wasDryRun := fd.Status.Triggered, fd.Status.DryRun
+	wasSymptom, wasSilencedBy := fd.Status.Symptom, fd.Status.SilencedBy
+	fd.Status.Triggered = false
+	fd.Status.DryRun = false
+	fd.Status.TriggerMsg = ""
+	fd.Status.TriggerAPI = ""
+	fd.Status.TriggerPayload = ""
+
+	// 5b. Anomalies explained by an anomalous upstream object are symptoms.
+	// Without the topology they trigger like any other.
+	var rootCause *detectv1.RootCause
 	if anomaly {
+		if rootCause, err = r.rootCause(ctx, &fd); err != nil {
+			logger.Error(err, "unable to look up upstream anomalies")
+		}
+	}
+	fd.Status.Symptom = rootCause != nil
+	fd.Status.RootCause = rootCause
+
+	// 5c. Anomalies inside a maintenance window are silenced instead of triggering
+	window := ""
+	if anomaly && rootCause == nil {
+		if window, err = r.silencingWindow(ctx, &fd, tmpl.Name, now.Time); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	fd.Status.Silenced = window != ""
+	fd.Status.SilencedBy = window
+
+	switch {
+	case anomaly && rootCause != nil:
+		fd.Status.TriggerMsg = fmt.Sprintf("Symptom of %s %s, detected by FaultDetection %s/%s",
+			rootCause.Object.Kind, rootCause.Object.Name, rootCause.Namespace, rootCause.Name)
+		if !wasSymptom {
+			symptomsTotal.WithLabelValues(tmpl.Name).Inc()
+		}
+		logger.Info("Anomaly is a symptom of an upstream anomaly", "reason", reason, "rootCause", rootCause)
+	case anomaly && window != "":
+		fd.Status.TriggerMsg = fmt.Sprintf("Anomaly silenced by MaintenanceWindow %s", window)
+		if wasSilencedBy != window {
+			anomaliesSilencedTotal.WithLabelValues(tmpl.Name, window).Inc()
+		}
+		logger.Info("Anomaly silenced by maintenance window", "reason", reason, "window", window)
+	case anomaly && (r.DryRun || fd.Spec.DryRun):
+		fd.Status.DryRun = true
+		fd.Status.TriggerMsg = "Dry run: anomaly detected, trigger recorded but not sent"
+		fd.Status.TriggerAPI = tmpl.Spec.TriggerAPI
+		fd.Status.TriggerPayload = tmpl.Spec.TriggerPayload
+		if !wasDryRun {
+			dryRunTriggersTotal.WithLabelValues(tmpl.Name).Inc()
+		}
+		logger.Info("Anomaly detected in dry run, not triggering", "reason", reason, "nodes", fd.Status.NodeResults,
+			"triggerAPI", tmpl.Spec.TriggerAPI, "triggerPayload", tmpl.Spec.TriggerPayload)
+	case anomaly:
 		fd.Status.Triggered = true
 		fd.Status.TriggerMsg = "Anomaly detected - printing instead of triggering"
-		logger.Info("Anomaly detected!", "reason", reason, "nodes", fd.Status.NodeResults)
+		fd.Status.TriggerAPI = tmpl.Spec.TriggerAPI
+		fd.Status.TriggerPayload = tmpl.Spec.TriggerPayload
+		if !wasTriggered {
+			triggersFiredTotal.WithLabelValues(tmpl.Name).Inc()
+		}
+		logger.Info("Anomaly detected!", "reason", reason, "nodes", fd.Status.NodeResults,
+			"triggerAPI", tmpl.Spec.TriggerAPI, "triggerPayload", tmpl.Spec.TriggerPayload)
+	}
+
+	// 5d. Correlate the anomaly into an Incident; a failure is retried on
+	// the next evaluation
+	if err := r.trackIncident(ctx, &fd, tmpl.Name, anomaly, now); err != nil {
+		logger.Error(err, "unable to record incident")
 	}
 
 	if err := r.Status().Update(ctx, &fd); err != nil {
@@ -238,63 +317,185 @@ func (r *FaultDetectionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// 6. Requeue
-	return ctrl.Result{RequeueAfter: tmpl.Spec.Interval.Duration}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *FaultDetectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&detectv1.FaultDetection{}).
-		Complete(r)
+// detectors returns the registry to dispatch through, building the
+// built-in one on first use.
+func (r *FaultDetectionReconciler) detectors() (*detector.Registry, error) {
+	r.detectorsOnce.Do(func() {
+		if r.Detectors == nil {
+			r.Detectors, r.detectorsErr = NewDetectorRegistry(r)
+		}
+	})
+	return r.Detectors, r.detectorsErr
 }
 
-// -------------------- Helper Functions --------------------
+// apiReader returns the uncached reader, or the cached client if none is set.
+func (r *FaultDetectionReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
 
-func queryPrometheus(api, query string) (float64, error) {
-	url := fmt.Sprintf("%s/api/v1/query?query=%s", api, query)
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, err
+// IndexFaultDetections registers the FaultDetection indexes the reconciler
+// lists by: by template, so that creating or changing a template
+// re-evaluates every FaultDetection that uses it, and by target, to find the
+// detections of upstream objects.
+func IndexFaultDetections(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &detectv1.FaultDetection{}, templateRefIndex,
+		func(obj client.Object) []string {
+			return []string{obj.(*detectv1.FaultDetection).Spec.TemplateRef}
+		}); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
+	return indexer.IndexField(ctx, &detectv1.FaultDetection{}, targetIndex, faultDetectionTarget)
+}
 
-	body, _ := ioutil.ReadAll(resp.Body)
-	var data map[string]interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
-		return 0, err
+// SetupWithManager sets up the controller with the Manager. Matching Events
+// re-evaluate event-based FaultDetections right away, and alerts received
+// from Alertmanager alert-based ones.
+func (r *FaultDetectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := IndexFaultDetections(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
 	}
 
-	results := data["data"].(map[string]interface{})["result"].([]interface{})
-	if len(results) == 0 {
-		return 0, fmt.Errorf("no data returned for query %s", query)
+	b := ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not retrigger evaluation; the interval does
+		For(&detectv1.FaultDetection{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&detectv1.DetectionTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.faultDetectionsForTemplate),
+			builder.WithPredicates(predicate.Or[client.Object](predicate.GenerationChangedPredicate{}, modelStatusChanged))).
+		Watches(&corev1.Event{},
+			handler.EnqueueRequestsFromMapFunc(r.faultDetectionsForEvent),
+			builder.WithPredicates(eventRecorded))
+	if r.Alerts != nil {
+		b = b.WatchesRawSource(source.Channel(r.Alerts.Events(),
+			handler.EnqueueRequestsFromMapFunc(r.faultDetectionsForAlert)))
 	}
-	valStr := results[0].(map[string]interface{})["value"].([]interface{})[1].(string)
-	return strconv.ParseFloat(valStr, 64)
+	return b.Complete(r)
 }
 
-func parseThreshold(rule string) float64 {
-	parts := strings.Split(rule, ">")
-	if len(parts) != 2 {
-		return 0
+// faultDetectionsForTemplate maps a DetectionTemplate to the FaultDetections
+// that reference it.
+func (r *FaultDetectionReconciler) faultDetectionsForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list detectv1.FaultDetectionList
+	if err := r.List(ctx, &list, client.MatchingFields{templateRefIndex: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list FaultDetections for template", "template", obj.GetName())
+		return nil
 	}
-	val, _ := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-	return val
+	reqs := make([]reconcile.Request, len(list.Items))
+	for i, fd := range list.Items {
+		reqs[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&fd)}
+	}
+	return reqs
 }
 
-func callMLModel(endpoint string, results []detectv1.Result) (bool, error) {
-	jsonBody, _ := json.Marshal(results)
-	resp, err := http.Post(endpoint, "application/json", strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return false, err
+// modelStatusChanged passes template updates that change the model server
+// endpoint, which only shows in status.
+var modelStatusChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldT, ok1 := e.ObjectOld.(*detectv1.DetectionTemplate)
+		newT, ok2 := e.ObjectNew.(*detectv1.DetectionTemplate)
+		if !ok1 || !ok2 {
+			return false
+		}
+		return oldT.Status.ModelReady != newT.Status.ModelReady || oldT.Status.ModelEndpoint != newT.Status.ModelEndpoint
+	},
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// setTemplateCondition records why the template cannot be used and saves status.
+func (r *FaultDetectionReconciler) setTemplateCondition(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	status metav1.ConditionStatus,
+	reason, msg string,
+) error {
+	changed := meta.SetStatusCondition(&fd.Status.Conditions, metav1.Condition{
+		Type:               detectv1.ConditionTemplateAvailable,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: fd.Generation,
+	})
+	if !changed && fd.Status.Reason == msg {
+		return nil
 	}
-	defer resp.Body.Close()
+	fd.Status.Reason = msg
+	return r.Status().Update(ctx, fd)
+}
 
-	body, _ := ioutil.ReadAll(resp.Body)
-	var respData map[string]interface{}
-	if err := json.Unmarshal(body, &respData); err != nil {
-		return false, err
+// -------------------- Helper Functions --------------------
+
+// countAnomalousTargets returns how many targets are unhealthy. Node-wide
+// checks report per node, event checks per involved object, log checks per
+// pod and probes per endpoint; every other check has a single target.
+func countAnomalousTargets(status *detectv1.FaultDetectionStatus, anomaly bool) int {
+	if len(status.NodeResults) == 0 && len(status.EventResults) == 0 &&
+		len(status.LogResults) == 0 && len(status.ProbeResults) == 0 && len(status.AlertResults) == 0 {
+		if anomaly {
+			return 1
+		}
+		return 0
+	}
+	n := 0
+	for _, nr := range status.NodeResults {
+		if !nr.Ok {
+			n++
+		}
+	}
+	for _, er := range status.EventResults {
+		if er.Anomalous {
+			n++
+		}
+	}
+	pods := map[string]bool{}
+	for _, lr := range status.LogResults {
+		if lr.Anomalous && !pods[lr.Pod] {
+			pods[lr.Pod] = true
+			n++
+		}
+	}
+	endpoints := map[string]bool{}
+	for _, pr := range status.ProbeResults {
+		if !pr.Success && !endpoints[pr.Endpoint] {
+			endpoints[pr.Endpoint] = true
+			n++
+		}
+	}
+	targets := map[detectv1.ObjectRef]bool{}
+	for _, ar := range status.AlertResults {
+		if !targets[ar.Target] {
+			targets[ar.Target] = true
+			n++
+		}
 	}
-	if anomaly, ok := respData["anomaly"].(bool); ok {
-		return anomaly, nil
+	return n
+}
+
+// recordOccurrence opens an occurrence when the target turns anomalous and
+// closes it when it recovers. Only the newest maxOccurrences are kept.
+func recordOccurrence(status *detectv1.FaultDetectionStatus, anomaly bool, reason string, results []detectv1.Result, now metav1.Time) {
+	var open *detectv1.AnomalyOccurrence
+	if n := len(status.Occurrences); n > 0 && status.Occurrences[n-1].EndTime == nil {
+		open = &status.Occurrences[n-1]
+	}
+	switch {
+	case anomaly && open == nil:
+		status.Occurrences = append(status.Occurrences, detectv1.AnomalyOccurrence{
+			ID:        strconv.FormatInt(now.Unix(), 10),
+			StartTime: now,
+			Reason:    reason,
+			Results:   results,
+		})
+		if n := len(status.Occurrences); n > maxOccurrences {
+			status.Occurrences = status.Occurrences[n-maxOccurrences:]
+		}
+	case !anomaly && open != nil:
+		open.EndTime = &now
 	}
-	return false, nil
 }
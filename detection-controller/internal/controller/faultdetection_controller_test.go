@@ -18,10 +18,12 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -81,4 +83,53 @@ var _ = Describe("FaultDetection Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When the template does not exist", func() {
+		const (
+			fdName       = "missing-template"
+			templateName = "created-later"
+		)
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: fdName, Namespace: "default"}
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, &detectv1alpha1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"}})
+			_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: templateName}})
+		})
+
+		It("reports the missing template and recovers once it is created", func() {
+			Expect(k8sClient.Create(ctx, &detectv1alpha1.FaultDetection{
+				ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"},
+				Spec:       detectv1alpha1.FaultDetectionSpec{TemplateRef: templateName},
+			})).To(Succeed())
+
+			r := &FaultDetectionReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(templateRetryInterval))
+
+			fd := &detectv1alpha1.FaultDetection{}
+			Expect(k8sClient.Get(ctx, key, fd)).To(Succeed())
+			cond := meta.FindStatusCondition(fd.Status.Conditions, detectv1alpha1.ConditionTemplateAvailable)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(reasonTemplateNotFound))
+
+			By("creating the template")
+			Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: templateName},
+				Spec: detectv1alpha1.DetectionTemplateSpec{
+					Scope:    detectv1alpha1.ScopeCluster,
+					Interval: metav1.Duration{Duration: time.Minute},
+				},
+			})).To(Succeed())
+			res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+
+			Expect(k8sClient.Get(ctx, key, fd)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(fd.Status.Conditions, detectv1alpha1.ConditionTemplateAvailable)).To(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/detector"
+)
+
+// nodeReadyPath is the field path checked with the special Node Ready handling.
+const nodeReadyPath = "status.conditions[Ready].status"
+
+// fieldDetector compares a field of the target object, or of every Node,
+// with the expected value (Option B).
+type fieldDetector struct {
+	reader client.Reader
+}
+
+func (d *fieldDetector) Name() string        { return subDetectorField }
+func (d *fieldDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *fieldDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.APIVersion != "" && spec.Kind != "" && spec.FieldPath != ""
+}
+
+func (d *fieldDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	for _, part := range strings.Split(spec.FieldPath, ".") {
+		if part == "" {
+			return fmt.Errorf("fieldPath %q has an empty segment", spec.FieldPath)
+		}
+	}
+	return nil
+}
+
+func (d *fieldDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	spec := &in.Template.Spec
+	// An unset target is treated like an empty one
+	target := in.FaultDetection.Spec.Target
+	if target == nil {
+		target = &detectv1.ObjectRef{}
+	}
+	if spec.Scope == "Node" && target.Name == "" {
+		return d.evaluateNodes(ctx, in.Template)
+	}
+
+	// Single target object
+	out := &detector.Outcome{}
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(spec.APIVersion)
+	u.SetKind(spec.Kind)
+	key := client.ObjectKey{Name: target.Name, Namespace: target.Namespace}
+
+	if key.Name == "" {
+		out.Anomalous = true
+		out.Reason = "FaultDetection has no target"
+	} else if err := d.reader.Get(ctx, key, u); err != nil {
+		if !apierrors.IsNotFound(err) {
+			dataSourceErrorsTotal.WithLabelValues(in.Template.Name, sourceKubernetes).Inc()
+		}
+		out.Anomalous = true
+		out.Reason = "Target resource not found or unreachable"
+	} else {
+		parts := strings.Split(spec.FieldPath, ".")
+		actual, found, _ := unstructured.NestedString(u.Object, parts...)
+		if !found {
+			out.Anomalous = true
+			out.Reason = fmt.Sprintf("Field %s not found in resource", spec.FieldPath)
+		} else if actual != spec.Expected {
+			out.Anomalous = true
+			out.Reason = fmt.Sprintf("Expected %s=%s but got %s", spec.FieldPath, spec.Expected, actual)
+		}
+	}
+	return out, nil
+}
+
+// evaluateNodes checks the field on every Node and reports each of them.
+func (d *fieldDetector) evaluateNodes(ctx context.Context, tmpl *detectv1.DetectionTemplate) (*detector.Outcome, error) {
+	spec := &tmpl.Spec
+	out := &detector.Outcome{}
+	var nodeList unstructured.UnstructuredList
+	nodeList.SetAPIVersion("v1")
+	nodeList.SetKind("NodeList")
+
+	if err := d.reader.List(ctx, &nodeList); err != nil {
+		dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourceKubernetes).Inc()
+		out.Anomalous = true
+		out.Reason = "Failed to list nodes"
+		return out, nil
+	}
+
+	fail := func(node, msg string) {
+		out.Anomalous = true
+		out.Targets.Nodes = append(out.Targets.Nodes, detectv1.NodeResult{NodeName: node, Ok: false, Message: msg})
+	}
+	for _, node := range nodeList.Items {
+		// Special handling for Node Ready
+		if spec.FieldPath == nodeReadyPath {
+			conditions, found, _ := unstructured.NestedSlice(node.Object, "status", "conditions")
+			if !found {
+				fail(node.GetName(), "status.conditions not found")
+				continue
+			}
+
+			readyStatus := ""
+			for _, c := range conditions {
+				if cond, ok := c.(map[string]interface{}); ok && cond["type"] == "Ready" {
+					if s, ok := cond["status"].(string); ok {
+						readyStatus = s
+					}
+				}
+			}
+
+			switch {
+			case readyStatus == "":
+				fail(node.GetName(), "Ready condition not found")
+			case readyStatus != spec.Expected:
+				out.Reason = fmt.Sprintf("Node %s Expected Ready=%s but got %s", node.GetName(), spec.Expected, readyStatus)
+				fail(node.GetName(), out.Reason)
+			default:
+				out.Targets.Nodes = append(out.Targets.Nodes, detectv1.NodeResult{
+					NodeName: node.GetName(),
+					Ok:       true,
+					Message:  "Node Ready",
+				})
+			}
+			continue
+		}
+
+		// fallback generic path
+		parts := strings.Split(spec.FieldPath, ".")
+		actual, found, _ := unstructured.NestedString(node.Object, parts...)
+		switch {
+		case !found:
+			fail(node.GetName(), fmt.Sprintf("Field %s not found", spec.FieldPath))
+		case actual != spec.Expected:
+			fail(node.GetName(), fmt.Sprintf("Expected %s=%s but got %s", spec.FieldPath, spec.Expected, actual))
+		default:
+			out.Targets.Nodes = append(out.Targets.Nodes, detectv1.NodeResult{
+				NodeName: node.GetName(),
+				Ok:       true,
+				Message:  "OK",
+			})
+		}
+	}
+	return out, nil
+}
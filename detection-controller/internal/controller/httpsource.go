@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/jsonsource"
+)
+
+// evaluateHTTPSource polls the template's JSON endpoint and extracts one
+// Result per value. values holds the numbers for the rule; reason is set
+// when a value differs from its expected string.
+func (r *FaultDetectionReconciler) evaluateHTTPSource(
+	ctx context.Context,
+	tmpl *detectv1.DetectionTemplate,
+) (results []detectv1.Result, values map[string]float64, reason string) {
+	logger := log.FromContext(ctx)
+	spec := tmpl.Spec.HTTP
+
+	doc, err := r.fetchJSON(ctx, spec)
+	if err != nil {
+		// The endpoint is unusable; report it on every value
+		dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourceHTTP).Inc()
+		logger.Error(err, "failed polling http source", "url", spec.URL)
+		for _, v := range spec.Values {
+			results = append(results, detectv1.Result{Metric: v.Metric, Error: err.Error()})
+		}
+		return results, nil, ""
+	}
+
+	values = map[string]float64{}
+	for _, v := range spec.Values {
+		raw, err := jsonsource.Extract(doc, v.JSONPath)
+		if err != nil {
+			dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourceHTTP).Inc()
+			results = append(results, detectv1.Result{Metric: v.Metric, Error: err.Error()})
+			continue
+		}
+
+		if v.Expected != "" {
+			actual := jsonsource.String(raw)
+			value := 0.0
+			if actual != v.Expected {
+				value = 1
+				if reason == "" {
+					reason = fmt.Sprintf("Expected %s=%s but got %s", v.JSONPath, v.Expected, actual)
+				}
+			}
+			values[v.Metric] = value
+			results = append(results, detectv1.Result{Metric: v.Metric, Value: fmt.Sprintf("%f", value)})
+			continue
+		}
+
+		value, err := jsonsource.Number(raw)
+		if err != nil {
+			results = append(results, detectv1.Result{Metric: v.Metric, Error: err.Error()})
+			continue
+		}
+		values[v.Metric] = value
+		results = append(results, detectv1.Result{Metric: v.Metric, Value: fmt.Sprintf("%f", value)})
+	}
+	return results, values, reason
+}
+
+func (r *FaultDetectionReconciler) fetchJSON(ctx context.Context, spec *detectv1.HTTPSourceSpec) (interface{}, error) {
+	httpClient, err := newHTTPClient(ctx, r.apiReader(), r.SecretNamespace, spec.Client)
+	if err != nil {
+		return nil, err
+	}
+	return jsonsource.Fetch(ctx, httpClient, jsonsource.Request{URL: spec.URL, Method: spec.Method, Body: spec.Body})
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestEvaluateHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"DOWN","queue":{"depth":17},"checks":[{"name":"db","latencyMs":"12.5"}]}`))
+	}))
+	defer srv.Close()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "status-auth"},
+			Data:       map[string][]byte{secretKeyToken: []byte("s3cret")},
+		}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c, SecretNamespace: "shop"}
+
+	tmpl := &detectv1.DetectionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-status"},
+		Spec: detectv1.DetectionTemplateSpec{HTTP: &detectv1.HTTPSourceSpec{
+			URL: srv.URL + "/status",
+			Values: []detectv1.JSONValue{
+				{Metric: "queue_depth", JSONPath: ".queue.depth"},
+				{Metric: "db_latency", JSONPath: "{.checks[?(@.name=='db')].latencyMs}"},
+				{Metric: "up", JSONPath: ".status", Expected: "UP"},
+				{Metric: "missing", JSONPath: ".nope"},
+			},
+			Client: &detectv1.HTTPClientConfig{AuthSecretRef: &detectv1.SecretRef{Namespace: "shop", Name: "status-auth"}},
+		}},
+	}
+
+	results, values, reason := r.evaluateHTTPSource(context.Background(), tmpl)
+	if len(results) != 4 {
+		t.Fatalf("results = %+v, want one per value", results)
+	}
+	if values["queue_depth"] != 17 || values["db_latency"] != 12.5 || values["up"] != 1 {
+		t.Errorf("values = %v", values)
+	}
+	if results[3].Metric != "missing" || results[3].Error == "" {
+		t.Errorf("results[3] = %+v, want an extraction error", results[3])
+	}
+	if reason != "Expected .status=UP but got DOWN" {
+		t.Errorf("reason = %q", reason)
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		tmpl := tmpl.DeepCopy()
+		tmpl.Spec.HTTP.Client = nil
+		results, values, reason := r.evaluateHTTPSource(context.Background(), tmpl)
+		if len(values) != 0 || reason != "" {
+			t.Errorf("values = %v, reason = %q; want none", values, reason)
+		}
+		for _, res := range results {
+			if !strings.Contains(res.Error, "unexpected status 401") {
+				t.Errorf("result = %+v, want the status error on every value", res)
+			}
+		}
+	})
+
+	t.Run("secret outside the controller namespace", func(t *testing.T) {
+		r := &FaultDetectionReconciler{Client: c, SecretNamespace: "detection-controller-system"}
+		results, values, _ := r.evaluateHTTPSource(context.Background(), tmpl)
+		if len(values) != 0 {
+			t.Errorf("values = %v, want none", values)
+		}
+		for _, res := range results {
+			if !strings.Contains(res.Error, "outside the controller namespace") {
+				t.Errorf("result = %+v, want the Secret refused", res)
+			}
+		}
+	})
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/params"
+)
+
+const (
+	// incidentCorrelationWindow is how long after an incident was detected
+	// anomalies on the same node join it.
+	incidentCorrelationWindow = 5 * time.Minute
+	// maxIncidentTimeline is how many timeline entries an Incident keeps.
+	maxIncidentTimeline = 100
+	// maxIncidentNamePrefix keeps generated Incident names within the
+	// object name limit.
+	maxIncidentNamePrefix = 200
+)
+
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=incidents,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=incidents/status,verbs=get;update;patch
+
+// trackIncident records fd in an Incident: it joins a correlated incident or
+// opens one when fd turns anomalous, and marks fd recovered when it no
+// longer is. fd.Status.Incident names the incident of the current anomaly.
+func (r *FaultDetectionReconciler) trackIncident(ctx context.Context, fd *detectv1.FaultDetection, template string, anomaly bool, now metav1.Time) error {
+	switch {
+	case anomaly && fd.Status.Incident == "":
+		return r.joinIncident(ctx, fd, template, now)
+	case !anomaly && fd.Status.Incident != "":
+		return r.leaveIncident(ctx, fd, now)
+	}
+	return nil
+}
+
+// joinIncident adds the anomaly of fd to a correlated incident, or opens one.
+func (r *FaultDetectionReconciler) joinIncident(ctx context.Context, fd *detectv1.FaultDetection, template string, now metav1.Time) error {
+	var vars params.Vars
+	if fd.Spec.Target != nil {
+		targetVars(ctx, r.Client, fd.Spec.Target, &vars)
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		inc, err := r.correlatedIncident(ctx, fd, vars.Node, now.Time)
+		if err != nil {
+			return err
+		}
+		if inc == nil {
+			prefix := fd.Namespace + "-" + fd.Name
+			if len(prefix) > maxIncidentNamePrefix {
+				prefix = prefix[:maxIncidentNamePrefix]
+			}
+			inc = &detectv1.Incident{
+				ObjectMeta: metav1.ObjectMeta{GenerateName: prefix + "-"},
+				Spec:       detectv1.IncidentSpec{Root: incidentRoot(fd), Node: vars.Node},
+			}
+			if err := r.Create(ctx, inc); err != nil {
+				return err
+			}
+			inc.Status.Phase = detectv1.IncidentOpen
+		}
+		addIncidentDetection(&inc.Status, fd, template, now)
+		if err := r.Status().Update(ctx, inc); err != nil {
+			return err
+		}
+		fd.Status.Incident = inc.Name
+		return nil
+	})
+}
+
+// correlatedIncident returns the unresolved incident the anomaly of fd
+// belongs to: the incident of its root cause, one opened for the same
+// object, or a recent one on the same node. Incidents are read uncached so
+// that detections evaluated in quick succession find each other's.
+func (r *FaultDetectionReconciler) correlatedIncident(ctx context.Context, fd *detectv1.FaultDetection, node string, now time.Time) (*detectv1.Incident, error) {
+	reader := r.apiReader()
+	if rc := fd.Status.RootCause; rc != nil {
+		var root detectv1.FaultDetection
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: rc.Namespace, Name: rc.Name}, &root); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+		} else if root.Status.Incident != "" {
+			var inc detectv1.Incident
+			if err := reader.Get(ctx, client.ObjectKey{Name: root.Status.Incident}, &inc); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+			} else if inc.Status.Phase != detectv1.IncidentResolved {
+				return &inc, nil
+			}
+		}
+	}
+
+	var list detectv1.IncidentList
+	if err := reader.List(ctx, &list); err != nil {
+		return nil, err
+	}
+	// Newest first
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[j].CreationTimestamp.Before(&list.Items[i].CreationTimestamp)
+	})
+	root := incidentRoot(fd)
+	for i := range list.Items {
+		inc := &list.Items[i]
+		if inc.Status.Phase == detectv1.IncidentResolved {
+			continue
+		}
+		if sameObject(inc.Spec.Root, root) {
+			return inc, nil
+		}
+		if node != "" && inc.Spec.Node == node && inc.Status.DetectedAt != nil &&
+			now.Sub(inc.Status.DetectedAt.Time) <= incidentCorrelationWindow {
+			return inc, nil
+		}
+	}
+	return nil, nil
+}
+
+// leaveIncident marks fd recovered in its incident, and resolves the
+// incident once every detection has recovered.
+func (r *FaultDetectionReconciler) leaveIncident(ctx context.Context, fd *detectv1.FaultDetection, now metav1.Time) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var inc detectv1.Incident
+		if err := r.apiReader().Get(ctx, client.ObjectKey{Name: fd.Status.Incident}, &inc); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !recoverIncidentDetection(&inc.Status, fd.Namespace, fd.Name, "Recovered", now) {
+			return nil
+		}
+		return r.Status().Update(ctx, &inc)
+	})
+	if err != nil {
+		return err
+	}
+	fd.Status.Incident = ""
+	return nil
+}
+
+// releaseIncidents closes the open detections of a deleted FaultDetection,
+// so that its incidents still resolve.
+func (r *FaultDetectionReconciler) releaseIncidents(ctx context.Context, key types.NamespacedName, now metav1.Time) error {
+	var list detectv1.IncidentList
+	if err := r.apiReader().List(ctx, &list); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if list.Items[i].Status.Phase == detectv1.IncidentResolved {
+			continue
+		}
+		name := list.Items[i].Name
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			var inc detectv1.Incident
+			if err := r.apiReader().Get(ctx, client.ObjectKey{Name: name}, &inc); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			if !recoverIncidentDetection(&inc.Status, key.Namespace, key.Name, "Deleted", now) {
+				return nil
+			}
+			return r.Status().Update(ctx, &inc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// incidentRoot is the object an incident opened by fd is about.
+func incidentRoot(fd *detectv1.FaultDetection) detectv1.ObjectRef {
+	if fd.Spec.Target != nil {
+		return fd.Spec.Target.WithDefaultNamespace(fd.Namespace)
+	}
+	return detectv1.ObjectRef{
+		APIVersion: detectv1.GroupVersion.String(),
+		Kind:       "FaultDetection",
+		Namespace:  fd.Namespace,
+		Name:       fd.Name,
+	}
+}
+
+// addIncidentDetection records the anomaly of fd and updates the detection
+// times.
+func addIncidentDetection(st *detectv1.IncidentStatus, fd *detectv1.FaultDetection, template string, now metav1.Time) {
+	st.Detections = append(st.Detections, detectv1.IncidentDetection{
+		Namespace:  fd.Namespace,
+		Name:       fd.Name,
+		Template:   template,
+		Target:     fd.Spec.Target.DeepCopy(),
+		Symptom:    fd.Status.Symptom,
+		Reason:     fd.Status.Reason,
+		DetectedAt: now,
+	})
+
+	onset := now
+	for _, ar := range fd.Status.AlertResults {
+		if ar.StartsAt.Before(&onset) {
+			onset = ar.StartsAt
+		}
+	}
+	if st.DetectedAt == nil || now.Before(st.DetectedAt) {
+		st.DetectedAt = now.DeepCopy()
+	}
+	if st.OnsetTime == nil || onset.Before(st.OnsetTime) {
+		st.OnsetTime = onset.DeepCopy()
+	}
+	st.TimeToDetect = &metav1.Duration{Duration: st.DetectedAt.Sub(st.OnsetTime.Time)}
+
+	msg := "Anomaly detected: " + fd.Status.Reason
+	if fd.Status.Symptom {
+		msg = "Symptom detected: " + fd.Status.Reason
+	}
+	appendIncidentEvent(st, detectv1.IncidentEvent{
+		Time:    now,
+		Source:  detectv1.IncidentSourceDetection,
+		Object:  "FaultDetection/" + fd.Namespace + "/" + fd.Name,
+		Message: msg,
+	})
+}
+
+// recoverIncidentDetection marks the open detections of the FaultDetection
+// namespace/name recovered, recording message in the timeline, and resolves
+// the incident when none is left. It reports whether anything changed.
+func recoverIncidentDetection(st *detectv1.IncidentStatus, namespace, name, message string, now metav1.Time) bool {
+	changed := false
+	open := 0
+	for i := range st.Detections {
+		d := &st.Detections[i]
+		if d.RecoveredAt == nil && d.Namespace == namespace && d.Name == name {
+			d.RecoveredAt = now.DeepCopy()
+			changed = true
+		}
+		if d.RecoveredAt == nil {
+			open++
+		}
+	}
+	if !changed {
+		return false
+	}
+	appendIncidentEvent(st, detectv1.IncidentEvent{
+		Time:    now,
+		Source:  detectv1.IncidentSourceDetection,
+		Object:  "FaultDetection/" + namespace + "/" + name,
+		Message: message,
+	})
+	if open == 0 && st.Phase != detectv1.IncidentResolved {
+		st.Phase = detectv1.IncidentResolved
+		st.ResolvedAt = now.DeepCopy()
+		if st.DetectedAt != nil {
+			st.TimeToRecover = &metav1.Duration{Duration: now.Sub(st.DetectedAt.Time)}
+		}
+	}
+	return true
+}
+
+// appendIncidentEvent adds e to the timeline, dropping the oldest entries
+// beyond maxIncidentTimeline.
+func appendIncidentEvent(st *detectv1.IncidentStatus, e detectv1.IncidentEvent) {
+	st.Timeline = append(st.Timeline, e)
+	if n := len(st.Timeline); n > maxIncidentTimeline {
+		st.Timeline = st.Timeline[n-maxIncidentTimeline:]
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// IncidentReconciler deletes resolved Incidents once Retention has passed.
+// Correlating an anomaly lists every Incident, so they must not pile up.
+type IncidentReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// How long resolved Incidents are kept; zero keeps them
+	Retention time.Duration
+}
+
+// Reconcile deletes the Incident when it expired, or requeues when it will.
+func (r *IncidentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var inc detectv1.Incident
+	if err := r.Get(ctx, req.NamespacedName, &inc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if r.Retention <= 0 || inc.Status.Phase != detectv1.IncidentResolved || inc.Status.ResolvedAt == nil {
+		return ctrl.Result{}, nil
+	}
+	if left := time.Until(inc.Status.ResolvedAt.Add(r.Retention)); left > 0 {
+		return ctrl.Result{RequeueAfter: left}, nil
+	}
+	log.FromContext(ctx).Info("Deleting resolved Incident", "resolvedAt", inc.Status.ResolvedAt.Time)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, &inc))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IncidentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&detectv1.Incident{}).
+		Named("incident").
+		Complete(r)
+}
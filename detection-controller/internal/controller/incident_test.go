@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestTrackIncident(t *testing.T) {
+	ctx := context.Background()
+	pod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	fd := func(ns, name string, target *detectv1.ObjectRef) *detectv1.FaultDetection {
+		return &detectv1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       detectv1.FaultDetectionSpec{TemplateRef: "t", Target: target},
+		}
+	}
+	podRef := func(name string) *detectv1.ObjectRef {
+		return &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: name}
+	}
+	node := fd("ops", "worker-1", &detectv1.ObjectRef{Kind: "Node", Name: "worker-1"})
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(pod("checkout-0", "worker-1"), pod("checkout-1", "worker-1"), pod("invoice-0", "worker-2"), node).
+		WithStatusSubresource(&detectv1.FaultDetection{}, &detectv1.Incident{}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	start := metav1.NewTime(time.Now().Truncate(time.Second))
+	track := func(fd *detectv1.FaultDetection, anomaly bool, at time.Duration) {
+		t.Helper()
+		fd.Status.Anomalous = anomaly
+		fd.Status.Reason = "not ready"
+		if err := r.trackIncident(ctx, fd, "t", anomaly, metav1.NewTime(start.Add(at))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	incident := func(name string) *detectv1.Incident {
+		t.Helper()
+		var inc detectv1.Incident
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, &inc); err != nil {
+			t.Fatal(err)
+		}
+		return &inc
+	}
+
+	// The node goes NotReady; its detection is saved so symptoms can follow
+	// the root cause to its incident
+	track(node, true, 0)
+	if err := c.Status().Update(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	inc := incident(node.Status.Incident)
+	if inc.Spec.Root.Kind != "Node" || inc.Spec.Node != "worker-1" || inc.Status.Phase != detectv1.IncidentOpen {
+		t.Fatalf("incident = %+v", inc)
+	}
+
+	// A symptom joins through its root cause, and another pod on the node
+	// by time and node
+	symptom := fd("shop", "checkout-0", podRef("checkout-0"))
+	symptom.Status.Symptom = true
+	symptom.Status.RootCause = &detectv1.RootCause{Namespace: "ops", Name: "worker-1"}
+	track(symptom, true, time.Minute)
+	sameNode := fd("shop", "checkout-1", podRef("checkout-1"))
+	track(sameNode, true, 2*time.Minute)
+	otherNode := fd("shop", "invoice-0", podRef("invoice-0"))
+	track(otherNode, true, 2*time.Minute)
+	if symptom.Status.Incident != inc.Name || sameNode.Status.Incident != inc.Name {
+		t.Errorf("incidents = %q, %q; want both in %q", symptom.Status.Incident, sameNode.Status.Incident, inc.Name)
+	}
+	if otherNode.Status.Incident == "" || otherNode.Status.Incident == inc.Name {
+		t.Errorf("incident = %q, want a separate one for the other node", otherNode.Status.Incident)
+	}
+
+	// Resolved once every detection has recovered
+	track(node, false, 5*time.Minute)
+	track(symptom, false, 5*time.Minute)
+	if inc := incident(inc.Name); inc.Status.Phase != detectv1.IncidentOpen {
+		t.Errorf("phase = %s, want open while checkout-1 is anomalous", inc.Status.Phase)
+	}
+	track(sameNode, false, 10*time.Minute)
+	inc = incident(inc.Name)
+	if inc.Status.Phase != detectv1.IncidentResolved || inc.Status.TimeToRecover == nil ||
+		inc.Status.TimeToRecover.Duration != 10*time.Minute {
+		t.Errorf("status = %+v, want resolved after 10m", inc.Status)
+	}
+	if len(inc.Status.Detections) != 3 || len(inc.Status.Timeline) != 6 {
+		t.Errorf("detections = %d, timeline = %d; want 3 and 6", len(inc.Status.Detections), len(inc.Status.Timeline))
+	}
+	if node.Status.Incident != "" {
+		t.Errorf("incident = %q, want cleared after recovery", node.Status.Incident)
+	}
+}
+
+func TestReleaseIncidentsOfDeletedDetection(t *testing.T) {
+	ctx := context.Background()
+	detected := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	inc := &detectv1.Incident{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop-checkout-0-x1"},
+		Status: detectv1.IncidentStatus{
+			Phase:      detectv1.IncidentOpen,
+			DetectedAt: &detected,
+			Detections: []detectv1.IncidentDetection{{Namespace: "shop", Name: "checkout-0", DetectedAt: detected}},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(inc).
+		WithStatusSubresource(&detectv1.Incident{}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	key := client.ObjectKey{Namespace: "shop", Name: "checkout-0"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(inc), inc); err != nil {
+		t.Fatal(err)
+	}
+	if inc.Status.Phase != detectv1.IncidentResolved || inc.Status.TimeToRecover == nil ||
+		len(inc.Status.Timeline) != 1 || inc.Status.Timeline[0].Message != "Deleted" {
+		t.Errorf("status = %+v, want resolved by the deletion", inc.Status)
+	}
+}
+
+func TestIncidentRetention(t *testing.T) {
+	ctx := context.Background()
+	incident := func(name string, phase detectv1.IncidentPhase, resolved time.Duration) *detectv1.Incident {
+		inc := &detectv1.Incident{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     detectv1.IncidentStatus{Phase: phase},
+		}
+		if phase == detectv1.IncidentResolved {
+			at := metav1.NewTime(time.Now().Add(-resolved))
+			inc.Status.ResolvedAt = &at
+		}
+		return inc
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(
+			incident("expired", detectv1.IncidentResolved, 48*time.Hour),
+			incident("recent", detectv1.IncidentResolved, time.Hour),
+			incident("open", detectv1.IncidentOpen, 0),
+		).
+		Build()
+	r := &IncidentReconciler{Client: c, Retention: 24 * time.Hour}
+
+	for name, wantRequeue := range map[string]bool{"expired": false, "recent": true, "open": false} {
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (res.RequeueAfter > 0) != wantRequeue {
+			t.Errorf("%s: requeue after %s", name, res.RequeueAfter)
+		}
+	}
+	var list detectv1.IncidentList
+	if err := c.List(ctx, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("incidents = %d, want the expired one deleted", len(list.Items))
+	}
+}
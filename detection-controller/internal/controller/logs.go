@@ -0,0 +1,275 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/logtail"
+)
+
+const (
+	// defaultLogWindow is how far back log matches are counted.
+	defaultLogWindow = 5 * time.Minute
+	// defaultLogLineLength is the default truncation of lines in status.
+	defaultLogLineLength = 256
+	// maxLogLines is how many matching lines are kept per result.
+	maxLogLines = 5
+	// maxLogResults bounds the per-container results kept in status.
+	maxLogResults = 20
+	// maxLogHits bounds the matches remembered per container and pattern.
+	maxLogHits = 1000
+	// logsMetric names the Result reporting a log source error.
+	logsMetric = "logs"
+)
+
+// logHit is one matching line.
+type logHit struct {
+	time time.Time
+	line string
+}
+
+// logWatch is the tailing state of one FaultDetection. It lives in memory;
+// after a restart the window is rebuilt by reading it again.
+type logWatch struct {
+	tailer *logtail.Tailer
+	// hits by pod, container and pattern
+	hits map[logHitKey][]logHit
+}
+
+type logHitKey struct {
+	pod, container, pattern string
+}
+
+// logWatches holds the logWatch of every log-based FaultDetection.
+type logWatches struct {
+	mu   sync.Mutex
+	byFD map[types.NamespacedName]*logWatch
+}
+
+func (w *logWatches) get(key types.NamespacedName, source logtail.Source) *logWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.byFD == nil {
+		w.byFD = map[types.NamespacedName]*logWatch{}
+	}
+	lw, ok := w.byFD[key]
+	if !ok {
+		lw = &logWatch{tailer: logtail.NewTailer(source), hits: map[logHitKey][]logHit{}}
+		w.byFD[key] = lw
+	}
+	return lw
+}
+
+func (w *logWatches) forget(key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.byFD, key)
+}
+
+// evaluateLogs reads the new log lines of the selected pods, matches them
+// and returns per-container results with a total count per pattern. A
+// container whose logs cannot be read is reported in its result and does
+// not fail the others.
+func (r *FaultDetectionReconciler) evaluateLogs(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	spec *detectv1.LogSpec,
+	now time.Time,
+) ([]detectv1.LogResult, []detectv1.Result, error) {
+	if r.Logs == nil {
+		return nil, nil, errors.New("no log source configured")
+	}
+	matcher, err := logtail.Compile(spec.Patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	pods, err := r.logPods(ctx, fd, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	window := defaultLogWindow
+	if spec.Window != nil && spec.Window.Duration > 0 {
+		window = spec.Window.Duration
+	}
+	since := now.Add(-window)
+	lw := r.logWatches.get(client.ObjectKeyFromObject(fd), r.Logs)
+	lw.tailer.Retain(pods)
+
+	logger := log.FromContext(ctx)
+	var logResults []detectv1.LogResult
+	live := map[logHitKey]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		for _, container := range logContainers(pod, spec) {
+			lines, err := lw.tailer.Read(ctx, pod, container, since)
+			if err != nil {
+				dataSourceErrorsTotal.WithLabelValues(fd.Spec.TemplateRef, sourceKubernetes).Inc()
+				logger.Error(err, "failed reading container logs")
+				logResults = append(logResults, detectv1.LogResult{Pod: pod.Name, Container: container, Error: err.Error()})
+			}
+			for _, l := range lines {
+				for _, name := range matcher.Match(l.Text) {
+					key := logHitKey{pod.Name, container, name}
+					lw.hits[key] = append(lw.hits[key], logHit{time: l.Time, line: l.Text})
+				}
+			}
+			for _, p := range spec.Patterns {
+				live[logHitKey{pod.Name, container, p.Name}] = true
+			}
+		}
+	}
+
+	// Forget matches that left the window and containers that are gone
+	for key, hits := range lw.hits {
+		first := 0
+		for first < len(hits) && hits[first].time.Before(since) {
+			first++
+		}
+		if n := len(hits) - first; n > maxLogHits {
+			first = len(hits) - maxLogHits
+		}
+		if !live[key] || first == len(hits) {
+			delete(lw.hits, key)
+			continue
+		}
+		lw.hits[key] = hits[first:]
+	}
+
+	lineLength := int(spec.MaxLineLength)
+	if lineLength <= 0 {
+		lineLength = defaultLogLineLength
+	}
+	totals := make(map[string]int, len(spec.Patterns))
+	for i := range pods {
+		for _, container := range logContainers(&pods[i], spec) {
+			for _, p := range spec.Patterns {
+				hits := lw.hits[logHitKey{pods[i].Name, container, p.Name}]
+				if len(hits) == 0 {
+					continue
+				}
+				totals[p.Name] += len(hits)
+				minCount := p.MinCount
+				if minCount <= 0 {
+					minCount = 1
+				}
+				res := detectv1.LogResult{
+					Pod:       pods[i].Name,
+					Container: container,
+					Pattern:   p.Name,
+					Count:     int32(len(hits)),
+					Anomalous: int32(len(hits)) >= minCount,
+				}
+				for _, h := range hits[max(0, len(hits)-maxLogLines):] {
+					res.Lines = append(res.Lines, truncate(h.line, lineLength))
+				}
+				logResults = append(logResults, res)
+			}
+		}
+	}
+
+	results := make([]detectv1.Result, 0, len(spec.Patterns))
+	for _, p := range spec.Patterns {
+		results = append(results, detectv1.Result{Metric: p.Name, Value: strconv.Itoa(totals[p.Name])})
+	}
+	return logResults, results, nil
+}
+
+// logPods returns the pods whose logs are read: those matching the
+// selector, or the FaultDetection's Pod target.
+func (r *FaultDetectionReconciler) logPods(ctx context.Context, fd *detectv1.FaultDetection, spec *detectv1.LogSpec) ([]corev1.Pod, error) {
+	if spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		var list corev1.PodList
+		if err := r.apiReader().List(ctx, &list, client.InNamespace(fd.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	t := fd.Spec.Target
+	if t == nil || t.Kind != "Pod" || t.Name == "" {
+		return nil, errors.New("logs need a selector or a Pod target")
+	}
+	ns := t.Namespace
+	if ns == "" {
+		ns = fd.Namespace
+	}
+	var pod corev1.Pod
+	if err := r.apiReader().Get(ctx, client.ObjectKey{Namespace: ns, Name: t.Name}, &pod); err != nil {
+		return nil, err
+	}
+	return []corev1.Pod{pod}, nil
+}
+
+// logsReason describes the first pattern that fired, or returns "".
+func logsReason(results []detectv1.LogResult, spec *detectv1.LogSpec) string {
+	window := defaultLogWindow
+	if spec.Window != nil && spec.Window.Duration > 0 {
+		window = spec.Window.Duration
+	}
+	for _, res := range results {
+		if res.Anomalous {
+			return fmt.Sprintf("log pattern %s matched %d times in %s in %s/%s",
+				res.Pattern, res.Count, window, res.Pod, res.Container)
+		}
+	}
+	return ""
+}
+
+func logContainers(pod *corev1.Pod, spec *detectv1.LogSpec) []string {
+	if spec.Container != "" {
+		return []string{spec.Container}
+	}
+	names := make([]string, len(pod.Spec.Containers))
+	for i, c := range pod.Spec.Containers {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+func utf8RuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// fakeLogs serves fixed log lines per pod, one per second ending at end.
+type fakeLogs struct {
+	end   time.Time
+	lines map[string][]string
+}
+
+func (f *fakeLogs) Logs(_ context.Context, _, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	lines, ok := f.lines[pod]
+	if !ok {
+		return nil, errors.New("container not found")
+	}
+	since := opts.SinceTime.Time
+	var b strings.Builder
+	for i, l := range lines {
+		at := f.end.Add(-time.Duration(len(lines)-1-i) * time.Second)
+		if at.Before(since.Truncate(time.Second)) {
+			continue
+		}
+		b.WriteString(at.Format(time.RFC3339Nano) + " " + l + "\n")
+	}
+	return io.NopCloser(strings.NewReader(b.String())), nil
+}
+
+func logPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, UID: types.UID("uid-" + name), Labels: map[string]string{"app": "checkout"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + name},
+		}},
+	}
+}
+
+func TestEvaluateLogs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(logPod("checkout-0"), logPod("checkout-1"), logPod("checkout-2")).
+		Build()
+	now := time.Now()
+	logs := &fakeLogs{end: now, lines: map[string][]string{
+		"checkout-0": {"starting", "panic: nil map", "panic: nil map", `{"error":{"kind":"timeout"}}`},
+		"checkout-1": {"starting", strings.Repeat("é", 20) + "panic: " + strings.Repeat("x", 100)},
+	}}
+	r := &FaultDetectionReconciler{Client: c, Logs: logs}
+	fd := &detectv1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout-logs"}}
+	spec := &detectv1.LogSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+		Patterns: []detectv1.LogPattern{
+			{Name: "panics", Regex: "panic:", MinCount: 2},
+			{Name: "timeouts", Regex: "^timeout$", JSONField: "error.kind"},
+		},
+		MaxLineLength: 32,
+	}
+
+	results, totals, err := r.evaluateLogs(context.Background(), fd, spec, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 2 || totals[0].Metric != "panics" || totals[0].Value != "3" || totals[1].Value != "1" {
+		t.Errorf("totals = %+v, want 3 panics and 1 timeout", totals)
+	}
+	byKey := map[string]detectv1.LogResult{}
+	for _, res := range results {
+		byKey[res.Pod+"/"+res.Pattern] = res
+	}
+	if res := byKey["checkout-0/panics"]; res.Count != 2 || !res.Anomalous || len(res.Lines) != 2 {
+		t.Errorf("checkout-0 panics = %+v, want 2 anomalous matches", res)
+	}
+	if res := byKey["checkout-1/panics"]; res.Count != 1 || res.Anomalous || len(res.Lines[0]) > 32+len("…") {
+		t.Errorf("checkout-1 panics = %+v, want 1 healthy truncated match", res)
+	}
+	if res := byKey["checkout-2/"]; res.Error == "" {
+		t.Errorf("checkout-2 = %+v, want its read error reported", res)
+	}
+	if got := logsReason(results, spec); !strings.Contains(got, "log pattern panics matched 2 times in 5m0s in checkout-0/app") {
+		t.Errorf("reason = %q", got)
+	}
+
+	// Nothing new was logged, the earlier matches still count
+	_, totals, err = r.evaluateLogs(context.Background(), fd, spec, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals[0].Value != "3" {
+		t.Errorf("panics = %s after an empty read, want 3", totals[0].Value)
+	}
+
+	// Matches age out of the window
+	_, totals, err = r.evaluateLogs(context.Background(), fd, spec, now.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals[0].Value != "0" {
+		t.Errorf("panics = %s after the window, want 0", totals[0].Value)
+	}
+
+	t.Run("pod target", func(t *testing.T) {
+		fd := fd.DeepCopy()
+		fd.Spec.Target = &detectv1.ObjectRef{Kind: "Pod", Name: "checkout-0"}
+		spec := spec.DeepCopy()
+		spec.Selector = nil
+		r := &FaultDetectionReconciler{Client: c, Logs: logs}
+		_, totals, err := r.evaluateLogs(context.Background(), fd, spec, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if totals[0].Value != "2" {
+			t.Errorf("panics = %s, want only the target's 2", totals[0].Value)
+		}
+
+		fd.Spec.Target = nil
+		if _, _, err := r.evaluateLogs(context.Background(), fd, spec, now); err == nil {
+			t.Error("expected an error without selector or target")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/maintenance"
+	"github.com/phuongbac/detection-controller/internal/params"
+)
+
+// MaintenanceWindowReconciler keeps the status of MaintenanceWindows
+// current, so that other controllers, such as the conflict-aware recovery
+// controller, can tell whether a window is open without evaluating its
+// schedule.
+type MaintenanceWindowReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=maintenancewindows,verbs=get;list;watch
+// +kubebuilder:rbac:groups=detect.failure-recovery.io,resources=maintenancewindows/status,verbs=get;update;patch
+
+// Reconcile evaluates the window and requeues at its next opening or closing.
+func (r *MaintenanceWindowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var mw detectv1.MaintenanceWindow
+	if err := r.Get(ctx, req.NamespacedName, &mw); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	now := time.Now()
+	status := detectv1.MaintenanceWindowStatus{}
+	var next time.Time
+	if err := maintenance.Validate(&mw.Spec); err != nil {
+		status.Message = err.Error()
+	} else if st, err := maintenance.Evaluate(&mw.Spec, now); err != nil {
+		status.Message = err.Error()
+	} else {
+		status.Active = st.Active
+		status.ActiveSince = metaTime(st.Since)
+		status.ActiveUntil = metaTime(st.Until)
+		status.NextStart = metaTime(st.Next)
+		next = st.Next
+		if st.Active {
+			next = st.Until
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(mw.Status, status) {
+		mw.Status = status
+		if err := r.Status().Update(ctx, &mw); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if next.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: max(next.Sub(now), time.Second)}, nil
+}
+
+// metaTime returns t as an API time, or nil if it is zero.
+func metaTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MaintenanceWindowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&detectv1.MaintenanceWindow{}).
+		Named("maintenancewindow").
+		Complete(r)
+}
+
+// silencingWindow returns the name of an open MaintenanceWindow that covers
+// fd, or "" if there is none. Windows are tried in name order.
+func (r *FaultDetectionReconciler) silencingWindow(ctx context.Context, fd *detectv1.FaultDetection, template string, now time.Time) (string, error) {
+	var list detectv1.MaintenanceWindowList
+	if err := r.List(ctx, &list); err != nil {
+		return "", err
+	}
+	if len(list.Items) == 0 {
+		return "", nil
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+
+	target := maintenance.Target{Template: template, Namespace: fd.Namespace}
+	if fd.Spec.Target != nil {
+		var vars params.Vars
+		targetVars(ctx, r.Client, fd.Spec.Target, &vars)
+		target.Labels = vars.Labels
+	}
+	for _, mw := range list.Items {
+		st, err := maintenance.Evaluate(&mw.Spec, now)
+		if err != nil || !st.Active {
+			continue
+		}
+		if ok, err := maintenance.Matches(&mw.Spec, target); err == nil && ok {
+			return mw.Name, nil
+		}
+	}
+	return "", nil
+}
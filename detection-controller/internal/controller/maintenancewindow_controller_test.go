@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func maintenanceScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := detectv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func TestMaintenanceWindowReconcile(t *testing.T) {
+	now := time.Now()
+	window := func(name string, spec detectv1.MaintenanceWindowSpec) *detectv1.MaintenanceWindow {
+		return &detectv1.MaintenanceWindow{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	open := window("open", detectv1.MaintenanceWindowSpec{
+		End: &metav1.Time{Time: now.Add(time.Hour)},
+	})
+	later := window("later", detectv1.MaintenanceWindowSpec{
+		Start: &metav1.Time{Time: now.Add(2 * time.Hour)},
+		End:   &metav1.Time{Time: now.Add(3 * time.Hour)},
+	})
+	broken := window("broken", detectv1.MaintenanceWindowSpec{
+		Schedule: "0 25 * * *",
+		Duration: &metav1.Duration{Duration: time.Hour},
+	})
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(open, later, broken).
+		WithStatusSubresource(&detectv1.MaintenanceWindow{}).
+		Build()
+	r := &MaintenanceWindowReconciler{Client: c}
+
+	reconcile := func(name string) (detectv1.MaintenanceWindowStatus, time.Duration) {
+		t.Helper()
+		res, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mw detectv1.MaintenanceWindow
+		if err := c.Get(context.Background(), client.ObjectKey{Name: name}, &mw); err != nil {
+			t.Fatal(err)
+		}
+		return mw.Status, res.RequeueAfter
+	}
+
+	status, requeue := reconcile("open")
+	if !status.Active || status.ActiveUntil == nil || requeue <= 0 || requeue > time.Hour {
+		t.Errorf("open: status = %+v, requeue = %s; want active and requeued at its end", status, requeue)
+	}
+	status, requeue = reconcile("later")
+	if status.Active || status.NextStart == nil || requeue <= time.Hour || requeue > 2*time.Hour {
+		t.Errorf("later: status = %+v, requeue = %s; want closed and requeued at its start", status, requeue)
+	}
+	status, requeue = reconcile("broken")
+	if status.Active || status.Message == "" || requeue != 0 {
+		t.Errorf("broken: status = %+v, requeue = %s; want an error message", status, requeue)
+	}
+}
+
+func TestSilencingWindow(t *testing.T) {
+	now := time.Now()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "worker-1",
+		Labels: map[string]string{"pool": "gpu"},
+	}}
+	spec := func(pool string) detectv1.MaintenanceWindowSpec {
+		return detectv1.MaintenanceWindowSpec{
+			Templates:      []string{"node-not-ready"},
+			TargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": pool}},
+			End:            &metav1.Time{Time: now.Add(time.Hour)},
+		}
+	}
+	cpu := &detectv1.MaintenanceWindow{ObjectMeta: metav1.ObjectMeta{Name: "a-cpu-upgrade"}, Spec: spec("cpu")}
+	gpu := &detectv1.MaintenanceWindow{ObjectMeta: metav1.ObjectMeta{Name: "b-gpu-upgrade"}, Spec: spec("gpu")}
+	c := fake.NewClientBuilder().WithScheme(maintenanceScheme(t)).WithObjects(node, cpu, gpu).Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	fd := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "worker-1"},
+		Spec: detectv1.FaultDetectionSpec{
+			TemplateRef: "node-not-ready",
+			Target:      &detectv1.ObjectRef{Kind: "Node", Name: "worker-1"},
+		},
+	}
+	silence := func(template string, at time.Time) string {
+		t.Helper()
+		name, err := r.silencingWindow(context.Background(), fd, template, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	if got := silence("node-not-ready", now); got != "b-gpu-upgrade" {
+		t.Errorf("window = %q, want the GPU pool window", got)
+	}
+	if got := silence("pod-crash-loop", now); got != "" {
+		t.Errorf("window = %q, want none for another template", got)
+	}
+	if got := silence("node-not-ready", now.Add(2*time.Hour)); got != "" {
+		t.Errorf("window = %q, want none after the window ended", got)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "detection"
+
+// Data source label values used by dataSourceErrorsTotal.
+const (
+	sourcePrometheus = "prometheus"
+	sourceKubernetes = "kubernetes"
+	sourceML         = "ml"
+	sourceHTTP       = "http"
+)
+
+var (
+	// anomalousTargets is the number of targets currently reported anomalous
+	// by a FaultDetection, labelled with the template it evaluates.
+	anomalousTargets = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "anomalous_targets",
+			Help:      "Number of targets currently anomalous per DetectionTemplate and FaultDetection.",
+		},
+		[]string{"template", "namespace", "faultdetection"},
+	)
+
+	// evaluationDuration measures one full evaluation of a FaultDetection.
+	evaluationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "evaluation_duration_seconds",
+			Help:      "Time taken to evaluate a FaultDetection against its DetectionTemplate.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"template"},
+	)
+
+	// dataSourceErrorsTotal counts failed calls to a data source.
+	dataSourceErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "datasource_errors_total",
+			Help:      "Number of failed data source calls per DetectionTemplate and source.",
+		},
+		[]string{"template", "source"},
+	)
+
+	// mlRequestDuration measures calls to the ML model endpoint.
+	mlRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "ml_request_duration_seconds",
+			Help:      "Latency of ML model inference calls per DetectionTemplate.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"template"},
+	)
+
+	// triggersFiredTotal counts anomalies that resulted in a trigger.
+	triggersFiredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "triggers_fired_total",
+			Help:      "Number of recovery triggers fired per DetectionTemplate.",
+		},
+		[]string{"template"},
+	)
+
+	// dryRunTriggersTotal counts triggers recorded in dry run instead of
+	// being sent.
+	dryRunTriggersTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dry_run_triggers_total",
+			Help:      "Number of recovery triggers recorded but not sent in dry run per DetectionTemplate.",
+		},
+		[]string{"template"},
+	)
+
+	// anomaliesSilencedTotal counts anomalies that fell into a maintenance
+	// window instead of triggering.
+	anomaliesSilencedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "anomalies_silenced_total",
+			Help:      "Number of anomalies silenced per DetectionTemplate and MaintenanceWindow.",
+		},
+		[]string{"template", "window"},
+	)
+
+	// symptomsTotal counts anomalies explained by an anomalous upstream
+	// object instead of triggering.
+	symptomsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "symptoms_total",
+			Help:      "Number of anomalies suppressed as symptoms of an upstream anomaly per DetectionTemplate.",
+		},
+		[]string{"template"},
+	)
+)
+
+func init() {
+	// Register custom metrics with the global controller-runtime registry so
+	// they are served by the manager's metrics endpoint.
+	metrics.Registry.MustRegister(
+		anomalousTargets,
+		evaluationDuration,
+		dataSourceErrorsTotal,
+		mlRequestDuration,
+		triggersFiredTotal,
+		dryRunTriggersTotal,
+		anomaliesSilencedTotal,
+		symptomsTotal,
+	)
+}
+
+// forgetFaultDetection drops the per-FaultDetection series once the object is gone.
+func forgetFaultDetection(namespace, name string) {
+	anomalousTargets.DeletePartialMatch(prometheus.Labels{
+		"namespace":      namespace,
+		"faultdetection": name,
+	})
+}
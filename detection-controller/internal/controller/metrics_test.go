@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestTriggerCountersCountTransitions(t *testing.T) {
+	value := "2"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,%q]}]}}`, value)
+	}))
+	defer srv.Close()
+
+	tmpl := &detectv1.DetectionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "transitions"},
+		Spec: detectv1.DetectionTemplateSpec{
+			PrometheusAPI: srv.URL,
+			Queries:       []detectv1.QuerySpec{{Metric: "p99", Query: "p99"}},
+			Rule:          "p99 > 1",
+			TriggerAPI:    "http://recovery/trigger",
+			Interval:      metav1.Duration{Duration: time.Minute},
+		},
+	}
+	fd := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "latency"},
+		Spec:       detectv1.FaultDetectionSpec{TemplateRef: "transitions"},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(tmpl, fd).
+		WithStatusSubresource(fd).
+		WithIndex(&detectv1.FaultDetection{}, targetIndex, faultDetectionTarget).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+	key := client.ObjectKeyFromObject(fd)
+	fired := triggersFiredTotal.WithLabelValues(tmpl.Name)
+	before := testutil.ToFloat64(fired)
+
+	evaluate := func() detectv1.FaultDetectionStatus {
+		t.Helper()
+		if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatal(err)
+		}
+		var got detectv1.FaultDetection
+		if err := c.Get(context.Background(), key, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Status
+	}
+
+	// An anomaly that persists fires once
+	for i := 0; i < 3; i++ {
+		if st := evaluate(); !st.Anomalous || !st.Triggered {
+			t.Fatalf("status = %+v, want triggered", st)
+		}
+	}
+	if n := testutil.ToFloat64(fired) - before; n != 1 {
+		t.Errorf("triggers fired = %v, want 1 for one anomaly", n)
+	}
+
+	// Recovery clears the trigger, and the next anomaly fires again
+	value = "0.5"
+	st := evaluate()
+	if st.Anomalous || st.Triggered || st.TriggerMsg != "" || st.TriggerAPI != "" || st.TriggerPayload != "" || st.DryRun {
+		t.Errorf("status = %+v, want the trigger cleared", st)
+	}
+	value = "2"
+	evaluate()
+	if n := testutil.ToFloat64(fired) - before; n != 2 {
+		t.Errorf("triggers fired = %v, want 2 after a second anomaly", n)
+	}
+}
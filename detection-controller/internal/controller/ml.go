@@ -0,0 +1,248 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/detector"
+	"github.com/phuongbac/detection-controller/internal/inference"
+)
+
+// defaultTopFeatures is how many contributing features are kept in status.
+const defaultTopFeatures = 3
+
+// modelEndpoint returns the ML endpoint to call: the configured one, or the
+// model server the DetectionTemplate controller deployed once it is ready.
+func modelEndpoint(tmpl *detectv1.DetectionTemplate) string {
+	if tmpl.Spec.ML == nil {
+		return ""
+	}
+	if tmpl.Spec.ML.Endpoint != "" {
+		return tmpl.Spec.ML.Endpoint
+	}
+	if tmpl.Status.ModelReady {
+		return tmpl.Status.ModelEndpoint
+	}
+	return ""
+}
+
+// nodeOKFeature is the feature carrying a node's own check result, 1 when
+// the node passed it.
+const nodeOKFeature = "ok"
+
+// mlInstances turns the successful query results into the model input, one
+// instance per target: every node a cluster-wide check reported on, or else
+// the FaultDetection's target. Targets share the query results; v1 and v2
+// models also receive the target of each instance.
+func mlInstances(
+	fd *detectv1.FaultDetection,
+	nodes []detectv1.NodeResult,
+	results []detectv1.Result,
+	series map[string][]float64,
+	now time.Time,
+) []inference.Instance {
+	shared := inference.Instance{Timestamp: now, Series: map[string][]float64{}}
+	for _, res := range results {
+		if res.Error != "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(res.Value, 64)
+		if err != nil {
+			continue
+		}
+		shared.Features = append(shared.Features, inference.Feature{Name: res.Metric, Value: v})
+		if s, ok := series[res.Metric]; ok {
+			shared.Series[res.Metric] = s
+		}
+	}
+
+	if len(nodes) == 0 {
+		if t := fd.Spec.Target; t != nil {
+			shared.Target = inference.Target{Kind: t.Kind, Namespace: t.Namespace, Name: t.Name}
+		}
+		return []inference.Instance{shared}
+	}
+	out := make([]inference.Instance, 0, len(nodes))
+	for _, nr := range nodes {
+		in := shared
+		in.Target = inference.Target{Kind: "Node", Name: nr.NodeName}
+		ok := 0.0
+		if nr.Ok {
+			ok = 1
+		}
+		in.Features = append(append([]inference.Feature{}, shared.Features...), inference.Feature{Name: nodeOKFeature, Value: ok})
+		out = append(out, in)
+	}
+	return out
+}
+
+// mlDetector scores the source results with the template's ML model.
+type mlDetector struct {
+	// reader reads the model endpoint's auth Secret
+	reader client.Reader
+	// secretNamespace is the only namespace the Secret may be in
+	secretNamespace string
+}
+
+func (d *mlDetector) Name() string        { return subDetectorML }
+func (d *mlDetector) Kind() detector.Kind { return detector.Analyzer }
+
+func (d *mlDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.ML != nil
+}
+
+func (d *mlDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	if t := spec.ML.Threshold; t != "" {
+		if _, err := strconv.ParseFloat(t, 64); err != nil {
+			return fmt.Errorf("invalid ML threshold %q", t)
+		}
+	}
+	return nil
+}
+
+// Evaluate calls the model once it has an endpoint. A failed call is
+// recorded in the MLResult rather than returned.
+func (d *mlDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	tmpl := in.Template
+	out := &detector.Outcome{}
+	endpoint := modelEndpoint(tmpl)
+	if endpoint == "" {
+		return out, nil
+	}
+
+	start := time.Now()
+	instances := mlInstances(in.FaultDetection, in.Targets.Nodes, in.Results, in.Series, start)
+	mlResult, err := d.runModel(ctx, tmpl, endpoint, instances)
+	mlRequestDuration.WithLabelValues(tmpl.Name).Observe(time.Since(start).Seconds())
+	out.Targets.ML = mlResult
+	if err != nil {
+		dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourceML).Inc()
+		log.FromContext(ctx).Error(err, "failed calling ML model")
+	} else if mlResult.Anomalous {
+		out.Anomalous = true
+		out.Reason = fmt.Sprintf("ML model detected anomaly (score %s)", mlResult.Score)
+		var anomalous []string
+		for _, t := range mlResult.Targets {
+			if t.Anomalous {
+				anomalous = append(anomalous, t.Target.Kind+" "+t.Target.Name)
+			}
+		}
+		if len(anomalous) > 0 {
+			out.Reason = fmt.Sprintf("ML model detected anomaly on %s (score %s)", strings.Join(anomalous, ", "), mlResult.Score)
+		}
+	}
+	return out, nil
+}
+
+// runModel calls the template's model and converts its predictions into an
+// MLResult. The returned error is also recorded in the result.
+func (d *mlDetector) runModel(
+	ctx context.Context,
+	tmpl *detectv1.DetectionTemplate,
+	endpoint string,
+	instances []inference.Instance,
+) (*detectv1.MLResult, error) {
+	ml := tmpl.Spec.ML
+	out := &detectv1.MLResult{
+		Model:     ml.ModelName,
+		Protocol:  string(ml.Protocol),
+		Threshold: ml.Threshold,
+	}
+	if out.Protocol == "" {
+		out.Protocol = string(detectv1.InferenceProtocolLegacy)
+	}
+	fail := func(err error) (*detectv1.MLResult, error) {
+		out.Error = err.Error()
+		return out, err
+	}
+
+	var threshold float64
+	if ml.Threshold != "" {
+		t, err := strconv.ParseFloat(ml.Threshold, 64)
+		if err != nil {
+			return fail(fmt.Errorf("invalid ML threshold %q", ml.Threshold))
+		}
+		threshold = t
+	}
+
+	httpClient, err := newHTTPClient(ctx, d.reader, d.secretNamespace, ml.Client)
+	if err != nil {
+		return fail(err)
+	}
+	c := &inference.Client{
+		Protocol:   inference.Protocol(out.Protocol),
+		Endpoint:   endpoint,
+		Model:      ml.ModelName,
+		HTTPClient: httpClient,
+	}
+	predictions, err := c.Predict(ctx, instances)
+	if err != nil {
+		return fail(err)
+	}
+	if len(predictions) != len(instances) {
+		return fail(fmt.Errorf("model returned %d predictions for %d targets", len(predictions), len(instances)))
+	}
+
+	// Thresholds apply to scores the model returned; without one its
+	// own flag decides
+	anomalous := func(p inference.Prediction) bool {
+		if ml.Threshold != "" && p.Scored {
+			return p.Score >= threshold
+		}
+		return p.Anomaly != nil && *p.Anomaly
+	}
+	best := 0
+	for i, p := range predictions {
+		if len(predictions) > 1 {
+			t := instances[i].Target
+			out.Targets = append(out.Targets, detectv1.MLTargetResult{
+				Target:    detectv1.ObjectRef{Kind: t.Kind, Namespace: t.Namespace, Name: t.Name},
+				Score:     strconv.FormatFloat(p.Score, 'f', 4, 64),
+				Anomalous: anomalous(p),
+			})
+		}
+		b := predictions[best]
+		if anomalous(p) && !anomalous(b) || anomalous(p) == anomalous(b) && p.Score > b.Score {
+			best = i
+		}
+	}
+
+	p := predictions[best]
+	out.Score = strconv.FormatFloat(p.Score, 'f', 4, 64)
+	out.Anomalous = anomalous(p)
+
+	n := int(ml.TopFeatures)
+	if n <= 0 {
+		n = defaultTopFeatures
+	}
+	for _, c := range p.TopContributions(n) {
+		out.TopFeatures = append(out.TopFeatures, detectv1.FeatureContribution{
+			Name:         c.Name,
+			Contribution: strconv.FormatFloat(c.Value, 'f', 4, 64),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/params"
+)
+
+// renderTemplate returns a copy of tmpl with the FaultDetection's variables
+// substituted into its templated fields. tmpl is returned unchanged when it
+// has none. The target object is read through c.
+func renderTemplate(
+	ctx context.Context,
+	c client.Reader,
+	fd *detectv1.FaultDetection,
+	tmpl *detectv1.DetectionTemplate,
+) (*detectv1.DetectionTemplate, error) {
+	if !isParameterised(tmpl) {
+		return tmpl, nil
+	}
+
+	values, err := params.Resolve(tmpl.Spec.Parameters, fd.Spec.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	vars := params.Vars{Params: values}
+	if t := fd.Spec.Target; t != nil {
+		vars.Target = params.Target{Kind: t.Kind, Namespace: t.Namespace, Name: t.Name}
+		targetVars(ctx, c, t, &vars)
+	}
+
+	out := tmpl.DeepCopy()
+	render := func(field *string, esc params.Escaper) {
+		if err != nil {
+			return
+		}
+		*field, err = params.Render(*field, vars, esc)
+	}
+	// HTTP probes address a URL; the others a host name, with a port for
+	// TCP and gRPC
+	renderProbe := func(name string, p *detectv1.ProbeSpec) {
+		if p.Type == detectv1.ProbeHTTP {
+			render(&p.Address, params.URL)
+			return
+		}
+		if !strings.Contains(p.Address, "{{") {
+			return
+		}
+		render(&p.Address, params.Host)
+		if err == nil {
+			if herr := params.CheckHost(p.Address, p.Type != detectv1.ProbeDNS); herr != nil {
+				err = fmt.Errorf("probe %s address: %w", name, herr)
+			}
+		}
+	}
+	for i := range out.Spec.Queries {
+		render(&out.Spec.Queries[i].Query, params.PromQL)
+	}
+	if out.Spec.Composite != nil {
+		for i := range out.Spec.Composite.Detectors {
+			if p := out.Spec.Composite.Detectors[i].Prometheus; p != nil {
+				render(&p.Query, params.PromQL)
+			}
+			if d := &out.Spec.Composite.Detectors[i]; d.Probe != nil {
+				renderProbe(d.Name, d.Probe)
+			}
+		}
+	}
+	for i := range out.Spec.Probes {
+		renderProbe(out.Spec.Probes[i].Name, &out.Spec.Probes[i])
+	}
+	if h := out.Spec.HTTP; h != nil {
+		render(&h.URL, params.URL)
+		render(&h.Body, params.JSON)
+	}
+	render(&out.Spec.Rule, params.PromQL)
+	render(&out.Spec.TriggerAPI, params.URL)
+	render(&out.Spec.TriggerPayload, params.JSON)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// targetVars fills in the node and labels of the target object. They are
+// left empty when the object cannot be read.
+func targetVars(ctx context.Context, c client.Reader, t *detectv1.ObjectRef, vars *params.Vars) {
+	if t.Kind == "Node" {
+		vars.Node = t.Name
+	}
+	apiVersion := t.APIVersion
+	if apiVersion == "" && (t.Kind == "Node" || t.Kind == "Pod") {
+		apiVersion = "v1"
+	}
+	if apiVersion == "" || t.Kind == "" || t.Name == "" {
+		return
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(t.Kind)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: t.Name}, u); err != nil {
+		return
+	}
+	vars.Labels = u.GetLabels()
+	if t.Kind == "Pod" {
+		vars.Node, _, _ = unstructured.NestedString(u.Object, "spec", "nodeName")
+	}
+}
+
+// isParameterised reports whether any templated field uses template actions
+// or the template declares parameters.
+func isParameterised(tmpl *detectv1.DetectionTemplate) bool {
+	if len(tmpl.Spec.Parameters) > 0 {
+		return true
+	}
+	fields := []string{tmpl.Spec.Rule, tmpl.Spec.TriggerAPI, tmpl.Spec.TriggerPayload}
+	for _, q := range tmpl.Spec.Queries {
+		fields = append(fields, q.Query)
+	}
+	for _, p := range tmpl.Spec.Probes {
+		fields = append(fields, p.Address)
+	}
+	if h := tmpl.Spec.HTTP; h != nil {
+		fields = append(fields, h.URL, h.Body)
+	}
+	if c := tmpl.Spec.Composite; c != nil {
+		for _, d := range c.Detectors {
+			if d.Prometheus != nil {
+				fields = append(fields, d.Prometheus.Query)
+			}
+			if d.Probe != nil {
+				fields = append(fields, d.Probe.Address)
+			}
+		}
+	}
+	for _, f := range fields {
+		if strings.Contains(f, "{{") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestRenderTemplateSubstitutesTarget(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0", Labels: map[string]string{"app": "api"}},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+	}
+	c := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Parameters: []detectv1.TemplateParameter{{Name: "limit", Default: "0.8"}},
+		Queries: []detectv1.QuerySpec{{
+			Metric: "cpu",
+			Query:  `rate(cpu{pod="{{ .Target.Name }}",app="{{ .Labels.app }}",node="{{ .Node }}"}[5m])`,
+		}},
+		Rule:           "cpu > {{ .Params.limit }}",
+		TriggerPayload: `{"pod":"{{ .Target.Namespace }}/{{ .Target.Name }}"}`,
+	}}
+	fd := &detectv1.FaultDetection{Spec: detectv1.FaultDetectionSpec{
+		Target:     &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "api-0"},
+		Parameters: map[string]string{"limit": "0.95"},
+	}}
+
+	out, err := renderTemplate(context.Background(), c, fd, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Spec.Queries[0].Query; got != `rate(cpu{pod="api-0",app="api",node="worker-1"}[5m])` {
+		t.Errorf("query = %s", got)
+	}
+	if out.Spec.Rule != "cpu > 0.95" {
+		t.Errorf("rule = %s", out.Spec.Rule)
+	}
+	if out.Spec.TriggerPayload != `{"pod":"shop/api-0"}` {
+		t.Errorf("payload = %s", out.Spec.TriggerPayload)
+	}
+	if tmpl.Spec.Rule != "cpu > {{ .Params.limit }}" {
+		t.Error("original template was modified")
+	}
+}
+
+func TestRenderTemplateProbeAddresses(t *testing.T) {
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Parameters: []detectv1.TemplateParameter{{Name: "backend", Required: true}},
+		Probes: []detectv1.ProbeSpec{
+			{Name: "tcp", Type: detectv1.ProbeTCP, Address: "{{ .Params.backend }}"},
+			{Name: "http", Type: detectv1.ProbeHTTP, Address: "http://{{ .Params.backend }}/healthz"},
+		},
+	}}
+	render := func(backend string) (*detectv1.DetectionTemplate, error) {
+		fd := &detectv1.FaultDetection{Spec: detectv1.FaultDetectionSpec{Parameters: map[string]string{"backend": backend}}}
+		return renderTemplate(context.Background(), fake.NewClientBuilder().Build(), fd, tmpl)
+	}
+
+	out, err := render("checkout.shop.svc:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Spec.Probes[0].Address != "checkout.shop.svc:8080" || out.Spec.Probes[1].Address != "http://checkout.shop.svc:8080/healthz" {
+		t.Errorf("addresses = %q, %q", out.Spec.Probes[0].Address, out.Spec.Probes[1].Address)
+	}
+	if _, err := render("checkout/admin:8080"); err == nil {
+		t.Error("want an error for an address that is no host name")
+	}
+}
+
+func TestFeedbackSnapshotRendersQueries(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.FormValue("query"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1700000000,"0.5"]]}]}}`))
+	}))
+	defer srv.Close()
+
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	tmpl := &detectv1.DetectionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-cpu"},
+		Spec: detectv1.DetectionTemplateSpec{
+			PrometheusAPI: srv.URL,
+			Queries:       []detectv1.QuerySpec{{Metric: "cpu", Query: `rate(cpu{pod="{{ .Target.Name }}"}[5m])`}},
+		},
+	}
+	fd := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0-cpu"},
+		Spec: detectv1.FaultDetectionSpec{
+			TemplateRef: "pod-cpu",
+			Target:      &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "api-0"},
+		},
+		Status: detectv1.FaultDetectionStatus{Occurrences: []detectv1.AnomalyOccurrence{{ID: "1", StartTime: started}}},
+	}
+	fb := &detectv1.DetectionFeedback{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0-cpu-1"},
+		Spec:       detectv1.DetectionFeedbackSpec{FaultDetectionRef: "api-0-cpu", Label: detectv1.FeedbackTruePositive},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(tmpl, fd, fb).
+		WithStatusSubresource(fb).
+		Build()
+	r := &DetectionFeedbackReconciler{Client: c}
+	key := client.ObjectKeyFromObject(fb)
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 || queries[0] != `rate(cpu{pod="api-0"}[5m])` {
+		t.Errorf("queries = %q, want the query rendered for the target", queries)
+	}
+	if err := c.Get(context.Background(), key, fb); err != nil {
+		t.Fatal(err)
+	}
+	if !fb.Status.Collected || len(fb.Status.Snapshots) != 1 || len(fb.Status.Snapshots[0].Samples) != 1 {
+		t.Errorf("status = %+v, want one collected snapshot", fb.Status)
+	}
+}
+
+func TestFeedbackSnapshotCompositeDetectors(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.FormValue("query"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1700000000,"0.5"]]}]}}`))
+	}))
+	defer srv.Close()
+
+	tmpl := &detectv1.DetectionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-health"},
+		Spec: detectv1.DetectionTemplateSpec{
+			PrometheusAPI: srv.URL,
+			Composite: &detectv1.CompositeSpec{
+				Operator: detectv1.CompositeOr,
+				Detectors: []detectv1.SubDetectorSpec{
+					{Name: "ready", Field: &detectv1.FieldCheck{APIVersion: "v1", Kind: "Pod", FieldPath: "status.phase", Expected: "Running"}},
+					{Name: "cpu", Prometheus: &detectv1.PrometheusCheck{Query: `rate(cpu{pod="{{ .Target.Name }}"}[5m])`}},
+				},
+			},
+		},
+	}
+	fd := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0-health"},
+		Spec: detectv1.FaultDetectionSpec{
+			TemplateRef: "pod-health",
+			Target:      &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "api-0"},
+		},
+		Status: detectv1.FaultDetectionStatus{Occurrences: []detectv1.AnomalyOccurrence{
+			{ID: "1", StartTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+		}},
+	}
+	fb := &detectv1.DetectionFeedback{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0-health-1"},
+		Spec:       detectv1.DetectionFeedbackSpec{FaultDetectionRef: "api-0-health", Label: detectv1.FeedbackTruePositive},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(tmpl, fd, fb).
+		WithStatusSubresource(fb).
+		Build()
+	r := &DetectionFeedbackReconciler{Client: c}
+	key := client.ObjectKeyFromObject(fb)
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), key, fb); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 || queries[0] != `rate(cpu{pod="api-0"}[5m])` {
+		t.Errorf("queries = %q, want the sub-detector query rendered", queries)
+	}
+	if len(fb.Status.Snapshots) != 1 || fb.Status.Snapshots[0].Metric != "cpu" || len(fb.Status.Snapshots[0].Samples) != 1 {
+		t.Errorf("snapshots = %+v, want the cpu detector's history", fb.Status.Snapshots)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/httpclient"
+	"github.com/phuongbac/detection-controller/internal/probe"
+)
+
+const (
+	// defaultProbeTimeout applies when a probe sets no timeout.
+	defaultProbeTimeout = 5 * time.Second
+	// maxConcurrentProbes bounds the endpoints probed at once per FaultDetection.
+	maxConcurrentProbes = 10
+	// probesMetric names the Result reporting a probe setup error.
+	probesMetric = "probes"
+	// probeLatencySuffix names the Result holding a probe's slowest answer in seconds.
+	probeLatencySuffix = "_latency"
+)
+
+// probeEndpoint is one address a probe runs against.
+type probeEndpoint struct {
+	// Reported in status: the pod name or the address
+	name string
+	// host:port, a host name for DNS, or a URL for HTTP
+	address string
+}
+
+// evaluateProbes runs every probe against its endpoints. It returns the
+// outcome per endpoint and, per probe, the number of failed endpoints and
+// the slowest answer.
+func (r *FaultDetectionReconciler) evaluateProbes(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	probes []detectv1.ProbeSpec,
+) ([]detectv1.ProbeResult, []detectv1.Result, error) {
+	type job struct {
+		spec     *detectv1.ProbeSpec
+		endpoint probeEndpoint
+		run      func(context.Context, string) probe.Outcome
+		index    int
+	}
+	var (
+		jobs         []job
+		probeResults []detectv1.ProbeResult
+	)
+	for i := range probes {
+		p := &probes[i]
+		run, err := r.prober(ctx, p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("probe %s: %w", p.Name, err)
+		}
+		endpoints, err := r.probeEndpoints(ctx, fd, p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("probe %s: %w", p.Name, err)
+		}
+		for _, ep := range endpoints {
+			jobs = append(jobs, job{spec: p, endpoint: ep, run: run, index: len(probeResults)})
+			probeResults = append(probeResults, detectv1.ProbeResult{Probe: p.Name, Endpoint: ep.name})
+		}
+	}
+
+	outcomes := make([]probe.Outcome, len(probeResults))
+	sem := make(chan struct{}, maxConcurrentProbes)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			timeout := defaultProbeTimeout
+			if j.spec.Timeout != nil && j.spec.Timeout.Duration > 0 {
+				timeout = j.spec.Timeout.Duration
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			outcomes[j.index] = j.run(probeCtx, j.endpoint.address)
+		}()
+	}
+	wg.Wait()
+
+	failedByProbe := map[string]int{}
+	slowest := map[string]time.Duration{}
+	for _, j := range jobs {
+		out := outcomes[j.index]
+		if out.Success && j.spec.MaxLatency != nil && out.Latency > j.spec.MaxLatency.Duration {
+			out.Success = false
+			out.Message = fmt.Sprintf("latency %s exceeds %s", out.Latency.Round(time.Millisecond), j.spec.MaxLatency.Duration)
+		}
+		res := &probeResults[j.index]
+		res.Success = out.Success
+		res.StatusCode = int32(out.StatusCode)
+		res.Latency = out.Latency.Round(time.Millisecond).String()
+		res.Message = out.Message
+		if !out.Success {
+			failedByProbe[j.spec.Name]++
+		}
+		slowest[j.spec.Name] = max(slowest[j.spec.Name], out.Latency)
+	}
+
+	results := make([]detectv1.Result, 0, 2*len(probes))
+	for _, p := range probes {
+		results = append(results,
+			detectv1.Result{Metric: p.Name, Value: strconv.Itoa(failedByProbe[p.Name])},
+			detectv1.Result{Metric: p.Name + probeLatencySuffix, Value: strconv.FormatFloat(slowest[p.Name].Seconds(), 'f', -1, 64)},
+		)
+	}
+	return probeResults, results, nil
+}
+
+// prober returns the check of p, run against one endpoint address.
+func (r *FaultDetectionReconciler) prober(ctx context.Context, p *detectv1.ProbeSpec) (func(context.Context, string) probe.Outcome, error) {
+	switch p.Type {
+	case detectv1.ProbeHTTP:
+		httpClient, err := newHTTPClient(ctx, r.apiReader(), r.SecretNamespace, p.Client)
+		if err != nil {
+			return nil, err
+		}
+		check := probe.HTTPCheck{Method: p.Method}
+		for _, code := range p.ExpectedStatusCodes {
+			check.StatusCodes = append(check.StatusCodes, int(code))
+		}
+		if p.BodyRegex != "" {
+			if check.Body, err = regexp.Compile(p.BodyRegex); err != nil {
+				return nil, fmt.Errorf("invalid bodyRegex: %w", err)
+			}
+		}
+		return func(ctx context.Context, url string) probe.Outcome {
+			return probe.HTTP(ctx, httpClient, url, check)
+		}, nil
+	case detectv1.ProbeTCP:
+		return probe.TCP, nil
+	case detectv1.ProbeGRPC:
+		cfg, err := resolveHTTPClientConfig(ctx, r.apiReader(), r.SecretNamespace, p.Client)
+		if err != nil {
+			return nil, err
+		}
+		check := probe.GRPCCheck{Service: p.GRPCService, Metadata: map[string]string{}}
+		if p.Scheme == "https" {
+			if check.TLS, err = httpclient.TLSConfig(cfg); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range cfg.Headers {
+			check.Metadata[strings.ToLower(k)] = v
+		}
+		if cfg.BearerToken != "" {
+			check.Metadata["authorization"] = "Bearer " + cfg.BearerToken
+		}
+		return func(ctx context.Context, addr string) probe.Outcome {
+			return probe.GRPC(ctx, addr, check)
+		}, nil
+	case detectv1.ProbeDNS:
+		return func(ctx context.Context, host string) probe.Outcome {
+			return probe.DNS(ctx, nil, host)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown probe type %q", p.Type)
+}
+
+// probeEndpoints resolves where p runs: its address, the running pods
+// matching its selector, or the FaultDetection's Pod or Service target.
+func (r *FaultDetectionReconciler) probeEndpoints(ctx context.Context, fd *detectv1.FaultDetection, p *detectv1.ProbeSpec) ([]probeEndpoint, error) {
+	if p.Address != "" {
+		return []probeEndpoint{{name: p.Address, address: p.Address}}, nil
+	}
+
+	if p.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(p.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		var list corev1.PodList
+		if err := r.apiReader().List(ctx, &list, client.InNamespace(fd.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		var endpoints []probeEndpoint
+		for _, pod := range list.Items {
+			// Pods that are not running yet have nothing to answer with
+			if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+				continue
+			}
+			endpoints = append(endpoints, probeEndpoint{name: pod.Name, address: probeAddress(p, pod.Status.PodIP)})
+		}
+		return endpoints, nil
+	}
+
+	t := fd.Spec.Target
+	if t == nil || t.Name == "" {
+		return nil, errors.New("an address, a selector or a named target is required")
+	}
+	ns := t.Namespace
+	if ns == "" {
+		ns = fd.Namespace
+	}
+	switch t.Kind {
+	case "Pod":
+		var pod corev1.Pod
+		if err := r.apiReader().Get(ctx, client.ObjectKey{Namespace: ns, Name: t.Name}, &pod); err != nil {
+			return nil, err
+		}
+		if pod.Status.PodIP == "" {
+			return nil, fmt.Errorf("pod %s/%s has no IP", ns, t.Name)
+		}
+		return []probeEndpoint{{name: pod.Name, address: probeAddress(p, pod.Status.PodIP)}}, nil
+	case "Service":
+		// Through cluster DNS, so headless Services work too
+		host := fmt.Sprintf("%s.%s.svc", t.Name, ns)
+		return []probeEndpoint{{name: t.Name, address: probeAddress(p, host)}}, nil
+	}
+	return nil, fmt.Errorf("cannot probe target kind %q", t.Kind)
+}
+
+// probeAddress is what p dials for host.
+func probeAddress(p *detectv1.ProbeSpec, host string) string {
+	if p.Type == detectv1.ProbeDNS {
+		return host
+	}
+	hostPort := net.JoinHostPort(host, strconv.Itoa(int(p.Port)))
+	if p.Type != detectv1.ProbeHTTP {
+		return hostPort
+	}
+	scheme := p.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	path := p.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return scheme + "://" + hostPort + path
+}
+
+// probesReason describes the first failed probe, or returns "".
+func probesReason(results []detectv1.ProbeResult) string {
+	for _, res := range results {
+		if !res.Success {
+			return fmt.Sprintf("probe %s failed on %s: %s", res.Probe, res.Endpoint, res.Message)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func probePod(name, ip string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: map[string]string{"app": "checkout"}},
+		Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+	}
+}
+
+func TestEvaluateProbes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	_, portStr, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			probePod("checkout-0", "127.0.0.1", corev1.PodRunning),
+			// The server only listens on 127.0.0.1
+			probePod("checkout-1", "127.0.0.2", corev1.PodRunning),
+			probePod("checkout-2", "", corev1.PodPending),
+		).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+	fd := &detectv1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout-probes"}}
+
+	probes := []detectv1.ProbeSpec{
+		{
+			Name:      "http",
+			Type:      detectv1.ProbeHTTP,
+			Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+			Port:      int32(port),
+			Path:      "healthz",
+			BodyRegex: "^ok$",
+			Timeout:   &metav1.Duration{Duration: time.Second},
+		},
+		{Name: "tcp", Type: detectv1.ProbeTCP, Address: strings.TrimPrefix(srv.URL, "http://")},
+		{
+			Name:       "slow",
+			Type:       detectv1.ProbeHTTP,
+			Address:    srv.URL + "/slow",
+			MaxLatency: &metav1.Duration{Duration: 10 * time.Millisecond},
+		},
+	}
+	probeResults, results, err := r.evaluateProbes(context.Background(), fd, probes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byEndpoint := map[string]detectv1.ProbeResult{}
+	for _, res := range probeResults {
+		byEndpoint[res.Probe+"/"+res.Endpoint] = res
+	}
+	if len(probeResults) != 4 {
+		t.Errorf("results = %+v, want 2 pods, the tcp address and the slow address", probeResults)
+	}
+	if res := byEndpoint["http/checkout-0"]; !res.Success || res.StatusCode != 200 || res.Latency == "" {
+		t.Errorf("checkout-0 = %+v, want success", res)
+	}
+	if res := byEndpoint["http/checkout-1"]; res.Success || res.Message == "" {
+		t.Errorf("checkout-1 = %+v, want connection refused", res)
+	}
+	if _, ok := byEndpoint["http/checkout-2"]; ok {
+		t.Error("pending pod without IP was probed")
+	}
+	if res := byEndpoint["tcp/"+probes[1].Address]; !res.Success {
+		t.Errorf("tcp = %+v, want success", res)
+	}
+	if res := byEndpoint["slow/"+probes[2].Address]; res.Success || !strings.Contains(res.Message, "latency") {
+		t.Errorf("slow = %+v, want a latency failure", res)
+	}
+
+	values := map[string]string{}
+	for _, res := range results {
+		values[res.Metric] = res.Value
+	}
+	if values["http"] != "1" || values["tcp"] != "0" || values["slow"] != "1" {
+		t.Errorf("failure counts = %v", values)
+	}
+	if v, err := strconv.ParseFloat(values["slow_latency"], 64); err != nil || v < 0.1 {
+		t.Errorf("slow_latency = %q, want at least 0.1s", values["slow_latency"])
+	}
+	if got := probesReason(probeResults); !strings.HasPrefix(got, "probe ") {
+		t.Errorf("reason = %q", got)
+	}
+
+	t.Run("target", func(t *testing.T) {
+		fd := fd.DeepCopy()
+		fd.Spec.Target = &detectv1.ObjectRef{Kind: "Pod", Name: "checkout-0"}
+		probe := detectv1.ProbeSpec{Name: "http", Type: detectv1.ProbeHTTP, Port: int32(port)}
+		probeResults, _, err := r.evaluateProbes(context.Background(), fd, []detectv1.ProbeSpec{probe})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(probeResults) != 1 || !probeResults[0].Success {
+			t.Errorf("results = %+v, want the target probed", probeResults)
+		}
+
+		fd.Spec.Target = &detectv1.ObjectRef{Kind: "Service", Name: "checkout"}
+		endpoints, err := r.probeEndpoints(context.Background(), fd, &probe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "http://checkout.shop.svc:" + portStr + "/"; len(endpoints) != 1 || endpoints[0].address != want {
+			t.Errorf("endpoints = %+v, want %s", endpoints, want)
+		}
+
+		fd.Spec.Target = nil
+		if _, _, err := r.evaluateProbes(context.Background(), fd, []detectv1.ProbeSpec{probe}); err == nil {
+			t.Error("expected an error without address, selector or target")
+		}
+	})
+
+	t.Run("composite", func(t *testing.T) {
+		tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{Composite: &detectv1.CompositeSpec{
+			Detectors: []detectv1.SubDetectorSpec{{Name: "health", Probe: &probes[0]}},
+		}}}
+		subs, results := r.evaluateCompositeSources(context.Background(), fd, tmpl, map[string][]float64{})
+		if len(subs) != 1 || subs[0].Type != subDetectorProbe || !subs[0].Condition || subs[0].Value != "1" ||
+			!strings.Contains(subs[0].Message, "1 of 2 endpoints failed") {
+			t.Errorf("subs = %+v, want the probe failing on checkout-1", subs)
+		}
+		if len(results) != 2 || results[0].Metric != "health" || results[1].Metric != "health"+probeLatencySuffix {
+			t.Errorf("results = %+v, want totals named after the detector", results)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/detector"
+	"github.com/phuongbac/detection-controller/internal/httpclient"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+// prometheusQuerier is the part of promclient.Client the detectors use.
+type prometheusQuerier interface {
+	Query(ctx context.Context, query string) (float64, error)
+	QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]promclient.Point, error)
+}
+
+// prometheusDetector runs the template's PromQL queries and applies its
+// rule (Option A).
+type prometheusDetector struct {
+	// connect returns a client for the template's Prometheus API
+	connect func(ctx context.Context, tmpl *detectv1.DetectionTemplate) (prometheusQuerier, error)
+}
+
+func (d *prometheusDetector) Name() string        { return subDetectorPrometheus }
+func (d *prometheusDetector) Kind() detector.Kind { return detector.Source }
+
+func (d *prometheusDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return spec.PrometheusAPI != "" && len(spec.Queries) > 0
+}
+
+func (d *prometheusDetector) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	seen := map[string]bool{}
+	for _, q := range spec.Queries {
+		if q.Metric == "" || q.Query == "" {
+			return fmt.Errorf("query %q needs a metric and a query", q.Metric)
+		}
+		if seen[q.Metric] {
+			return fmt.Errorf("metric %q is defined twice", q.Metric)
+		}
+		seen[q.Metric] = true
+	}
+	return nil
+}
+
+func (d *prometheusDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	logger := log.FromContext(ctx)
+	tmpl := in.Template
+	out := &detector.Outcome{Series: map[string][]float64{}}
+
+	promClient, err := d.connect(ctx, tmpl)
+	for _, q := range tmpl.Spec.Queries {
+		if err != nil {
+			// Connection settings are broken; report it on every query
+			dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourcePrometheus).Inc()
+			out.Results = append(out.Results, detectv1.Result{Metric: q.Metric, Error: err.Error()})
+			continue
+		}
+
+		queryCtx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout(tmpl))
+		value, points, qerr := evaluateQuery(queryCtx, promClient, q)
+		cancel()
+		if qerr != nil {
+			dataSourceErrorsTotal.WithLabelValues(tmpl.Name, sourcePrometheus).Inc()
+			logger.Error(qerr, "failed querying prometheus", "metric", q.Metric)
+			out.Results = append(out.Results, detectv1.Result{Metric: q.Metric, Error: qerr.Error()})
+			continue
+		}
+
+		out.Results = append(out.Results, detectv1.Result{
+			Metric: q.Metric,
+			Value:  fmt.Sprintf("%f", value),
+		})
+		if len(points) > 0 {
+			out.Series[q.Metric] = pointValues(points)
+		}
+		applyRule(tmpl.Spec.Rule, q.Metric, value, out)
+	}
+	return out, nil
+}
+
+// applyRule flags out when rule names metric and value exceeds its threshold.
+func applyRule(rule, metric string, value float64, out *detector.Outcome) {
+	if rule == "" || !strings.Contains(rule, metric) {
+		return
+	}
+	threshold := parseThreshold(rule)
+	if value > threshold {
+		out.Anomalous = true
+		out.Reason = fmt.Sprintf("metric %s value %f exceeded threshold %f", metric, value, threshold)
+	}
+}
+
+// prometheusQueryTimeout bounds a single query, so one slow backend cannot
+// stall the reconcile loop.
+func prometheusQueryTimeout(tmpl *detectv1.DetectionTemplate) time.Duration {
+	if c := tmpl.Spec.PrometheusClient; c != nil && c.Timeout != nil && c.Timeout.Duration > 0 {
+		return c.Timeout.Duration
+	}
+	return httpclient.DefaultTimeout
+}
+
+// evaluateQuery runs q as an instant query, or as a range query reduced by
+// its window aggregation. The raw window samples are returned for range queries.
+func evaluateQuery(ctx context.Context, c prometheusQuerier, q detectv1.QuerySpec) (float64, []promclient.Point, error) {
+	if q.Range == nil {
+		value, err := c.Query(ctx, q.Query)
+		return value, nil, err
+	}
+
+	end := time.Now()
+	points, err := c.QueryRange(ctx, q.Query, end.Add(-q.Range.Lookback.Duration), end, rangeStep(q.Range))
+	if err != nil {
+		return 0, nil, err
+	}
+	value, err := aggregateWindow(points, q.Range)
+	return value, points, err
+}
+
+func pointValues(points []promclient.Point) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+func parseThreshold(rule string) float64 {
+	parts := strings.Split(rule, ">")
+	if len(parts) != 2 {
+		return 0
+	}
+	val, _ := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	return val
+}
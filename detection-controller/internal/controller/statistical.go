@@ -0,0 +1,234 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/anomaly"
+	"github.com/phuongbac/detection-controller/internal/detector"
+)
+
+// invalidConfigMapKeyChars matches characters not allowed in ConfigMap keys.
+var invalidConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// baselineConfigMapName is the ConfigMap that stores a FaultDetection's baselines.
+func baselineConfigMapName(fd *detectv1.FaultDetection) string {
+	return fd.Name + "-baseline"
+}
+
+func baselineKey(metric string, algorithm detectv1.StatisticalAlgorithm) string {
+	return invalidConfigMapKeyChars.ReplaceAllString(metric, "_") + "." + string(algorithm)
+}
+
+// runStatisticalDetectors scores the template's query results with its
+// statistical detectors. Baselines are loaded from and saved back to a
+// ConfigMap owned by the FaultDetection, so they survive controller restarts.
+// A sample is folded into a baseline at most once per template interval:
+// reconciles in between, such as those caused by events or alerts, are
+// scored against the baseline without changing it.
+func (r *FaultDetectionReconciler) runStatisticalDetectors(
+	ctx context.Context,
+	fd *detectv1.FaultDetection,
+	tmpl *detectv1.DetectionTemplate,
+	results []detectv1.Result,
+) ([]detectv1.StatisticalResult, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: fd.Namespace, Name: baselineConfigMapName(fd)}
+	exists := true
+	if err := r.apiReader().Get(ctx, key, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("loading baselines: %w", err)
+		}
+		exists = false
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	}
+	if exists && !metav1.IsControlledBy(cm, fd) {
+		return nil, fmt.Errorf("ConfigMap %s exists and is not owned by the FaultDetection, not using it for baselines", key.Name)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+
+	now := time.Now()
+	changed := false
+	var out []detectv1.StatisticalResult
+	for _, spec := range tmpl.Spec.Statistical {
+		cfg, err := anomalyConfig(spec)
+		for _, res := range results {
+			if spec.Metric != "" && spec.Metric != res.Metric {
+				continue
+			}
+			sr := detectv1.StatisticalResult{Metric: res.Metric, Algorithm: string(spec.Algorithm)}
+			if err != nil {
+				sr.Message = err.Error()
+				out = append(out, sr)
+				continue
+			}
+			if res.Error != "" {
+				sr.Message = "no sample: " + res.Error
+				out = append(out, sr)
+				continue
+			}
+			value, perr := strconv.ParseFloat(res.Value, 64)
+			if perr != nil {
+				sr.Message = fmt.Sprintf("invalid sample %q", res.Value)
+				out = append(out, sr)
+				continue
+			}
+
+			stateKey := baselineKey(res.Metric, spec.Algorithm)
+			var state anomaly.State
+			if raw, ok := cm.Data[stateKey]; ok {
+				// A corrupt baseline is discarded rather than blocking detection
+				_ = json.Unmarshal([]byte(raw), &state)
+			}
+			// Samples between intervals are scored but not stored
+			fold := state.Last == 0 || now.Sub(time.Unix(state.Last, 0)) >= tmpl.Spec.Interval.Duration
+			verdict, derr := anomaly.Detect(cfg, &state, now, value)
+			if derr != nil {
+				sr.Message = derr.Error()
+				out = append(out, sr)
+				continue
+			}
+			if fold {
+				raw, _ := json.Marshal(&state)
+				cm.Data[stateKey] = string(raw)
+				changed = true
+			}
+
+			sr.Anomalous = verdict.Anomalous
+			sr.Message = verdict.Message
+			if !verdict.Warming {
+				sr.Score = strconv.FormatFloat(verdict.Score, 'f', 3, 64)
+			}
+			out = append(out, sr)
+		}
+	}
+
+	if exists {
+		if !changed {
+			return out, nil
+		}
+		if err := r.Update(ctx, cm); err != nil {
+			return out, fmt.Errorf("saving baselines: %w", err)
+		}
+		return out, nil
+	}
+	if err := ctrl.SetControllerReference(fd, cm, r.Scheme); err != nil {
+		return out, err
+	}
+	if err := r.Create(ctx, cm); err != nil {
+		return out, fmt.Errorf("saving baselines: %w", err)
+	}
+	return out, nil
+}
+
+// statisticalDetector scores the source results against baselines learnt
+// from earlier evaluations. Baselines are kept in a ConfigMap owned by the
+// FaultDetection.
+type statisticalDetector struct {
+	r *FaultDetectionReconciler
+}
+
+func (d *statisticalDetector) Name() string        { return subDetectorStatistical }
+func (d *statisticalDetector) Kind() detector.Kind { return detector.Analyzer }
+
+func (d *statisticalDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return len(spec.Statistical) > 0
+}
+
+// Validate accepts any settings; invalid ones are reported on every result
+// they apply to.
+func (d *statisticalDetector) Validate(*detectv1.DetectionTemplateSpec) error {
+	return nil
+}
+
+// Evaluate scores the results. Failing to save the baselines is logged, and
+// the scores are still reported.
+func (d *statisticalDetector) Evaluate(ctx context.Context, in detector.Input) (*detector.Outcome, error) {
+	out := &detector.Outcome{}
+	if len(in.Results) == 0 {
+		return out, nil
+	}
+	statResults, err := d.r.runStatisticalDetectors(ctx, in.FaultDetection, in.Template, in.Results)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed running statistical detectors")
+	} else {
+		out.Targets.Baseline = baselineConfigMapName(in.FaultDetection)
+	}
+	out.Targets.Statistical = statResults
+	for _, sr := range statResults {
+		if sr.Anomalous {
+			out.Anomalous = true
+			out.Reason = fmt.Sprintf("metric %s: %s %s", sr.Metric, sr.Algorithm, sr.Message)
+		}
+	}
+	return out, nil
+}
+
+// anomalyConfig converts the API spec into detector parameters.
+func anomalyConfig(spec detectv1.StatisticalSpec) (anomaly.Config, error) {
+	cfg := anomaly.Config{
+		Algorithm:  anomaly.Algorithm(spec.Algorithm),
+		MinSamples: int(spec.MinSamples),
+		WindowSize: int(spec.WindowSize),
+	}
+	var err error
+	if cfg.Sensitivity, err = parseOptionalFloat("sensitivity", spec.Sensitivity); err != nil {
+		return cfg, err
+	}
+	if cfg.Alpha, err = parseOptionalFloat("alpha", spec.Alpha); err != nil {
+		return cfg, err
+	}
+	if spec.Algorithm == detectv1.AlgorithmForecast {
+		if spec.Limit == "" {
+			return cfg, fmt.Errorf("forecast requires a limit")
+		}
+		if cfg.Limit, err = parseOptionalFloat("limit", spec.Limit); err != nil {
+			return cfg, err
+		}
+	}
+	if spec.Horizon != nil {
+		cfg.Horizon = spec.Horizon.Duration
+	}
+	return cfg, nil
+}
+
+func parseOptionalFloat(field, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", field, value)
+	}
+	return f, nil
+}
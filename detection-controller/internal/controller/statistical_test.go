@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/anomaly"
+)
+
+func TestStatisticalBaselinePerInterval(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, detectv1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fd := &detectv1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "latency", UID: "fd-uid"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(fd).Build()
+	r := &FaultDetectionReconciler{Client: c, Scheme: scheme}
+	tmpl := &detectv1.DetectionTemplate{Spec: detectv1.DetectionTemplateSpec{
+		Interval:    metav1.Duration{Duration: time.Minute},
+		Statistical: []detectv1.StatisticalSpec{{Algorithm: detectv1.AlgorithmEWMA}},
+	}}
+	results := []detectv1.Result{{Metric: "p99", Value: "1"}}
+	key := client.ObjectKey{Namespace: "shop", Name: baselineConfigMapName(fd)}
+	count := func() int {
+		var cm corev1.ConfigMap
+		if err := c.Get(ctx, key, &cm); err != nil {
+			t.Fatal(err)
+		}
+		var st anomaly.State
+		if err := json.Unmarshal([]byte(cm.Data[baselineKey("p99", detectv1.AlgorithmEWMA)]), &st); err != nil {
+			t.Fatal(err)
+		}
+		return st.EWMA.Count
+	}
+
+	// Reconciles within the interval, such as those caused by events, do
+	// not add samples
+	for i := 0; i < 3; i++ {
+		if _, err := r.runStatisticalDetectors(ctx, fd, tmpl, results); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(); n != 1 {
+		t.Errorf("samples = %d, want 1 within the interval", n)
+	}
+
+	tmpl.Spec.Interval.Duration = 0
+	if _, err := r.runStatisticalDetectors(ctx, fd, tmpl, results); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("samples = %d, want 2 once the interval passed", n)
+	}
+
+	// A ConfigMap of the same name that the FaultDetection does not own is
+	// left alone
+	other := &detectv1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "latency", UID: "other-uid"}}
+	if _, err := r.runStatisticalDetectors(ctx, other, tmpl, results); err == nil {
+		t.Error("foreign baseline ConfigMap used, want an error")
+	}
+	if n := count(); n != 2 {
+		t.Errorf("samples = %d, want the foreign ConfigMap unchanged", n)
+	}
+}
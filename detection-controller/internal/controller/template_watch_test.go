@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestFaultDetectionsForTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := detectv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	fd := func(ns, name, tmpl string) *detectv1.FaultDetection {
+		return &detectv1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       detectv1.FaultDetectionSpec{TemplateRef: tmpl},
+		}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(fd("a", "cpu-1", "cpu"), fd("b", "cpu-2", "cpu"), fd("a", "disk-1", "disk")).
+		WithIndex(&detectv1.FaultDetection{}, templateRefIndex, func(obj client.Object) []string {
+			return []string{obj.(*detectv1.FaultDetection).Spec.TemplateRef}
+		}).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	reqs := r.faultDetectionsForTemplate(context.Background(), &detectv1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}})
+	got := map[string]bool{}
+	for _, req := range reqs {
+		got[req.String()] = true
+	}
+	if len(got) != 2 || !got["a/cpu-1"] || !got["b/cpu-2"] {
+		t.Errorf("requests = %v, want a/cpu-1 and b/cpu-2", reqs)
+	}
+}
+
+func TestModelStatusChanged(t *testing.T) {
+	old := &detectv1.DetectionTemplate{}
+	ready := old.DeepCopy()
+	ready.Status.ModelReady = true
+	ready.Status.ModelEndpoint = "http://model-cpu.default.svc:8080/predict"
+
+	if !modelStatusChanged.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: ready}) {
+		t.Error("expected model endpoint change to pass")
+	}
+	valid := old.DeepCopy()
+	valid.Status.Message = "ok"
+	if modelStatusChanged.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: valid}) {
+		t.Error("unrelated status change should be filtered")
+	}
+}
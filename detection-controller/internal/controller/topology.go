@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+const (
+	// targetIndex indexes FaultDetections by the object they watch.
+	targetIndex = "spec.target"
+	// clusterTarget is the targetIndex value of FaultDetections without a
+	// target, such as node-wide checks.
+	clusterTarget = "*"
+	// maxDependencyDepth bounds how far upstream a root cause is looked for:
+	// a Service, its pods, their nodes.
+	maxDependencyDepth = 3
+)
+
+// +kubebuilder:rbac:groups="",resources=services,verbs=get
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets;replicasets,verbs=get
+
+// targetKey identifies an object in targetIndex.
+func targetKey(t detectv1.ObjectRef) string {
+	return t.Kind + "/" + t.Namespace + "/" + t.Name
+}
+
+// faultDetectionTarget returns the targetIndex value of a FaultDetection.
+// Namespaced targets without a namespace are in the FaultDetection's.
+func faultDetectionTarget(obj client.Object) []string {
+	fd := obj.(*detectv1.FaultDetection)
+	if fd.Spec.Target == nil {
+		return []string{clusterTarget}
+	}
+	return []string{targetKey(fd.Spec.Target.WithDefaultNamespace(fd.Namespace))}
+}
+
+// sameObject compares object references, ignoring the API version.
+func sameObject(a, b detectv1.ObjectRef) bool {
+	return a.Kind == b.Kind && a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+// rootCause returns the detection of an anomalous object the target of fd
+// depends on, nearest dependencies first, or nil if there is none. A pod
+// depends on its node and claims, a Service on the pods it selects, and a
+// workload on its claims. When the upstream detection is itself a symptom,
+// its root cause is returned.
+func (r *FaultDetectionReconciler) rootCause(ctx context.Context, fd *detectv1.FaultDetection) (*detectv1.RootCause, error) {
+	if fd.Spec.Target == nil {
+		return nil, nil
+	}
+	target := fd.Spec.Target.WithDefaultNamespace(fd.Namespace)
+	seen := map[string]bool{targetKey(target): true}
+	level := []detectv1.ObjectRef{target}
+	for depth := 0; depth < maxDependencyDepth && len(level) > 0; depth++ {
+		var next []detectv1.ObjectRef
+		for _, obj := range level {
+			deps, err := r.dependencies(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			for _, dep := range deps {
+				if seen[targetKey(dep)] {
+					continue
+				}
+				seen[targetKey(dep)] = true
+				rc, err := r.anomalyOf(ctx, fd, dep)
+				if err != nil || rc != nil {
+					return rc, err
+				}
+				next = append(next, dep)
+			}
+		}
+		level = next
+	}
+	return nil, nil
+}
+
+// anomalyOf returns the root cause recorded by an anomalous FaultDetection,
+// other than fd, that reports obj: one that targets it, or a check without
+// target whose node or alert results list it.
+func (r *FaultDetectionReconciler) anomalyOf(ctx context.Context, fd *detectv1.FaultDetection, obj detectv1.ObjectRef) (*detectv1.RootCause, error) {
+	var candidates []detectv1.FaultDetection
+	for _, key := range []string{targetKey(obj), clusterTarget} {
+		var list detectv1.FaultDetectionList
+		if err := r.List(ctx, &list, client.MatchingFields{targetIndex: key}); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, list.Items...)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := &candidates[i], &candidates[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+
+	for i := range candidates {
+		up := &candidates[i]
+		if up.Namespace == fd.Namespace && up.Name == fd.Name {
+			continue
+		}
+		if !up.Status.Anomalous || !reports(up, obj) {
+			continue
+		}
+		if up.Status.RootCause != nil {
+			return up.Status.RootCause.DeepCopy(), nil
+		}
+		return &detectv1.RootCause{Namespace: up.Namespace, Name: up.Name, Object: obj}, nil
+	}
+	return nil, nil
+}
+
+// reports tells whether the anomaly of fd concerns obj.
+func reports(fd *detectv1.FaultDetection, obj detectv1.ObjectRef) bool {
+	if fd.Spec.Target != nil {
+		return sameObject(fd.Spec.Target.WithDefaultNamespace(fd.Namespace), obj)
+	}
+	for _, nr := range fd.Status.NodeResults {
+		if obj.Kind == "Node" && nr.NodeName == obj.Name && !nr.Ok {
+			return true
+		}
+	}
+	for _, ar := range fd.Status.AlertResults {
+		if sameObject(ar.Target, obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencies returns the objects obj depends on. Objects that no longer
+// exist have none.
+func (r *FaultDetectionReconciler) dependencies(ctx context.Context, obj detectv1.ObjectRef) ([]detectv1.ObjectRef, error) {
+	if obj.Name == "" {
+		return nil, nil
+	}
+	key := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Name}
+	reader := r.apiReader()
+
+	var podSpec *corev1.PodSpec
+	var out []detectv1.ObjectRef
+	switch obj.Kind {
+	case "Pod":
+		var pod corev1.Pod
+		if err := reader.Get(ctx, key, &pod); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if pod.Spec.NodeName != "" {
+			out = append(out, detectv1.ObjectRef{Kind: "Node", Name: pod.Spec.NodeName})
+		}
+		podSpec = &pod.Spec
+	case "Service":
+		var svc corev1.Service
+		if err := reader.Get(ctx, key, &svc); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, nil
+		}
+		var pods corev1.PodList
+		if err := reader.List(ctx, &pods, client.InNamespace(obj.Namespace), client.MatchingLabels(svc.Spec.Selector)); err != nil {
+			return nil, err
+		}
+		for _, p := range pods.Items {
+			out = append(out, detectv1.ObjectRef{Kind: "Pod", Namespace: p.Namespace, Name: p.Name})
+		}
+		return out, nil
+	case "Deployment":
+		var d appsv1.Deployment
+		if err := reader.Get(ctx, key, &d); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		podSpec = &d.Spec.Template.Spec
+	case "DaemonSet":
+		var d appsv1.DaemonSet
+		if err := reader.Get(ctx, key, &d); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		podSpec = &d.Spec.Template.Spec
+	case "ReplicaSet":
+		var rs appsv1.ReplicaSet
+		if err := reader.Get(ctx, key, &rs); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		podSpec = &rs.Spec.Template.Spec
+	case "StatefulSet":
+		var sts appsv1.StatefulSet
+		if err := reader.Get(ctx, key, &sts); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		podSpec = &sts.Spec.Template.Spec
+		replicas := int32(1)
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+		for _, c := range sts.Spec.VolumeClaimTemplates {
+			for i := int32(0); i < replicas; i++ {
+				out = append(out, detectv1.ObjectRef{
+					Kind:      "PersistentVolumeClaim",
+					Namespace: obj.Namespace,
+					Name:      fmt.Sprintf("%s-%s-%d", c.Name, sts.Name, i),
+				})
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	for _, v := range podSpec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			out = append(out, detectv1.ObjectRef{
+				Kind:      "PersistentVolumeClaim",
+				Namespace: obj.Namespace,
+				Name:      v.PersistentVolumeClaim.ClaimName,
+			})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestRootCause(t *testing.T) {
+	labels := map[string]string{"app": "checkout"}
+	pod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec:       corev1.ServiceSpec{Selector: labels},
+	}
+	replicas := int32(2)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+	fd := func(ns, name string, target *detectv1.ObjectRef, anomalous bool) *detectv1.FaultDetection {
+		return &detectv1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       detectv1.FaultDetectionSpec{TemplateRef: "t", Target: target},
+			Status:     detectv1.FaultDetectionStatus{Anomalous: anomalous},
+		}
+	}
+	nodeWide := fd("ops", "nodes-ready", nil, true)
+	nodeWide.Status.NodeResults = []detectv1.NodeResult{{NodeName: "worker-1", Ok: true}, {NodeName: "worker-2"}}
+
+	podRef := func(name string) *detectv1.ObjectRef {
+		return &detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: name}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(
+			pod("checkout-0", "worker-1"), pod("checkout-1", "worker-2"), svc, sts,
+			fd("ops", "worker-1", &detectv1.ObjectRef{Kind: "Node", Name: "worker-1"}, true),
+			nodeWide,
+			fd("shop", "checkout-0", podRef("checkout-0"), true),
+			fd("shop", "data-db-1", &detectv1.ObjectRef{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-1"}, true),
+		).
+		WithIndex(&detectv1.FaultDetection{}, targetIndex, faultDetectionTarget).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	tests := []struct {
+		target *detectv1.ObjectRef
+		want   *detectv1.RootCause
+	}{
+		// The node's own detection
+		{podRef("checkout-0"), &detectv1.RootCause{Namespace: "ops", Name: "worker-1",
+			Object: detectv1.ObjectRef{Kind: "Node", Name: "worker-1"}}},
+		// A node-wide check that lists the node as not ready
+		{podRef("checkout-1"), &detectv1.RootCause{Namespace: "ops", Name: "nodes-ready",
+			Object: detectv1.ObjectRef{Kind: "Node", Name: "worker-2"}}},
+		// The nearest anomalous dependency, a selected pod whose own status
+		// names no root cause yet
+		{&detectv1.ObjectRef{Kind: "Service", Namespace: "shop", Name: "checkout"},
+			&detectv1.RootCause{Namespace: "shop", Name: "checkout-0",
+				Object: detectv1.ObjectRef{Kind: "Pod", Namespace: "shop", Name: "checkout-0"}}},
+		{&detectv1.ObjectRef{Kind: "StatefulSet", Namespace: "shop", Name: "db"},
+			&detectv1.RootCause{Namespace: "shop", Name: "data-db-1",
+				Object: detectv1.ObjectRef{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-1"}}},
+		{&detectv1.ObjectRef{Kind: "Node", Name: "worker-1"}, nil},
+		{podRef("gone"), nil},
+	}
+	for _, tt := range tests {
+		got, err := r.rootCause(context.Background(), fd("shop", "under-test", tt.target, true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("rootCause(%s) = %+v, want %+v", targetKey(*tt.target), got, tt.want)
+		}
+	}
+
+	// A symptom passes its own root cause on
+	var checkout0 detectv1.FaultDetection
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "shop", Name: "checkout-0"}, &checkout0); err != nil {
+		t.Fatal(err)
+	}
+	checkout0.Status.Symptom = true
+	checkout0.Status.RootCause = tests[0].want
+	if err := c.Update(context.Background(), &checkout0); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.rootCause(context.Background(),
+		fd("shop", "under-test", &detectv1.ObjectRef{Kind: "Service", Namespace: "shop", Name: "checkout"}, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Name != "worker-1" {
+		t.Errorf("rootCause = %+v, want the node detection", got)
+	}
+}
+
+func TestRootCauseDefaultsTargetNamespace(t *testing.T) {
+	replicas := int32(1)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+		},
+	}
+	// Neither detection names the namespace of its target
+	claim := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "data-db-0"},
+		Spec: detectv1.FaultDetectionSpec{TemplateRef: "t",
+			Target: &detectv1.ObjectRef{Kind: "PersistentVolumeClaim", Name: "data-db-0"}},
+		Status: detectv1.FaultDetectionStatus{Anomalous: true},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(maintenanceScheme(t)).
+		WithObjects(sts, claim).
+		WithIndex(&detectv1.FaultDetection{}, targetIndex, faultDetectionTarget).
+		Build()
+	r := &FaultDetectionReconciler{Client: c}
+
+	fd := &detectv1.FaultDetection{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db"},
+		Spec:       detectv1.FaultDetectionSpec{TemplateRef: "t", Target: &detectv1.ObjectRef{Kind: "StatefulSet", Name: "db"}},
+	}
+	got, err := r.rootCause(context.Background(), fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := detectv1.RootCause{Namespace: "shop", Name: "data-db-0",
+		Object: detectv1.ObjectRef{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-0"}}
+	if got == nil || *got != want {
+		t.Errorf("rootCause = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+// defaultRangeStep is used when a RangeSpec does not set Step.
+const defaultRangeStep = 30 * time.Second
+
+// rangeStep returns the effective resolution for a range query.
+func rangeStep(spec *detectv1.RangeSpec) time.Duration {
+	step := defaultRangeStep
+	if spec.Step != nil && spec.Step.Duration > 0 {
+		step = spec.Step.Duration
+	}
+	if step > spec.Lookback.Duration {
+		step = spec.Lookback.Duration
+	}
+	return step
+}
+
+// aggregateWindow reduces the samples of a range query to one value.
+func aggregateWindow(points []promclient.Point, spec *detectv1.RangeSpec) (float64, error) {
+	if len(points) == 0 {
+		return 0, promclient.ErrNoData
+	}
+
+	switch spec.Aggregation {
+	case detectv1.AggregationAvg:
+		sum := 0.0
+		for _, p := range points {
+			sum += p.Value
+		}
+		return sum / float64(len(points)), nil
+
+	case detectv1.AggregationMax:
+		hi := math.Inf(-1)
+		for _, p := range points {
+			hi = math.Max(hi, p.Value)
+		}
+		return hi, nil
+
+	case detectv1.AggregationMin:
+		lo := math.Inf(1)
+		for _, p := range points {
+			lo = math.Min(lo, p.Value)
+		}
+		return lo, nil
+
+	case detectv1.AggregationP95:
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Value
+		}
+		sort.Float64s(values)
+		// nearest-rank percentile
+		rank := int(math.Ceil(0.95*float64(len(values)))) - 1
+		return values[rank], nil
+
+	case detectv1.AggregationRateOfChange:
+		// per-second change between the first and last sample
+		first, last := points[0], points[len(points)-1]
+		elapsed := last.Time.Sub(first.Time).Seconds()
+		if elapsed <= 0 {
+			return 0, nil
+		}
+		return (last.Value - first.Value) / elapsed, nil
+
+	case detectv1.AggregationCountAbove, detectv1.AggregationFractionAbove:
+		threshold, err := strconv.ParseFloat(spec.Threshold, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid threshold %q for %s", spec.Threshold, spec.Aggregation)
+		}
+		count := 0
+		for _, p := range points {
+			if p.Value > threshold {
+				count++
+			}
+		}
+		if spec.Aggregation == detectv1.AggregationFractionAbove {
+			return float64(count) / float64(len(points)), nil
+		}
+		return float64(count), nil
+
+	default:
+		return 0, fmt.Errorf("unsupported aggregation %q", spec.Aggregation)
+	}
+}
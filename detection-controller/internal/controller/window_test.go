@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/promclient"
+)
+
+func TestAggregateWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var points []promclient.Point
+	// 20 samples one minute apart: 10 at 50%, then 10 at 95%
+	for i := 0; i < 20; i++ {
+		v := 50.0
+		if i >= 10 {
+			v = 95
+		}
+		points = append(points, promclient.Point{Time: start.Add(time.Duration(i) * time.Minute), Value: v})
+	}
+
+	tests := []struct {
+		agg       detectv1.WindowAggregation
+		threshold string
+		want      float64
+	}{
+		{agg: detectv1.AggregationAvg, want: 72.5},
+		{agg: detectv1.AggregationMax, want: 95},
+		{agg: detectv1.AggregationMin, want: 50},
+		{agg: detectv1.AggregationP95, want: 95},
+		{agg: detectv1.AggregationRateOfChange, want: 45.0 / (19 * 60)},
+		{agg: detectv1.AggregationCountAbove, threshold: "90", want: 10},
+		{agg: detectv1.AggregationFractionAbove, threshold: "90", want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.agg), func(t *testing.T) {
+			got, err := aggregateWindow(points, &detectv1.RangeSpec{Aggregation: tt.agg, Threshold: tt.threshold})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := aggregateWindow(points, &detectv1.RangeSpec{Aggregation: detectv1.AggregationCountAbove}); err == nil {
+		t.Error("expected error for countAbove without threshold")
+	}
+}
+
+func TestRangeStep(t *testing.T) {
+	spec := &detectv1.RangeSpec{Lookback: metav1.Duration{Duration: 10 * time.Minute}}
+	if got := rangeStep(spec); got != defaultRangeStep {
+		t.Errorf("default step = %v", got)
+	}
+	spec.Lookback.Duration = 10 * time.Second
+	if got := rangeStep(spec); got != 10*time.Second {
+		t.Errorf("step should not exceed lookback, got %v", got)
+	}
+}
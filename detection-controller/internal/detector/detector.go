@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package detector defines the interface detection plugins implement and
+// the registry the FaultDetection reconciler dispatches through.
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// Kind tells when a detector runs during an evaluation.
+type Kind int
+
+const (
+	// Source detectors read a data source. The first enabled source in
+	// registry order evaluates a template.
+	Source Kind = iota
+	// Analyzers run after the source detector on its results.
+	Analyzer
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Source:
+		return "source"
+	case Analyzer:
+		return "analyzer"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Input is what a detector evaluates.
+type Input struct {
+	FaultDetection *detectv1.FaultDetection
+	// Template with parameters and target variables substituted
+	Template *detectv1.DetectionTemplate
+	// Start of the evaluation
+	Now time.Time
+	// Results and raw range query samples of the source detector; only
+	// set for analyzers
+	Results []detectv1.Result
+	Series  map[string][]float64
+	// Per-target results of the source detector; only set for analyzers
+	Targets Targets
+}
+
+// Outcome is a detector's verdict.
+type Outcome struct {
+	Results []detectv1.Result
+	// Raw range query samples by metric, passed on to analyzers
+	Series    map[string][]float64
+	Anomalous bool
+	Reason    string
+	// Targets holds the typed per-target results for FaultDetection status
+	Targets Targets
+}
+
+// Targets holds per-target results. Each detector fills the fields of its
+// own kind and leaves the others empty.
+type Targets struct {
+	Nodes     []detectv1.NodeResult
+	Events    []detectv1.EventResult
+	Logs      []detectv1.LogResult
+	Probes    []detectv1.ProbeResult
+	Alerts    []detectv1.AlertResult
+	Composite []detectv1.SubDetectorResult
+	ML        *detectv1.MLResult
+	// Statistical results, and the ConfigMap their baselines were saved
+	// in when saving succeeded
+	Statistical []detectv1.StatisticalResult
+	Baseline    string
+}
+
+// Detector evaluates one kind of detection configured in a DetectionTemplate.
+type Detector interface {
+	// Name identifies the detector. Errors returned by Evaluate are
+	// reported as a result with this metric name.
+	Name() string
+	Kind() Kind
+	// Enabled reports whether spec configures the detector.
+	Enabled(spec *detectv1.DetectionTemplateSpec) bool
+	// Validate checks the detector's part of a rendered spec. It repeats
+	// the checks that need no cluster access, since templates may have
+	// been admitted without the webhook.
+	Validate(spec *detectv1.DetectionTemplateSpec) error
+	// Evaluate runs the detector. Failures of single queries or targets
+	// belong in the outcome; an error means nothing could be evaluated.
+	Evaluate(ctx context.Context, in Input) (*Outcome, error)
+}
+
+// Registry holds detectors in dispatch order.
+type Registry struct {
+	detectors []Detector
+}
+
+// NewRegistry returns a registry of detectors, in order.
+func NewRegistry(detectors ...Detector) (*Registry, error) {
+	r := &Registry{}
+	for _, d := range detectors {
+		if err := r.Register(d); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds d after the detectors registered so far.
+func (r *Registry) Register(d Detector) error {
+	if d.Name() == "" {
+		return errors.New("detector has no name")
+	}
+	if r.Get(d.Name()) != nil {
+		return fmt.Errorf("detector %q is already registered", d.Name())
+	}
+	r.detectors = append(r.detectors, d)
+	return nil
+}
+
+// Get returns the detector named name, or nil.
+func (r *Registry) Get(name string) Detector {
+	for _, d := range r.detectors {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Source returns the source detector that evaluates spec, or nil when spec
+// configures none.
+func (r *Registry) Source(spec *detectv1.DetectionTemplateSpec) Detector {
+	for _, d := range r.detectors {
+		if d.Kind() == Source && d.Enabled(spec) {
+			return d
+		}
+	}
+	return nil
+}
+
+// Analyzers returns the analyzers spec enables, in order.
+func (r *Registry) Analyzers(spec *detectv1.DetectionTemplateSpec) []Detector {
+	var out []Detector
+	for _, d := range r.detectors {
+		if d.Kind() == Analyzer && d.Enabled(spec) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Validate validates spec with its source detector and analyzers.
+func (r *Registry) Validate(spec *detectv1.DetectionTemplateSpec) error {
+	var errs []error
+	detectors := r.Analyzers(spec)
+	if src := r.Source(spec); src != nil {
+		detectors = append([]Detector{src}, detectors...)
+	}
+	for _, d := range detectors {
+		if err := d.Validate(spec); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
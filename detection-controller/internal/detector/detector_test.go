@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+type fakeDetector struct {
+	name    string
+	kind    Kind
+	enabled func(*detectv1.DetectionTemplateSpec) bool
+	invalid error
+}
+
+func (f *fakeDetector) Name() string { return f.name }
+func (f *fakeDetector) Kind() Kind   { return f.kind }
+func (f *fakeDetector) Enabled(spec *detectv1.DetectionTemplateSpec) bool {
+	return f.enabled(spec)
+}
+func (f *fakeDetector) Validate(*detectv1.DetectionTemplateSpec) error { return f.invalid }
+func (f *fakeDetector) Evaluate(context.Context, Input) (*Outcome, error) {
+	return &Outcome{}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	always := func(*detectv1.DetectionTemplateSpec) bool { return true }
+	hasEvents := func(spec *detectv1.DetectionTemplateSpec) bool { return spec.Events != nil }
+	hasML := func(spec *detectv1.DetectionTemplateSpec) bool { return spec.ML != nil }
+
+	r, err := NewRegistry(
+		&fakeDetector{name: "events", kind: Source, enabled: hasEvents},
+		&fakeDetector{name: "fallback", kind: Source, enabled: always},
+		&fakeDetector{name: "ml", kind: Analyzer, enabled: hasML, invalid: errors.New("bad threshold")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &detectv1.DetectionTemplateSpec{Events: &detectv1.EventSpec{}}
+	if d := r.Source(spec); d == nil || d.Name() != "events" {
+		t.Errorf("source = %v, want the first enabled source", d)
+	}
+	if d := r.Source(&detectv1.DetectionTemplateSpec{}); d == nil || d.Name() != "fallback" {
+		t.Errorf("source = %v, want fallback", d)
+	}
+	if got := r.Analyzers(spec); len(got) != 0 {
+		t.Errorf("analyzers = %v, want none", got)
+	}
+	if err := r.Validate(spec); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+
+	spec.ML = &detectv1.MLSpec{}
+	if got := r.Analyzers(spec); len(got) != 1 || got[0].Name() != "ml" {
+		t.Errorf("analyzers = %v, want ml", got)
+	}
+	if err := r.Validate(spec); err == nil || !strings.Contains(err.Error(), "ml: bad threshold") {
+		t.Errorf("Validate = %v, want the ml error", err)
+	}
+
+	if r.Get("events") == nil || r.Get("missing") != nil {
+		t.Error("Get did not look detectors up by name")
+	}
+	if err := r.Register(&fakeDetector{name: "events", enabled: always}); err == nil {
+		t.Error("expected a duplicate name to be rejected")
+	}
+	if err := r.Register(&fakeDetector{enabled: always}); err == nil {
+		t.Error("expected an unnamed detector to be rejected")
+	}
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export serves labelled anomaly occurrences (collected
+// DetectionFeedback objects) as a training dataset for MLSpec models.
+package export
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// Path is where the dataset is served.
+const Path = "/feedback/export"
+
+// Output formats.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// csvHeader is the column layout of the CSV export: one row per value.
+var csvHeader = []string{
+	"namespace", "feedback", "fault_detection", "template", "target",
+	"occurrence_id", "occurrence_time", "label", "kind", "metric", "timestamp", "value",
+}
+
+// Record is one labelled occurrence in the JSONL export.
+type Record struct {
+	Namespace      string              `json:"namespace"`
+	Feedback       string              `json:"feedback"`
+	FaultDetection string              `json:"faultDetection"`
+	Template       string              `json:"template"`
+	Target         *detectv1.ObjectRef `json:"target,omitempty"`
+	OccurrenceID   string              `json:"occurrenceID"`
+	OccurrenceTime time.Time           `json:"occurrenceTime"`
+	Label          string              `json:"label"`
+	Comment        string              `json:"comment,omitempty"`
+	// Query results when the anomaly was detected
+	Features map[string]float64 `json:"features"`
+	// Metric history around the occurrence
+	Series map[string][]Sample `json:"series,omitempty"`
+}
+
+// Sample is one timestamped value of a series.
+type Sample struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// Handler serves the dataset. Query parameters namespace, template and label
+// filter the records; format selects jsonl (default) or csv.
+type Handler struct {
+	Reader client.Reader
+	// TokenFile holds the bearer token requests must carry. It is read on
+	// every request, so a rotated Secret applies without a restart. Empty
+	// accepts any request, for servers that authenticate clients by TLS
+	// client certificate.
+	TokenFile string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.TokenFile != "" {
+		ok, err := h.authorized(req)
+		if err != nil {
+			logf.FromContext(req.Context()).WithName("export").Error(err, "unable to read token file")
+			http.Error(w, "unable to authenticate", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	q := req.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = FormatJSONL
+	}
+	if format != FormatJSONL && format != FormatCSV {
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+
+	var list detectv1.DetectionFeedbackList
+	var opts []client.ListOption
+	if ns := q.Get("namespace"); ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+	if err := h.Reader.List(req.Context(), &list, opts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var records []Record
+	for i := range list.Items {
+		fb := &list.Items[i]
+		if !fb.Status.Collected || fb.Status.Occurrence == nil {
+			continue
+		}
+		if t := q.Get("template"); t != "" && t != fb.Status.Template {
+			continue
+		}
+		if l := q.Get("label"); l != "" && l != string(fb.Spec.Label) {
+			continue
+		}
+		records = append(records, NewRecord(fb))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].OccurrenceTime.Equal(records[j].OccurrenceTime) {
+			return records[i].OccurrenceTime.Before(records[j].OccurrenceTime)
+		}
+		return records[i].Namespace+"/"+records[i].Feedback < records[j].Namespace+"/"+records[j].Feedback
+	})
+
+	var err error
+	if format == FormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+		err = WriteCSV(w, records)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		err = WriteJSONL(w, records)
+	}
+	if err != nil {
+		logf.FromContext(req.Context()).Error(err, "failed writing feedback export")
+	}
+}
+
+// NewRecord converts collected feedback into a dataset record. Values that
+// do not parse as numbers are dropped.
+func NewRecord(fb *detectv1.DetectionFeedback) Record {
+	occ := fb.Status.Occurrence
+	rec := Record{
+		Namespace:      fb.Namespace,
+		Feedback:       fb.Name,
+		FaultDetection: fb.Spec.FaultDetectionRef,
+		Template:       fb.Status.Template,
+		Target:         fb.Status.Target,
+		OccurrenceID:   occ.ID,
+		OccurrenceTime: occ.StartTime.UTC(),
+		Label:          string(fb.Spec.Label),
+		Comment:        fb.Spec.Comment,
+		Features:       map[string]float64{},
+	}
+	for _, res := range occ.Results {
+		if res.Error != "" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(res.Value, 64); err == nil {
+			rec.Features[res.Metric] = v
+		}
+	}
+	for _, snap := range fb.Status.Snapshots {
+		for _, s := range snap.Samples {
+			v, err := strconv.ParseFloat(s.Value, 64)
+			if err != nil {
+				continue
+			}
+			if rec.Series == nil {
+				rec.Series = map[string][]Sample{}
+			}
+			rec.Series[snap.Metric] = append(rec.Series[snap.Metric], Sample{Time: s.Time.UTC(), Value: v})
+		}
+	}
+	return rec
+}
+
+// WriteJSONL writes one JSON object per record.
+func WriteJSONL(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes records in long form: a "feature" row per query result
+// and a "series" row per history sample.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		target := ""
+		if rec.Target != nil {
+			target = rec.Target.Kind + "/" + rec.Target.Namespace + "/" + rec.Target.Name
+		}
+		row := func(kind, metric string, t time.Time, v float64) error {
+			return cw.Write([]string{
+				rec.Namespace, rec.Feedback, rec.FaultDetection, rec.Template, target,
+				rec.OccurrenceID, rec.OccurrenceTime.Format(time.RFC3339), rec.Label,
+				kind, metric, t.Format(time.RFC3339), strconv.FormatFloat(v, 'f', -1, 64),
+			})
+		}
+		for _, metric := range sortedKeys(rec.Features) {
+			if err := row("feature", metric, rec.OccurrenceTime, rec.Features[metric]); err != nil {
+				return err
+			}
+		}
+		for _, metric := range sortedKeys(rec.Series) {
+			for _, s := range rec.Series[metric] {
+				if err := row("series", metric, s.Time, s.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// authorized reports whether req carries the bearer token of TokenFile.
+func (h *Handler) authorized(req *http.Request) (bool, error) {
+	data, err := os.ReadFile(h.TokenFile)
+	if err != nil {
+		return false, err
+	}
+	want := strings.TrimSpace(string(data))
+	if want == "" {
+		return false, fmt.Errorf("token file %s is empty", h.TokenFile)
+	}
+	got, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Server runs the export endpoint as a manager Runnable.
+type Server struct {
+	Addr    string
+	Handler http.Handler
+	// TLSConfig serves HTTPS when set. Requiring verified client
+	// certificates in it authenticates clients.
+	TLSConfig *tls.Config
+}
+
+// Start serves until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, s.Handler)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("feedback export listener: %w", err)
+	}
+	if s.TLSConfig != nil {
+		ln = tls.NewListener(ln, s.TLSConfig)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection reports false: every replica can serve exports.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
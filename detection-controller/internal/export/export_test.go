@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func feedback(name string, label detectv1.FeedbackLabel, collected bool, start time.Time) *detectv1.DetectionFeedback {
+	fb := &detectv1.DetectionFeedback{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: detectv1.DetectionFeedbackSpec{
+			FaultDetectionRef: "api-latency",
+			Label:             label,
+		},
+	}
+	if collected {
+		fb.Status = detectv1.DetectionFeedbackStatus{
+			Collected: true,
+			Template:  "latency",
+			Target:    &detectv1.ObjectRef{Kind: "Deployment", Namespace: "default", Name: "api"},
+			Occurrence: &detectv1.AnomalyOccurrence{
+				ID:        "1",
+				StartTime: metav1.NewTime(start),
+				Results: []detectv1.Result{
+					{Metric: "p99", Value: "1.250000"},
+					{Metric: "errors", Error: "no data returned"},
+				},
+			},
+			Snapshots: []detectv1.MetricSnapshot{{
+				Metric: "p99",
+				Samples: []detectv1.SnapshotSample{
+					{Time: metav1.NewTime(start.Add(-time.Minute)), Value: "0.3"},
+					{Time: metav1.NewTime(start), Value: "1.25"},
+				},
+			}},
+		}
+	}
+	return fb
+}
+
+func newHandler(t *testing.T, objs ...*detectv1.DetectionFeedback) *Handler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := detectv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	b := fake.NewClientBuilder().WithScheme(scheme)
+	for _, o := range objs {
+		b = b.WithObjects(o)
+	}
+	return &Handler{Reader: b.Build()}
+}
+
+func TestExportJSONL(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := newHandler(t,
+		feedback("fp", detectv1.FeedbackFalsePositive, true, start),
+		feedback("tp", detectv1.FeedbackTruePositive, true, start.Add(time.Hour)),
+		feedback("pending", detectv1.FeedbackTruePositive, false, start),
+	)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2 (uncollected feedback is skipped)", len(lines))
+	}
+	var first Record
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Feedback != "fp" || first.Label != "FalsePositive" {
+		t.Errorf("records not ordered by occurrence time: %+v", first)
+	}
+	if len(first.Features) != 1 || first.Features["p99"] != 1.25 {
+		t.Errorf("features = %v, failed results should be dropped", first.Features)
+	}
+	if got := first.Series["p99"]; len(got) != 2 || got[0].Value != 0.3 {
+		t.Errorf("series = %v", got)
+	}
+}
+
+func TestExportCSVWithFilter(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := newHandler(t,
+		feedback("fp", detectv1.FeedbackFalsePositive, true, start),
+		feedback("tp", detectv1.FeedbackTruePositive, true, start),
+	)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?format=csv&label=TruePositive", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header, one feature row, two series rows
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4: %v", len(rows), rows)
+	}
+	if strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("header = %v", rows[0])
+	}
+	for _, row := range rows[1:] {
+		if row[1] != "tp" || row[7] != "TruePositive" {
+			t.Errorf("unexpected row %v", row)
+		}
+	}
+	if rows[1][8] != "feature" || rows[1][11] != "1.25" || rows[1][4] != "Deployment/default/api" {
+		t.Errorf("feature row = %v", rows[1])
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newHandler(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?format=parquet", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestExportToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := newHandler(t)
+	h.TokenFile = tokenFile
+	get := func(auth string) int {
+		req := httptest.NewRequest(http.MethodGet, Path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	for auth, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		if code := get(auth); code != want {
+			t.Errorf("Authorization %q: status = %d, want %d", auth, code, want)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package harness evaluates FaultDetections offline, for testing
+// DetectionTemplates before they are applied. It runs the controller's
+// FaultDetectionReconciler against a fake API server holding fixture objects,
+// with Prometheus and ML models answered from recorded responses. Probes and
+// HTTP data sources are still called as configured.
+package harness
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/controller"
+	webhookv1alpha1 "github.com/phuongbac/detection-controller/internal/webhook/v1alpha1"
+)
+
+// AnnotationExpect on a FaultDetection states the outcome a test expects:
+// ExpectTrigger or ExpectNone.
+const AnnotationExpect = "detect.failure-recovery.io/expect"
+
+// Expected outcomes.
+const (
+	ExpectTrigger = "trigger"
+	ExpectNone    = "none"
+)
+
+// Fixtures are the recorded responses of the external services templates
+// query.
+type Fixtures struct {
+	Prometheus []PrometheusResponse `json:"prometheus,omitempty"`
+	ML         []MLResponse         `json:"ml,omitempty"`
+}
+
+// PrometheusResponse answers one PromQL query.
+type PrometheusResponse struct {
+	// FaultDetection the response is for, as name or namespace/name. Empty
+	// answers the query for every FaultDetection.
+	FaultDetection string `json:"faultDetection,omitempty"`
+	// Query after parameter substitution, compared without surrounding space
+	Query string `json:"query"`
+	// Body as returned by /api/v1/query, or /api/v1/query_range for range
+	// queries
+	Response json.RawMessage `json:"response"`
+}
+
+// MLResponse answers the model calls of FaultDetections.
+type MLResponse struct {
+	// FaultDetection the response is for, as name or namespace/name. Empty
+	// answers for every FaultDetection.
+	FaultDetection string `json:"faultDetection,omitempty"`
+	// Body as returned by the model for the template's protocol
+	Response json.RawMessage `json:"response"`
+}
+
+// Result is the outcome of evaluating one FaultDetection.
+type Result struct {
+	Namespace string
+	Name      string
+	Template  string
+	// Outcome stated by AnnotationExpect, if any
+	Expect string
+	// Status after evaluation
+	Status detectv1.FaultDetectionStatus
+	// Why the FaultDetection could not be evaluated, such as a missing or
+	// invalid template
+	Err error
+}
+
+// Fires reports whether the evaluation would have sent a trigger. Symptoms
+// and silenced anomalies do not.
+func (r *Result) Fires() bool {
+	return r.Err == nil && r.Status.Anomalous && r.Status.DryRun
+}
+
+// Check compares the outcome with Expect, which defaults to expect.
+func (r *Result) Check(expect string) error {
+	if r.Expect != "" {
+		expect = r.Expect
+	}
+	switch {
+	case r.Err != nil:
+		return r.Err
+	case expect == ExpectTrigger && !r.Fires():
+		return errors.New("expected a trigger, none would fire")
+	case expect == ExpectNone && r.Fires():
+		return errors.New("expected no trigger, one would fire")
+	case expect != "" && expect != ExpectTrigger && expect != ExpectNone:
+		return fmt.Errorf("unknown expectation %q", expect)
+	}
+	return nil
+}
+
+// NewScheme returns the scheme of the objects the harness loads.
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = detectv1.AddToScheme(scheme)
+	return scheme
+}
+
+// LoadObjects decodes the YAML or JSON documents of r.
+func LoadObjects(scheme *runtime.Scheme, r io.Reader) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var objs []client.Object
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", len(objs)+1, err)
+		}
+		cobj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("document %d: %T is not an object", len(objs)+1, obj)
+		}
+		objs = append(objs, cobj)
+	}
+}
+
+// LoadFixtures decodes a YAML or JSON fixtures file.
+func LoadFixtures(data []byte) (*Fixtures, error) {
+	var f Fixtures
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, err
+	}
+	for i, p := range f.Prometheus {
+		if p.Query == "" || len(p.Response) == 0 {
+			return nil, fmt.Errorf("prometheus[%d]: query and response are required", i)
+		}
+	}
+	for i, m := range f.ML {
+		if len(m.Response) == 0 {
+			return nil, fmt.Errorf("ml[%d]: response is required", i)
+		}
+	}
+	return &f, nil
+}
+
+// Run evaluates every FaultDetection among objs once, in order. The other
+// objects are what the reconciler reads: DetectionTemplates, target Nodes
+// and Pods, Events, MaintenanceWindows and so on. FaultDetections are
+// evaluated from an empty status, in dry run. DetectionTemplates and
+// FaultDetections are defaulted and validated as the admission webhooks
+// would; an object they would reject fails the run.
+func Run(ctx context.Context, scheme *runtime.Scheme, objs []client.Object, fixtures *Fixtures) ([]Result, error) {
+	if fixtures == nil {
+		fixtures = &Fixtures{}
+	}
+	srv := &servers{fixtures: fixtures}
+	prometheus := httptest.NewServer(http.HandlerFunc(srv.prometheus))
+	defer prometheus.Close()
+	ml := httptest.NewServer(http.HandlerFunc(srv.ml))
+	defer ml.Close()
+
+	var fds []*detectv1.FaultDetection
+	builder := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&detectv1.FaultDetection{}, &detectv1.DetectionTemplate{},
+			&detectv1.MaintenanceWindow{}, &detectv1.Incident{})
+	for _, obj := range objs {
+		obj = obj.DeepCopyObject().(client.Object)
+		switch o := obj.(type) {
+		case *detectv1.DetectionTemplate:
+			if err := admitTemplate(ctx, o); err != nil {
+				return nil, err
+			}
+			// Data sources are answered from the fixtures
+			if o.Spec.PrometheusAPI != "" {
+				o.Spec.PrometheusAPI = prometheus.URL
+				o.Spec.PrometheusClient = nil
+			}
+			if o.Spec.ML != nil {
+				o.Spec.ML.Endpoint = ml.URL
+				o.Spec.ML.Client = nil
+			}
+		case *detectv1.FaultDetection:
+			if o.Namespace == "" {
+				o.Namespace = "default"
+			}
+			if err := (&webhookv1alpha1.FaultDetectionCustomDefaulter{}).Default(ctx, o); err != nil {
+				return nil, err
+			}
+			o.Status = detectv1.FaultDetectionStatus{}
+			fds = append(fds, o)
+		}
+		builder = builder.WithObjects(obj)
+	}
+	if err := controller.IndexFaultDetections(ctx, indexer{builder}); err != nil {
+		return nil, err
+	}
+	c := builder.Build()
+	// Missing templates are only a warning, reported again by the evaluation
+	validator := &webhookv1alpha1.FaultDetectionCustomValidator{Client: c}
+	for _, fd := range fds {
+		if _, err := validator.ValidateCreate(ctx, fd); err != nil {
+			return nil, err
+		}
+	}
+	r := &controller.FaultDetectionReconciler{Client: c, Scheme: scheme, DryRun: true}
+
+	results := make([]Result, 0, len(fds))
+	for _, fd := range fds {
+		key := client.ObjectKeyFromObject(fd)
+		srv.evaluating(key)
+		res := Result{Namespace: fd.Namespace, Name: fd.Name, Template: fd.Spec.TemplateRef, Expect: fd.Annotations[AnnotationExpect]}
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			res.Err = err
+		}
+		var got detectv1.FaultDetection
+		if err := c.Get(ctx, key, &got); err != nil {
+			return nil, err
+		}
+		res.Status = got.Status
+		if cond := meta.FindStatusCondition(got.Status.Conditions, detectv1.ConditionTemplateAvailable); res.Err == nil &&
+			cond != nil && cond.Status != metav1.ConditionTrue {
+			res.Err = errors.New(cond.Message)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// admitTemplate defaults and validates tmpl as the admission webhooks would.
+func admitTemplate(ctx context.Context, tmpl *detectv1.DetectionTemplate) error {
+	if err := (&webhookv1alpha1.DetectionTemplateCustomDefaulter{}).Default(ctx, tmpl); err != nil {
+		return err
+	}
+	_, err := (&webhookv1alpha1.DetectionTemplateCustomValidator{}).ValidateCreate(ctx, tmpl)
+	return err
+}
+
+// indexer registers the reconciler's field indexes with a fake client.
+type indexer struct{ b *fake.ClientBuilder }
+
+func (i indexer) IndexField(_ context.Context, obj client.Object, field string, extract client.IndexerFunc) error {
+	i.b.WithIndex(obj, field, extract)
+	return nil
+}
+
+// servers answers Prometheus and model requests for the FaultDetection
+// being evaluated.
+type servers struct {
+	fixtures *Fixtures
+
+	mu      sync.Mutex
+	current client.ObjectKey
+}
+
+func (s *servers) evaluating(key client.ObjectKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = key
+}
+
+// forCurrent tells whether a fixture scoped to fd applies to the
+// FaultDetection being evaluated.
+func (s *servers) forCurrent(fd string) bool {
+	if fd == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ns, name, ok := strings.Cut(fd, "/"); ok {
+		return ns == s.current.Namespace && name == s.current.Name
+	}
+	return fd == s.current.Name
+}
+
+func (s *servers) prometheus(w http.ResponseWriter, req *http.Request) {
+	query := strings.TrimSpace(req.FormValue("query"))
+	var fallback json.RawMessage
+	for _, p := range s.fixtures.Prometheus {
+		if strings.TrimSpace(p.Query) != query || !s.forCurrent(p.FaultDetection) {
+			continue
+		}
+		// Responses for the FaultDetection win over shared ones
+		if p.FaultDetection != "" {
+			writeJSON(w, http.StatusOK, p.Response)
+			return
+		}
+		if fallback == nil {
+			fallback = p.Response
+		}
+	}
+	if fallback != nil {
+		writeJSON(w, http.StatusOK, fallback)
+		return
+	}
+	body, _ := json.Marshal(map[string]string{
+		"status":    "error",
+		"errorType": "bad_data",
+		"error":     fmt.Sprintf("no recorded response for query %q", query),
+	})
+	writeJSON(w, http.StatusBadRequest, body)
+}
+
+func (s *servers) ml(w http.ResponseWriter, _ *http.Request) {
+	var fallback json.RawMessage
+	for _, m := range s.fixtures.ML {
+		if !s.forCurrent(m.FaultDetection) {
+			continue
+		}
+		if m.FaultDetection != "" {
+			writeJSON(w, http.StatusOK, m.Response)
+			return
+		}
+		if fallback == nil {
+			fallback = m.Response
+		}
+	}
+	if fallback != nil {
+		writeJSON(w, http.StatusOK, fallback)
+		return
+	}
+	http.Error(w, "no recorded model response", http.StatusNotFound)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const objects = `
+apiVersion: detect.failure-recovery.io/v1alpha1
+kind: DetectionTemplate
+metadata:
+  name: pod-restarts-template
+spec:
+  scope: Pod
+  interval: 30s
+  prometheusAPI: http://prometheus-operated.monitoring.svc:9090
+  parameters:
+    - name: maxRestarts
+      required: true
+  queries:
+    - metric: restarts
+      query: increase(kube_pod_container_status_restarts_total{pod="{{ .Target.Name }}"}[10m])
+  rule: "restarts > {{ .Params.maxRestarts }}"
+  triggerPayload: '{"pod": "{{ .Target.Name }}", "node": "{{ .Node }}"}'
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: checkout-0
+  namespace: shop
+spec:
+  nodeName: worker-1
+  containers: [{name: app, image: shop}]
+---
+apiVersion: detect.failure-recovery.io/v1alpha1
+kind: FaultDetection
+metadata:
+  name: checkout-restarts
+  annotations:
+    detect.failure-recovery.io/expect: trigger
+spec:
+  templateRef: pod-restarts-template
+  target: {kind: Pod, namespace: shop, name: checkout-0}
+  parameters: {maxRestarts: "3"}
+---
+apiVersion: detect.failure-recovery.io/v1alpha1
+kind: FaultDetection
+metadata:
+  name: checkout-restarts-lenient
+spec:
+  templateRef: pod-restarts-template
+  target: {kind: Pod, namespace: shop, name: checkout-0}
+  parameters: {maxRestarts: "10"}
+---
+apiVersion: detect.failure-recovery.io/v1alpha1
+kind: FaultDetection
+metadata:
+  name: no-template
+spec:
+  templateRef: missing
+`
+
+const fixtures = `
+prometheus:
+- query: increase(kube_pod_container_status_restarts_total{pod="checkout-0"}[10m])
+  response: {"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1700000000, "5"]}]}}
+`
+
+func TestRun(t *testing.T) {
+	scheme := NewScheme()
+	objs, err := LoadObjects(scheme, strings.NewReader(objects))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fx, err := LoadFixtures([]byte(fixtures))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Run(context.Background(), scheme, objs, fx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want one per FaultDetection", len(results))
+	}
+
+	strict, lenient, missing := &results[0], &results[1], &results[2]
+	if !strict.Fires() || strict.Namespace != "default" || strict.Status.Results[0].Value != "5.000000" {
+		t.Errorf("strict = %+v, want the recorded value to trigger", strict)
+	}
+	if !strings.Contains(strict.Status.TriggerPayload, `"node": "worker-1"`) {
+		t.Errorf("payload = %s, want the target's node", strict.Status.TriggerPayload)
+	}
+	if lenient.Fires() || lenient.Err != nil {
+		t.Errorf("lenient = %+v, want no trigger", lenient)
+	}
+	if missing.Err == nil {
+		t.Error("missing template evaluated, want an error")
+	}
+
+	if err := strict.Check(ExpectNone); err != nil {
+		t.Errorf("check = %v, want the annotation to override the expectation", err)
+	}
+	if err := lenient.Check(ExpectTrigger); err == nil {
+		t.Error("check passed, want the missed trigger reported")
+	}
+	if err := lenient.Check(""); err != nil {
+		t.Errorf("check = %v, want no expectation to pass", err)
+	}
+}
+
+func TestRunUnrecordedQuery(t *testing.T) {
+	scheme := NewScheme()
+	objs, err := LoadObjects(scheme, strings.NewReader(objects))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Run(context.Background(), scheme, objs[:3], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := results[0].Status.Results; len(res) != 1 || !strings.Contains(res[0].Error, "no recorded response") {
+		t.Errorf("results = %+v, want the unrecorded query reported", res)
+	}
+}
+
+func TestRunAdmission(t *testing.T) {
+	scheme := NewScheme()
+	run := func(docs string) error {
+		objs, err := LoadObjects(scheme, strings.NewReader(docs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Run(context.Background(), scheme, objs, nil)
+		return err
+	}
+
+	// The rule names a metric no query returns
+	invalidTemplate := strings.Replace(objects, `rule: "restarts >`, `rule: "restart >`, 1)
+	if err := run(invalidTemplate); err == nil || !strings.Contains(err.Error(), "spec.rule") {
+		t.Errorf("err = %v, want the template rejected", err)
+	}
+
+	// Pod scoped templates need a target
+	untargeted := objects[:strings.Index(objects, "---\napiVersion: v1")] + `---
+apiVersion: detect.failure-recovery.io/v1alpha1
+kind: FaultDetection
+metadata:
+  name: untargeted
+spec:
+  templateRef: pod-restarts-template
+  parameters: {maxRestarts: "3"}
+`
+	if err := run(untargeted); err == nil || !strings.Contains(err.Error(), "spec.target") {
+		t.Errorf("err = %v, want the FaultDetection rejected", err)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	if _, err := LoadFixtures([]byte("prometheus:\n- query: up\n")); err == nil {
+		t.Error("fixture without response loaded, want an error")
+	}
+	if _, err := LoadFixtures([]byte("promethus: []\n")); err == nil {
+		t.Error("unknown field loaded, want an error")
+	}
+}
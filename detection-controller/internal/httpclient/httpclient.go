@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpclient builds HTTP clients for data sources that need
+// authentication, custom TLS or extra headers.
+package httpclient
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is used when Config.Timeout is not set.
+const DefaultTimeout = 10 * time.Second
+
+// maxCachedClients bounds the clients Cached keeps. The least recently used
+// one is closed when a new config exceeds it.
+const maxCachedClients = 64
+
+// Config holds resolved connection settings. Secret material is passed in
+// already decoded so this package does not depend on the Kubernetes API.
+type Config struct {
+	Timeout time.Duration
+
+	BearerToken string
+	Username    string
+	Password    string
+
+	// PEM encoded CA bundle, client certificate and key
+	CAData   []byte
+	CertData []byte
+	KeyData  []byte
+
+	InsecureSkipVerify bool
+	Headers            map[string]string
+}
+
+// New returns an http.Client that applies cfg to every request.
+func New(cfg Config) (*http.Client, error) {
+	if cfg.BearerToken != "" && cfg.Username != "" {
+		return nil, errors.New("bearer token and basic auth are mutually exclusive")
+	}
+
+	tlsConfig, err := TLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &authRoundTripper{
+			next:     transport,
+			token:    cfg.BearerToken,
+			username: cfg.Username,
+			password: cfg.Password,
+			headers:  cfg.Headers,
+		},
+	}, nil
+}
+
+// cache holds the clients returned by Cached, most recently used first.
+var cache = struct {
+	sync.Mutex
+	lru     *list.List
+	entries map[[sha256.Size]byte]*list.Element
+}{lru: list.New(), entries: map[[sha256.Size]byte]*list.Element{}}
+
+type cacheEntry struct {
+	key    [sha256.Size]byte
+	client *http.Client
+}
+
+// Cached returns the client of cfg, sharing it and its connection pool with
+// earlier calls for the same config. Clients are keyed by the whole config,
+// secret material included, so rotated credentials get a new client.
+func Cached(cfg Config) (*http.Client, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256(data)
+
+	cache.Lock()
+	defer cache.Unlock()
+	if e, ok := cache.entries[key]; ok {
+		cache.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).client, nil
+	}
+	c, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cache.entries[key] = cache.lru.PushFront(&cacheEntry{key: key, client: c})
+	for cache.lru.Len() > maxCachedClients {
+		oldest := cache.lru.Remove(cache.lru.Back()).(*cacheEntry)
+		delete(cache.entries, oldest.key)
+		oldest.client.CloseIdleConnections()
+	}
+	return c, nil
+}
+
+// TLSConfig returns the TLS settings of cfg, for clients other than HTTP.
+func TLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // opt-in for test environments
+	}
+	if len(cfg.CAData) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(cfg.CAData) {
+			return nil, errors.New("no valid certificates found in CA data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(cfg.CertData) > 0 || len(cfg.KeyData) > 0 {
+		cert, err := tls.X509KeyPair(cfg.CertData, cfg.KeyData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// authRoundTripper adds credentials and static headers to outgoing requests.
+type authRoundTripper struct {
+	next     http.RoundTripper
+	token    string
+	username string
+	password string
+	headers  map[string]string
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the
+// transport.
+func (rt *authRoundTripper) CloseIdleConnections() {
+	if c, ok := rt.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for k, v := range rt.headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case rt.token != "":
+		req.Header.Set("Authorization", "Bearer "+rt.token)
+	case rt.username != "":
+		req.SetBasicAuth(rt.username, rt.password)
+	}
+	return rt.next.RoundTrip(req)
+}
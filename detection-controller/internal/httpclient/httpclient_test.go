@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpclient
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCached(t *testing.T) {
+	cfg := Config{BearerToken: "a", Headers: map[string]string{"X-Scope": "team-a"}}
+	c1, err := Cached(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := Cached(Config{BearerToken: "a", Headers: map[string]string{"X-Scope": "team-a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 {
+		t.Error("same config got a new client, want the cached one")
+	}
+
+	// A rotated token gets its own client
+	cfg.BearerToken = "b"
+	if c3, err := Cached(cfg); err != nil || c3 == c1 {
+		t.Errorf("rotated token got the old client (err %v)", err)
+	}
+
+	// Old clients are dropped once the cache is full
+	for i := 0; i < maxCachedClients; i++ {
+		if _, err := Cached(Config{BearerToken: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c4, _ := Cached(Config{BearerToken: "a", Headers: map[string]string{"X-Scope": "team-a"}}); c4 == c1 {
+		t.Error("evicted client returned")
+	}
+
+	if _, err := Cached(Config{BearerToken: "a", Username: "u"}); err == nil {
+		t.Error("invalid config cached, want an error")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inference calls anomaly detection models over HTTP. It speaks the
+// controller's native v1 schema, the KServe v2 / Open Inference Protocol and
+// the legacy format used before versioning. The wire formats are described in
+// docs/ml-inference-protocol.md.
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Protocol selects the wire format.
+type Protocol string
+
+const (
+	// Legacy POSTs a JSON array of {metric, value} and reads {"anomaly": bool}.
+	Legacy Protocol = "legacy"
+	// V1 is the native batched schema with feature vectors, scores and contributions.
+	V1 Protocol = "v1"
+	// V2 is the KServe v2 / Open Inference Protocol.
+	V2 Protocol = "v2"
+)
+
+// V1APIVersion identifies native v1 requests.
+const V1APIVersion = "detect.failure-recovery.io/inference/v1"
+
+// maxResponseBody bounds the size of a model response.
+const maxResponseBody = 4 << 20
+
+// Target identifies the object an instance describes.
+type Target struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Feature is one named input value.
+type Feature struct {
+	Name  string
+	Value float64
+}
+
+// Instance is the input for one target.
+type Instance struct {
+	Target    Target
+	Timestamp time.Time
+	Features  []Feature
+	// Window samples per feature, for features backed by range queries
+	Series map[string][]float64
+}
+
+// FeatureNames returns the feature names in order.
+func (in Instance) FeatureNames() []string {
+	names := make([]string, len(in.Features))
+	for i, f := range in.Features {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Prediction is the model output for one instance.
+type Prediction struct {
+	Target Target
+	// Anomaly is the model's own verdict, when it returns one
+	Anomaly *bool
+	Score   float64
+	// Scored is false when the model returned no score; Score is then
+	// derived from Anomaly
+	Scored bool
+	// Contribution of each feature to the score
+	Contributions map[string]float64
+}
+
+// Contribution is a feature and its share of the score.
+type Contribution struct {
+	Name  string
+	Value float64
+}
+
+// TopContributions returns the n features with the largest absolute contribution.
+func (p Prediction) TopContributions(n int) []Contribution {
+	out := make([]Contribution, 0, len(p.Contributions))
+	for name, v := range p.Contributions {
+		out = append(out, Contribution{Name: name, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ai, aj := abs(out[i].Value), abs(out[j].Value)
+		if ai != aj {
+			return ai > aj
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Client calls one model.
+type Client struct {
+	Protocol   Protocol
+	Endpoint   string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// Predict sends instances to the model and returns one prediction per instance.
+func (c *Client) Predict(ctx context.Context, instances []Instance) ([]Prediction, error) {
+	if len(instances) == 0 {
+		return nil, nil
+	}
+	switch c.Protocol {
+	case V1:
+		return c.predictV1(ctx, instances)
+	case V2:
+		return c.predictV2(ctx, instances)
+	case Legacy, "":
+		return c.predictLegacy(ctx, instances)
+	default:
+		return nil, fmt.Errorf("unsupported inference protocol %q", c.Protocol)
+	}
+}
+
+// post sends body as JSON to url and decodes the response into out.
+func (c *Client) post(ctx context.Context, url string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return fmt.Errorf("reading model response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("model returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding model response: %w", err)
+	}
+	return nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
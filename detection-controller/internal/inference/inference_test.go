@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testInstances = []Instance{
+	{
+		Target:    Target{Kind: "Node", Name: "worker-1"},
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Features:  []Feature{{Name: "cpu", Value: 0.9}, {Name: "mem", Value: 0.4}},
+		Series:    map[string][]float64{"cpu": {0.5, 0.9}},
+	},
+	{
+		Target:    Target{Kind: "Node", Name: "worker-2"},
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Features:  []Feature{{Name: "cpu", Value: 0.1}, {Name: "mem", Value: 0.2}},
+	},
+}
+
+// serve starts a model that checks the request body with check and answers with resp.
+func serve(t *testing.T, wantPath string, check func(body map[string]interface{}), resp string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != wantPath {
+			t.Errorf("path = %q, want %q", r.URL.Path, wantPath)
+		}
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(raw, &body); err != nil && check != nil {
+			t.Errorf("request is not a JSON object: %s", raw)
+		}
+		if check != nil {
+			check(body)
+		}
+		_, _ = io.WriteString(w, resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPredictV1(t *testing.T) {
+	srv := serve(t, "/predict", func(body map[string]interface{}) {
+		if body["apiVersion"] != V1APIVersion || body["model"] != "iforest" {
+			t.Errorf("header fields = %v, %v", body["apiVersion"], body["model"])
+		}
+		instances := body["instances"].([]interface{})
+		first := instances[0].(map[string]interface{})
+		if !reflect.DeepEqual(first["featureNames"], []interface{}{"cpu", "mem"}) {
+			t.Errorf("featureNames = %v", first["featureNames"])
+		}
+		if first["target"].(map[string]interface{})["name"] != "worker-1" || first["timestamp"] != "2025-01-01T00:00:00Z" {
+			t.Errorf("instance = %v", first)
+		}
+	}, `{"predictions":[
+		{"score":0.93,"contributions":{"cpu":0.7,"mem":-0.1}},
+		{"anomaly":false,"score":0.05}
+	]}`)
+
+	c := &Client{Protocol: V1, Endpoint: srv.URL + "/predict", Model: "iforest"}
+	preds, err := c.Predict(context.Background(), testInstances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preds) != 2 || preds[0].Score != 0.93 || preds[1].Anomaly == nil || *preds[1].Anomaly {
+		t.Fatalf("predictions = %+v", preds)
+	}
+	if preds[0].Target.Name != "worker-1" {
+		t.Errorf("target = %+v", preds[0].Target)
+	}
+	top := preds[0].TopContributions(1)
+	if len(top) != 1 || top[0].Name != "cpu" {
+		t.Errorf("top contributions = %+v", top)
+	}
+}
+
+func TestPredictV2(t *testing.T) {
+	srv := serve(t, "/v2/models/iforest/infer", func(body map[string]interface{}) {
+		input := body["inputs"].([]interface{})[0].(map[string]interface{})
+		if input["name"] != "features" || input["datatype"] != "FP64" {
+			t.Errorf("input = %v", input)
+		}
+		if !reflect.DeepEqual(input["shape"], []interface{}{2.0, 2.0}) {
+			t.Errorf("shape = %v", input["shape"])
+		}
+		if !reflect.DeepEqual(input["data"], []interface{}{0.9, 0.4, 0.1, 0.2}) {
+			t.Errorf("data = %v", input["data"])
+		}
+	}, `{"model_name":"iforest","outputs":[
+		{"name":"score","shape":[2],"datatype":"FP64","data":[0.8,0.1]},
+		{"name":"anomaly","shape":[2],"datatype":"BOOL","data":[true,false]},
+		{"name":"contributions","shape":[2,2],"datatype":"FP64","data":[0.6,0.2,0.05,0.05]}
+	]}`)
+
+	c := &Client{Protocol: V2, Endpoint: srv.URL, Model: "iforest"}
+	preds, err := c.Predict(context.Background(), testInstances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preds[0].Score != 0.8 || !*preds[0].Anomaly || *preds[1].Anomaly {
+		t.Errorf("predictions = %+v", preds)
+	}
+	if preds[0].Contributions["mem"] != 0.2 {
+		t.Errorf("contributions = %v", preds[0].Contributions)
+	}
+}
+
+func TestPredictLegacy(t *testing.T) {
+	var got []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = io.WriteString(w, `{"anomaly":true}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{Endpoint: srv.URL}
+	preds, err := c.Predict(context.Background(), testInstances[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["metric"] != "cpu" || got[0]["value"] != "0.900000" {
+		t.Errorf("legacy request = %v", got)
+	}
+	if !*preds[0].Anomaly || preds[0].Score != 1 || preds[0].Scored {
+		t.Errorf("prediction = %+v, want the score derived from the flag", preds[0])
+	}
+}
+
+func TestPredictErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := &Client{Protocol: V1, Endpoint: srv.URL}
+	if _, err := c.Predict(context.Background(), testInstances); err == nil {
+		t.Error("expected error for 503")
+	}
+
+	srv2 := serve(t, "/", nil, `{"predictions":[{"score":1}]}`)
+	c = &Client{Protocol: V1, Endpoint: srv2.URL + "/"}
+	if _, err := c.Predict(context.Background(), testInstances); err == nil {
+		t.Error("expected error for prediction count mismatch")
+	}
+}
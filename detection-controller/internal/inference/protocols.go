@@ -0,0 +1,251 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -------------------- legacy --------------------
+
+type legacySample struct {
+	Metric string    `json:"metric"`
+	Value  string    `json:"value"`
+	Series []float64 `json:"series,omitempty"`
+}
+
+type legacyResponse struct {
+	Anomaly *bool    `json:"anomaly"`
+	Score   *float64 `json:"score"`
+}
+
+// predictLegacy keeps the pre-versioning format: one unbatched request per
+// instance carrying a bare array of metric results.
+func (c *Client) predictLegacy(ctx context.Context, instances []Instance) ([]Prediction, error) {
+	out := make([]Prediction, 0, len(instances))
+	for _, in := range instances {
+		samples := make([]legacySample, len(in.Features))
+		for i, f := range in.Features {
+			samples[i] = legacySample{
+				Metric: f.Name,
+				Value:  strconv.FormatFloat(f.Value, 'f', 6, 64),
+				Series: in.Series[f.Name],
+			}
+		}
+		var resp legacyResponse
+		if err := c.post(ctx, c.Endpoint, samples, &resp); err != nil {
+			return nil, err
+		}
+		p := Prediction{Target: in.Target, Anomaly: resp.Anomaly, Scored: resp.Score != nil}
+		switch {
+		case resp.Score != nil:
+			p.Score = *resp.Score
+		case resp.Anomaly != nil && *resp.Anomaly:
+			p.Score = 1
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// -------------------- v1 --------------------
+
+type v1Request struct {
+	APIVersion string       `json:"apiVersion"`
+	Model      string       `json:"model,omitempty"`
+	Instances  []v1Instance `json:"instances"`
+}
+
+type v1Instance struct {
+	Target       Target               `json:"target"`
+	Timestamp    string               `json:"timestamp"`
+	FeatureNames []string             `json:"featureNames"`
+	Features     []float64            `json:"features"`
+	Series       map[string][]float64 `json:"series,omitempty"`
+}
+
+type v1Response struct {
+	Predictions []v1Prediction `json:"predictions"`
+}
+
+type v1Prediction struct {
+	Anomaly       *bool              `json:"anomaly,omitempty"`
+	Score         float64            `json:"score"`
+	Contributions map[string]float64 `json:"contributions,omitempty"`
+}
+
+func (c *Client) predictV1(ctx context.Context, instances []Instance) ([]Prediction, error) {
+	req := v1Request{APIVersion: V1APIVersion, Model: c.Model}
+	for _, in := range instances {
+		values := make([]float64, len(in.Features))
+		for i, f := range in.Features {
+			values[i] = f.Value
+		}
+		req.Instances = append(req.Instances, v1Instance{
+			Target:       in.Target,
+			Timestamp:    in.Timestamp.UTC().Format(time.RFC3339),
+			FeatureNames: in.FeatureNames(),
+			Features:     values,
+			Series:       in.Series,
+		})
+	}
+
+	var resp v1Response
+	if err := c.post(ctx, c.Endpoint, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Predictions) != len(instances) {
+		return nil, fmt.Errorf("model returned %d predictions for %d instances", len(resp.Predictions), len(instances))
+	}
+
+	out := make([]Prediction, len(instances))
+	for i, p := range resp.Predictions {
+		out[i] = Prediction{
+			Target:        instances[i].Target,
+			Anomaly:       p.Anomaly,
+			Score:         p.Score,
+			Scored:        true,
+			Contributions: p.Contributions,
+		}
+	}
+	return out, nil
+}
+
+// -------------------- v2 (Open Inference Protocol) --------------------
+
+// Names of the tensors exchanged with v2 models.
+const (
+	v2InputFeatures       = "features"
+	v2OutputScore         = "score"
+	v2OutputAnomaly       = "anomaly"
+	v2OutputContributions = "contributions"
+)
+
+type v2Tensor struct {
+	Name       string                 `json:"name"`
+	Shape      []int                  `json:"shape"`
+	Datatype   string                 `json:"datatype"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Data       json.RawMessage        `json:"data"`
+}
+
+type v2Request struct {
+	ID         string                 `json:"id,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Inputs     []v2Tensor             `json:"inputs"`
+}
+
+type v2Response struct {
+	ModelName string     `json:"model_name"`
+	Outputs   []v2Tensor `json:"outputs"`
+}
+
+// v2URL returns the infer URL. Endpoint may be the server root or the full
+// /v2/models/<model>/infer path.
+func (c *Client) v2URL() string {
+	endpoint := strings.TrimSuffix(c.Endpoint, "/")
+	if strings.HasSuffix(endpoint, "/infer") {
+		return endpoint
+	}
+	return fmt.Sprintf("%s/v2/models/%s/infer", endpoint, c.Model)
+}
+
+func (c *Client) predictV2(ctx context.Context, instances []Instance) ([]Prediction, error) {
+	// All instances share the feature layout of the first one
+	names := instances[0].FeatureNames()
+	data := make([]float64, 0, len(instances)*len(names))
+	targets := make([]Target, len(instances))
+	for i, in := range instances {
+		if len(in.Features) != len(names) {
+			return nil, fmt.Errorf("instance %d has %d features, want %d", i, len(in.Features), len(names))
+		}
+		for _, f := range in.Features {
+			data = append(data, f.Value)
+		}
+		targets[i] = in.Target
+	}
+	raw, _ := json.Marshal(data)
+
+	req := v2Request{
+		ID: fmt.Sprintf("%s-%d", c.Model, instances[0].Timestamp.Unix()),
+		Parameters: map[string]interface{}{
+			"targets":   targets,
+			"timestamp": instances[0].Timestamp.UTC().Format(time.RFC3339),
+		},
+		Inputs: []v2Tensor{{
+			Name:       v2InputFeatures,
+			Shape:      []int{len(instances), len(names)},
+			Datatype:   "FP64",
+			Parameters: map[string]interface{}{"feature_names": names},
+			Data:       raw,
+		}},
+	}
+
+	var resp v2Response
+	if err := c.post(ctx, c.v2URL(), req, &resp); err != nil {
+		return nil, err
+	}
+
+	out := make([]Prediction, len(instances))
+	for i := range out {
+		out[i].Target = targets[i]
+	}
+
+	scoreFound := false
+	for _, t := range resp.Outputs {
+		switch t.Name {
+		case v2OutputScore:
+			var scores []float64
+			if err := json.Unmarshal(t.Data, &scores); err != nil || len(scores) != len(instances) {
+				return nil, fmt.Errorf("invalid %q output", v2OutputScore)
+			}
+			for i, s := range scores {
+				out[i].Score = s
+				out[i].Scored = true
+			}
+			scoreFound = true
+		case v2OutputAnomaly:
+			var flags []bool
+			if err := json.Unmarshal(t.Data, &flags); err != nil || len(flags) != len(instances) {
+				return nil, fmt.Errorf("invalid %q output", v2OutputAnomaly)
+			}
+			for i := range flags {
+				out[i].Anomaly = &flags[i]
+			}
+		case v2OutputContributions:
+			var values []float64
+			if err := json.Unmarshal(t.Data, &values); err != nil || len(values) != len(instances)*len(names) {
+				return nil, fmt.Errorf("invalid %q output", v2OutputContributions)
+			}
+			for i := range out {
+				out[i].Contributions = map[string]float64{}
+				for j, name := range names {
+					out[i].Contributions[name] = values[i*len(names)+j]
+				}
+			}
+		}
+	}
+	if !scoreFound {
+		return nil, fmt.Errorf("model response has no %q output", v2OutputScore)
+	}
+	return out, nil
+}
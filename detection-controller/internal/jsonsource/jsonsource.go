@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonsource fetches JSON documents over HTTP and extracts values
+// from them with Kubernetes-style JSONPath.
+package jsonsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// maxResponseBytes bounds the JSON document read from an endpoint.
+const maxResponseBytes = 4 << 20
+
+// Request describes what to fetch.
+type Request struct {
+	URL string
+	// Default GET
+	Method string
+	// Sent as JSON when set
+	Body string
+}
+
+// Fetch requests req with c and decodes the JSON response. Responses
+// without a 2xx status are errors.
+func Fetch(ctx context.Context, c *http.Client, req Request) (interface{}, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL)
+	}
+
+	var doc interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding response of %s: %w", req.URL, err)
+	}
+	return doc, nil
+}
+
+// Parse checks that path is a valid JSONPath.
+func Parse(path string) error {
+	return jsonpath.New("value").Parse(normalize(path))
+}
+
+// Extract returns the single value path selects in doc.
+func Extract(doc interface{}, path string) (interface{}, error) {
+	jp := jsonpath.New("value")
+	if err := jp.Parse(normalize(path)); err != nil {
+		return nil, err
+	}
+	found, err := jp.FindResults(doc)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for _, set := range found {
+		for _, v := range set {
+			values = append(values, v.Interface())
+		}
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("%s matched %d values, want 1", path, len(values))
+	}
+	return values[0], nil
+}
+
+// normalize accepts "{.a.b}", ".a.b", "a.b" and "$.a.b".
+func normalize(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// Number converts an extracted value to a float. Booleans are 1 and 0.
+func Number(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return Number(b)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not a number", v)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("value %s is not a number", String(v))
+}
+
+// String formats an extracted value for comparison with an expected value.
+func String(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
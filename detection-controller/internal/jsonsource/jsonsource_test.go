@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonsource
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const statusPage = `{
+  "status": "UP",
+  "health": "true",
+  "uptime": 3600,
+  "queue": {"depth": "42"},
+  "checks": [
+    {"name": "db", "ok": true, "latencyMs": 12.5},
+    {"name": "cache", "ok": false, "latencyMs": 250}
+  ]
+}`
+
+func TestExtract(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(statusPage), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   string
+		number float64
+		str    string
+	}{
+		{path: "{.uptime}", number: 3600, str: "3600"},
+		{path: ".uptime", number: 3600, str: "3600"},
+		{path: "uptime", number: 3600, str: "3600"},
+		{path: "$.queue.depth", number: 42, str: "42"},
+		{path: "{.checks[?(@.name=='db')].latencyMs}", number: 12.5, str: "12.5"},
+		{path: "{.checks[1].ok}", number: 0, str: "false"},
+		{path: ".health", number: 1, str: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			v, err := Extract(doc, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := Number(v)
+			if err != nil || n != tt.number {
+				t.Errorf("Number = %v, %v; want %v", n, err, tt.number)
+			}
+			if s := String(v); s != tt.str {
+				t.Errorf("String = %q, want %q", s, tt.str)
+			}
+		})
+	}
+
+	status, err := Extract(doc, ".status")
+	if err != nil || String(status) != "UP" {
+		t.Errorf("status = %v, %v; want UP", status, err)
+	}
+	if _, err := Number(status); err == nil {
+		t.Error("expected UP not to be a number")
+	}
+	if _, err := Extract(doc, "{.checks[*].latencyMs}"); err == nil || !strings.Contains(err.Error(), "matched 2 values") {
+		t.Errorf("err = %v, want several values rejected", err)
+	}
+	if _, err := Extract(doc, ".missing"); err == nil {
+		t.Error("expected a missing key to fail")
+	}
+	if err := Parse("{.checks[?(@.name=='db'"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			_, _ = w.Write([]byte(statusPage))
+		case "/query":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+		case "/html":
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	doc, err := Fetch(ctx, srv.Client(), Request{URL: srv.URL + "/status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Extract(doc, ".status"); err != nil || v != "UP" {
+		t.Errorf("status = %v, %v", v, err)
+	}
+
+	doc, err = Fetch(ctx, srv.Client(), Request{URL: srv.URL + "/query", Method: http.MethodPost, Body: `{"n":7}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Extract(doc, ".echo.n"); err != nil || v != 7.0 {
+		t.Errorf("echo = %v, %v", v, err)
+	}
+
+	if _, err := Fetch(ctx, srv.Client(), Request{URL: srv.URL + "/missing"}); err == nil || !strings.Contains(err.Error(), "unexpected status 404") {
+		t.Errorf("err = %v, want status error", err)
+	}
+	if _, err := Fetch(ctx, srv.Client(), Request{URL: srv.URL + "/html"}); err == nil {
+		t.Error("expected a decode error")
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// fakeSource serves logs like the kubelet: sinceTime is applied with second
+// precision and limitBytes cuts the stream anywhere.
+type fakeSource struct {
+	// lines by container, with "/previous" appended for the previous instance
+	lines map[string][]Line
+	err   map[string]error
+}
+
+func (s *fakeSource) Logs(_ context.Context, _, _ string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	key := opts.Container
+	if opts.Previous {
+		key += "/previous"
+	}
+	if err := s.err[key]; err != nil {
+		return nil, err
+	}
+	since := opts.SinceTime.Truncate(time.Second)
+	var b strings.Builder
+	for _, l := range s.lines[key] {
+		if l.Time.Before(since) {
+			continue
+		}
+		b.WriteString(l.Time.Format(time.RFC3339Nano) + " " + l.Text + "\n")
+	}
+	out := b.String()
+	if opts.LimitBytes != nil && int64(len(out)) > *opts.LimitBytes {
+		out = out[:*opts.LimitBytes]
+	}
+	return io.NopCloser(strings.NewReader(out)), nil
+}
+
+func testPod(containerID string, lastTerminated string) *corev1.Pod {
+	status := corev1.ContainerStatus{Name: "app", ContainerID: containerID}
+	if lastTerminated != "" {
+		status.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ContainerID: lastTerminated}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout-0", UID: "uid-1"},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+	}
+}
+
+func texts(lines []Line) []string {
+	var out []string
+	for _, l := range lines {
+		out = append(out, l.Text)
+	}
+	return out
+}
+
+func TestTailerRead(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{lines: map[string][]Line{"app": {
+		{base.Add(-time.Hour), "too old"},
+		{base.Add(100 * time.Millisecond), "one"},
+		{base.Add(200 * time.Millisecond), "two"},
+	}}}
+	tailer := NewTailer(src)
+	pod := testPod("containerd://a", "")
+
+	lines, err := tailer.Read(context.Background(), pod, "app", base.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(lines); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("first read = %v, want [one two]", got)
+	}
+
+	// Lines of the same second are served again and must not repeat
+	src.lines["app"] = append(src.lines["app"], Line{base.Add(300 * time.Millisecond), "three"})
+	lines, err = tailer.Read(context.Background(), pod, "app", base.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(lines); !reflect.DeepEqual(got, []string{"three"}) {
+		t.Errorf("second read = %v, want [three]", got)
+	}
+
+	t.Run("not started", func(t *testing.T) {
+		lines, err := tailer.Read(context.Background(), testPod("", ""), "app", base)
+		if err != nil || lines != nil {
+			t.Errorf("read = %v, %v; want nothing", lines, err)
+		}
+	})
+}
+
+func TestTailerRestart(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{lines: map[string][]Line{"app": {{base, "starting"}}}}
+	tailer := NewTailer(src)
+	if _, err := tailer.Read(context.Background(), testPod("containerd://a", ""), "app", base.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	src.lines["app/previous"] = []Line{{base, "starting"}, {base.Add(time.Second), "panic: nil map"}}
+	src.lines["app"] = []Line{{base.Add(2 * time.Second), "starting again"}}
+	restarted := testPod("containerd://b", "containerd://a")
+	lines, err := tailer.Read(context.Background(), restarted, "app", base.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(lines); !reflect.DeepEqual(got, []string{"panic: nil map", "starting again"}) {
+		t.Errorf("read after restart = %v, want the end of the previous instance first", got)
+	}
+
+	t.Run("previous unavailable", func(t *testing.T) {
+		src.err = map[string]error{"app/previous": errors.New("previous terminated container not found")}
+		src.lines["app"] = append(src.lines["app"], Line{base.Add(3 * time.Second), "serving"})
+		lines, err := tailer.Read(context.Background(), testPod("containerd://c", "containerd://b"), "app", base.Add(-time.Minute))
+		if err == nil {
+			t.Error("expected the previous read error")
+		}
+		if got := texts(lines); !reflect.DeepEqual(got, []string{"serving"}) {
+			t.Errorf("read = %v, want the current lines despite the error", got)
+		}
+	})
+}
+
+func TestTailerLimit(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	long := strings.Repeat("x", MaxReadBytes/2)
+	src := &fakeSource{lines: map[string][]Line{"app": {
+		{base.Add(time.Second), long},
+		{base.Add(2 * time.Second), long},
+		{base.Add(3 * time.Second), "tail"},
+	}}}
+	tailer := NewTailer(src)
+	pod := testPod("containerd://a", "")
+
+	lines, err := tailer.Read(context.Background(), pod, "app", base.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("first read returned %d lines, want the cut-off line dropped", len(lines))
+	}
+	// The first line is served again and counts against the limit
+	for i := 0; i < 2; i++ {
+		more, err := tailer.Read(context.Background(), pod, "app", base.Add(-time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, more...)
+	}
+	if got := texts(lines); len(got) != 3 || got[1] != long || got[2] != "tail" {
+		t.Errorf("reads returned %d lines, want the rest in full", len(got))
+	}
+
+	t.Run("one busy second", func(t *testing.T) {
+		src := &fakeSource{lines: map[string][]Line{"app": {
+			{base.Add(time.Millisecond), long},
+			{base.Add(2 * time.Millisecond), long},
+			{base.Add(3 * time.Millisecond), long},
+			{base.Add(time.Second), "next second"},
+		}}}
+		tailer := NewTailer(src)
+		var got []string
+		for i := 0; i < 3; i++ {
+			lines, err := tailer.Read(context.Background(), pod, "app", base.Add(-time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, texts(lines)...)
+		}
+		if len(got) == 0 || got[len(got)-1] != "next second" {
+			t.Errorf("reads did not get past the busy second")
+		}
+	})
+}
+
+func TestTailerRetain(t *testing.T) {
+	tailer := NewTailer(&fakeSource{})
+	pod := testPod("containerd://a", "")
+	if _, err := tailer.Read(context.Background(), pod, "app", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	tailer.Retain([]corev1.Pod{*pod})
+	if len(tailer.cursors) != 1 {
+		t.Errorf("cursors = %v, want the pod kept", tailer.cursors)
+	}
+	tailer.Retain(nil)
+	if len(tailer.cursors) != 0 {
+		t.Errorf("cursors = %v, want the pod dropped", tailer.cursors)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m, err := Compile([]detectv1.LogPattern{
+		{Name: "panics", Regex: "^panic:"},
+		{Name: "timeouts", Regex: "^timeout$", JSONField: "error.kind"},
+		{Name: "slow", Regex: "^[0-9]{4,}$", JSONField: "latencyMs"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"panic: runtime error", []string{"panics"}},
+		{`{"level":"error","error":{"kind":"timeout"},"latencyMs":12000}`, []string{"timeouts", "slow"}},
+		{`{"error":{"kind":"refused"},"latencyMs":12}`, nil},
+		{`not json "kind":"timeout"`, nil},
+		{`{"error":"timeout"}`, nil},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := Compile([]detectv1.LogPattern{{Name: "bad", Regex: "("}}); err == nil {
+		t.Error("expected invalid regex error")
+	}
+}
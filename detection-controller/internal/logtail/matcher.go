@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtail
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+type pattern struct {
+	name  string
+	re    *regexp.Regexp
+	field []string
+}
+
+// Matcher matches log lines against a template's patterns.
+type Matcher struct {
+	patterns []pattern
+}
+
+// Compile compiles the patterns of a LogSpec.
+func Compile(patterns []detectv1.LogPattern) (*Matcher, error) {
+	m := &Matcher{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", p.Name, err)
+		}
+		cp := pattern{name: p.Name, re: re}
+		if p.JSONField != "" {
+			cp.field = strings.Split(p.JSONField, ".")
+		}
+		m.patterns = append(m.patterns, cp)
+	}
+	return m, nil
+}
+
+// Match returns the names of the patterns matching line.
+func (m *Matcher) Match(line string) []string {
+	var (
+		names  []string
+		obj    map[string]interface{}
+		parsed bool
+	)
+	for _, p := range m.patterns {
+		if p.field == nil {
+			if p.re.MatchString(line) {
+				names = append(names, p.name)
+			}
+			continue
+		}
+		if !parsed {
+			parsed = true
+			if strings.HasPrefix(strings.TrimSpace(line), "{") {
+				_ = json.Unmarshal([]byte(line), &obj)
+			}
+		}
+		if v, ok := lookup(obj, p.field); ok && p.re.MatchString(v) {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+// lookup returns the field at path as a string.
+func lookup(obj map[string]interface{}, path []string) (string, bool) {
+	var cur interface{} = obj
+	for _, key := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if cur, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", false
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err == nil
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logtail incrementally reads container logs through the
+// Kubernetes API and matches them against patterns.
+//
+// Reads are positioned by log timestamps rather than byte offsets, so log
+// rotation on the node cannot make a tailer skip or repeat lines. When a
+// container restarts, the last lines of the previous instance are read
+// before the new one, since they usually explain the restart.
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MaxReadBytes bounds the log bytes fetched per container and read. Lines
+// beyond it are picked up by the next read.
+const MaxReadBytes = 1 << 20
+
+// Source streams container logs.
+type Source interface {
+	Logs(ctx context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+type clientsetSource struct {
+	clientset kubernetes.Interface
+}
+
+// NewSource returns a Source reading logs from the API server.
+func NewSource(clientset kubernetes.Interface) Source {
+	return &clientsetSource{clientset: clientset}
+}
+
+func (s *clientsetSource) Logs(ctx context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return s.clientset.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+}
+
+// Line is one log line with its kubelet timestamp.
+type Line struct {
+	Time time.Time
+	Text string
+}
+
+// cursor is the read position in one container's logs.
+type cursor struct {
+	containerID string
+	last        time.Time
+}
+
+// Tailer remembers how far each container's logs have been read.
+type Tailer struct {
+	source Source
+
+	mu      sync.Mutex
+	cursors map[string]*cursor
+}
+
+// NewTailer returns a Tailer reading from source.
+func NewTailer(source Source) *Tailer {
+	return &Tailer{source: source, cursors: map[string]*cursor{}}
+}
+
+// Read returns the lines the container logged since the previous Read, or
+// since since on the first Read. Lines older than since are never returned.
+// A container that has not started yet has no lines.
+func (t *Tailer) Read(ctx context.Context, pod *corev1.Pod, container string, since time.Time) ([]Line, error) {
+	status := containerStatus(pod, container)
+	if status == nil || status.ContainerID == "" {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := cursorKey(pod.UID, container)
+	c, ok := t.cursors[key]
+	if !ok {
+		c = &cursor{containerID: status.ContainerID}
+		t.cursors[key] = c
+	}
+	if c.last.Before(since) {
+		c.last = since
+	}
+
+	var (
+		lines   []Line
+		prevErr error
+	)
+	if c.containerID != status.ContainerID {
+		// The container restarted since the last read. Its previous
+		// instance is only kept while it is the last terminated one.
+		if term := status.LastTerminationState.Terminated; term != nil && term.ContainerID == c.containerID {
+			lines, prevErr = t.read(ctx, pod, container, true, c)
+			if prevErr != nil {
+				prevErr = fmt.Errorf("reading previous logs of %s/%s: %w", pod.Name, container, prevErr)
+			}
+		}
+		c.containerID = status.ContainerID
+	}
+
+	cur, err := t.read(ctx, pod, container, false, c)
+	if err != nil {
+		return lines, fmt.Errorf("reading logs of %s/%s: %w", pod.Name, container, err)
+	}
+	return append(lines, cur...), prevErr
+}
+
+// Retain drops the cursors of pods that are not in keep.
+func (t *Tailer) Retain(keep []corev1.Pod) {
+	uids := make(map[types.UID]bool, len(keep))
+	for _, p := range keep {
+		uids[p.UID] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.cursors {
+		uid, _, _ := strings.Cut(key, "/")
+		if !uids[types.UID(uid)] {
+			delete(t.cursors, key)
+		}
+	}
+}
+
+// read fetches the lines after c.last and advances it.
+func (t *Tailer) read(ctx context.Context, pod *corev1.Pod, container string, previous bool, c *cursor) ([]Line, error) {
+	limit := int64(MaxReadBytes)
+	rc, err := t.source.Logs(ctx, pod.Namespace, pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		Previous:   previous,
+		Timestamps: true,
+		// sinceTime is sent with second precision and rounded down
+		SinceTime:  &metav1.Time{Time: c.last.Add(time.Nanosecond)},
+		LimitBytes: &limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	// A line cut off by the limit is read in full next time
+	truncated := int64(len(data)) >= limit
+	if truncated && !bytes.HasSuffix(data, []byte("\n")) {
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[:i+1]
+		}
+	}
+
+	var lines []Line
+	for _, raw := range strings.Split(string(data), "\n") {
+		if raw == "" {
+			continue
+		}
+		ts, text, _ := strings.Cut(raw, " ")
+		at, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			continue
+		}
+		// sinceTime has second precision, so earlier lines of that second repeat
+		if !at.After(c.last) {
+			continue
+		}
+		c.last = at
+		lines = append(lines, Line{Time: at, Text: text})
+	}
+	// More than the limit was logged within one second. Reading again from
+	// the same second would return the same lines, so skip the rest of it.
+	if truncated && len(lines) == 0 {
+		c.last = c.last.Truncate(time.Second).Add(time.Second - time.Nanosecond)
+	}
+	return lines, nil
+}
+
+func containerStatus(pod *corev1.Pod, container string) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == container {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+func cursorKey(uid types.UID, container string) string {
+	return string(uid) + "/" + container
+}
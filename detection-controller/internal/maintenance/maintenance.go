@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance evaluates MaintenanceWindows: whether a window is open
+// at a given time and which FaultDetections and RecoveryTriggers it covers.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	// Time zones must resolve in images without a zoneinfo database
+	_ "time/tzdata"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// State tells whether a window is open.
+type State struct {
+	Active bool
+	// Bounds of the open window; Since is zero for a fixed window without start
+	Since, Until time.Time
+	// Next opening of a closed window; zero if it does not open again
+	Next time.Time
+}
+
+// Validate checks the timing fields of spec.
+func Validate(spec *detectv1.MaintenanceWindowSpec) error {
+	_, err := Evaluate(spec, time.Now())
+	if err != nil {
+		return err
+	}
+	if spec.TargetSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.TargetSelector); err != nil {
+			return fmt.Errorf("invalid targetSelector: %w", err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the state of the window described by spec at now.
+func Evaluate(spec *detectv1.MaintenanceWindowSpec, now time.Time) (State, error) {
+	var start, end time.Time
+	if spec.Start != nil {
+		start = spec.Start.Time
+	}
+	if spec.End != nil {
+		end = spec.End.Time
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return State{}, errors.New("end must be after start")
+	}
+
+	if spec.Schedule == "" {
+		if end.IsZero() {
+			return State{}, errors.New("either schedule or end must be set")
+		}
+		switch {
+		case now.Before(start):
+			return State{Next: start}, nil
+		case now.Before(end):
+			return State{Active: true, Since: start, Until: end}, nil
+		}
+		return State{}, nil
+	}
+
+	sched, err := ParseSchedule(spec.Schedule)
+	if err != nil {
+		return State{}, err
+	}
+	if spec.Duration == nil || spec.Duration.Duration <= 0 {
+		return State{}, errors.New("a schedule needs a positive duration")
+	}
+	loc := time.UTC
+	if spec.TimeZone != "" {
+		if loc, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return State{}, fmt.Errorf("invalid timeZone: %w", err)
+		}
+	}
+	dur := spec.Duration.Duration
+
+	// Openings outside [start, end) are ignored, and no window outlasts end
+	inRange := func(t time.Time) bool {
+		return !t.IsZero() && !t.Before(start) && (end.IsZero() || t.Before(end))
+	}
+	until := func(t time.Time) time.Time {
+		if u := t.Add(dur); end.IsZero() || u.Before(end) {
+			return u
+		}
+		return end
+	}
+
+	// The window is open if it opened in (now-dur, now]. Overlapping
+	// openings extend it.
+	st := State{}
+	for t := sched.Next(now.Add(-dur).In(loc)); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		if !inRange(t) {
+			continue
+		}
+		if !st.Active {
+			st.Active, st.Since = true, t
+		}
+		st.Until = until(t)
+	}
+	if st.Active && st.Until.After(now) {
+		return st, nil
+	}
+
+	st = State{}
+	from := now
+	if start.After(from) {
+		// Openings exactly at start count
+		from = start.Add(-time.Nanosecond)
+	}
+	if next := sched.Next(from.In(loc)); inRange(next) {
+		st.Next = next
+	}
+	return st, nil
+}
+
+// Target is what a window is matched against: a FaultDetection, identified
+// by its template, or a RecoveryTrigger, identified by its failure type.
+type Target struct {
+	Template    string
+	FailureType string
+	Namespace   string
+	// Labels of the target object
+	Labels map[string]string
+}
+
+// Matches reports whether spec covers t.
+func Matches(spec *detectv1.MaintenanceWindowSpec, t Target) (bool, error) {
+	if !matchesKind(spec.Templates, spec.FailureTypes, t.Template) ||
+		!matchesKind(spec.FailureTypes, spec.Templates, t.FailureType) {
+		return false, nil
+	}
+	if len(spec.Namespaces) > 0 && !slices.Contains(spec.Namespaces, t.Namespace) {
+		return false, nil
+	}
+	if spec.TargetSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(spec.TargetSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid targetSelector: %w", err)
+		}
+		if !sel.Matches(labels.Set(t.Labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchesKind matches value, if the object has one, against own. A window that only
+// selects the other kind of object does not cover t.
+func matchesKind(own, other []string, value string) bool {
+	switch {
+	case value == "":
+		return true
+	case len(own) > 0:
+		return slices.Contains(own, value)
+	}
+	return len(other) == 0
+}
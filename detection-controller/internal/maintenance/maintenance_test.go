@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"*/15 * * * *", "2025-06-01T12:07:30Z", "2025-06-01T12:15:00Z"},
+		{"0 2 * * *", "2025-06-01T02:00:00Z", "2025-06-02T02:00:00Z"},
+		{"30 22 * * sat", "2025-06-01T12:00:00Z", "2025-06-07T22:30:00Z"},
+		{"0 0 1,15 * *", "2025-06-02T00:00:00Z", "2025-06-15T00:00:00Z"},
+		// Both day fields restricted: either matches
+		{"0 0 13 * fri", "2025-06-01T00:00:00Z", "2025-06-06T00:00:00Z"},
+		{"0 4 * jan-mar/2 7", "2025-06-01T00:00:00Z", "2026-01-04T04:00:00Z"},
+		{"@monthly", "2025-06-01T00:00:00Z", "2025-07-01T00:00:00Z"},
+		{"0 0 30 2 *", "2025-06-01T00:00:00Z", "0001-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Errorf("Next(%q, %s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := ParseSchedule(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	mt := func(s string) *metav1.Time { return &metav1.Time{Time: at(s)} }
+	fixed := &detectv1.MaintenanceWindowSpec{Start: mt("2025-06-01T10:00:00Z"), End: mt("2025-06-01T12:00:00Z")}
+	weekly := &detectv1.MaintenanceWindowSpec{
+		Schedule: "0 22 * * sat",
+		Duration: &metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+		End:      mt("2025-06-30T00:00:00Z"),
+	}
+	tests := []struct {
+		name string
+		spec *detectv1.MaintenanceWindowSpec
+		now  string
+		want State
+	}{
+		{"before fixed", fixed, "2025-06-01T09:00:00Z", State{Next: at("2025-06-01T10:00:00Z")}},
+		{"during fixed", fixed, "2025-06-01T10:00:00Z",
+			State{Active: true, Since: at("2025-06-01T10:00:00Z"), Until: at("2025-06-01T12:00:00Z")}},
+		{"after fixed", fixed, "2025-06-01T12:00:00Z", State{}},
+		// Saturday 22:00 in Berlin is 20:00 UTC in summer
+		{"before weekly", weekly, "2025-06-07T19:00:00Z", State{Next: at("2025-06-07T20:00:00Z")}},
+		{"during weekly", weekly, "2025-06-07T23:59:00Z",
+			State{Active: true, Since: at("2025-06-07T20:00:00Z"), Until: at("2025-06-08T00:00:00Z")}},
+		{"after weekly", weekly, "2025-06-08T00:00:00Z", State{Next: at("2025-06-14T20:00:00Z")}},
+		// The recurrence ends with the window's end
+		{"cut by end", weekly, "2025-06-29T00:00:00Z", State{}},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(tt.spec, at(tt.now))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.Active != tt.want.Active || !got.Since.Equal(tt.want.Since) ||
+			!got.Until.Equal(tt.want.Until) || !got.Next.Equal(tt.want.Next) {
+			t.Errorf("%s: state = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, bad := range []*detectv1.MaintenanceWindowSpec{
+		{},
+		{Start: mt("2025-06-01T12:00:00Z"), End: mt("2025-06-01T10:00:00Z")},
+		{Schedule: "0 22 * * sat"},
+		{Schedule: "0 22 * * sat", Duration: &metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"},
+	} {
+		if _, err := Evaluate(bad, at("2025-06-01T12:00:00Z")); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	spec := &detectv1.MaintenanceWindowSpec{
+		Templates:      []string{"node-not-ready"},
+		TargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+	}
+	tests := []struct {
+		target Target
+		want   bool
+	}{
+		{Target{Template: "node-not-ready", Labels: map[string]string{"pool": "gpu"}}, true},
+		{Target{Template: "node-not-ready", Labels: map[string]string{"pool": "cpu"}}, false},
+		{Target{Template: "pod-crash", Labels: map[string]string{"pool": "gpu"}}, false},
+		// The window selects FaultDetections only
+		{Target{FailureType: "NodeNotReady", Labels: map[string]string{"pool": "gpu"}}, false},
+	}
+	for _, tt := range tests {
+		got, err := Matches(spec, tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+
+	both := &detectv1.MaintenanceWindowSpec{Templates: []string{"node-not-ready"}, FailureTypes: []string{"NodeNotReady"}}
+	if ok, _ := Matches(both, Target{FailureType: "NodeNotReady"}); !ok {
+		t.Error("expected the failure type to select the RecoveryTrigger")
+	}
+	if ok, _ := Matches(both, Target{Template: "node-not-ready"}); !ok {
+		t.Error("expected the template to select the FaultDetection")
+	}
+	all := &detectv1.MaintenanceWindowSpec{Namespaces: []string{"shop"}}
+	if ok, _ := Matches(all, Target{FailureType: "NodeNotReady", Namespace: "shop"}); !ok {
+		t.Error("expected a namespace-only window to match everything in the namespace")
+	}
+}
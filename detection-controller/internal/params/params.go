@@ -0,0 +1,301 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package params substitutes per-FaultDetection variables into
+// DetectionTemplate fields written as Go templates, e.g.
+//
+//	rate(container_cpu_usage_seconds_total{pod="{{ .Target.Name }}"}[5m])
+//
+// Every value is escaped for the context it is rendered into before the
+// template sees it, so a value can never break out of a PromQL string
+// literal, a URL or a JSON string. Host fields are checked after rendering
+// instead. Outside PromQL string literals only
+// Number and Duration parameters may be used, whose values are checked by
+// Resolve.
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+// paramRef matches references to template parameters.
+var paramRef = regexp.MustCompile(`\.Params\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// bareParam matches an action that is a single parameter reference.
+var bareParam = regexp.MustCompile(`^\.Params\.([A-Za-z_][A-Za-z0-9_]*)$`)
+
+var (
+	// promqlNumber matches the PromQL number literals a Number parameter accepts.
+	promqlNumber = regexp.MustCompile(`^[-+]?(([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?|Inf|NaN)$`)
+	// promqlDuration matches PromQL durations such as 1h30m.
+	promqlDuration = regexp.MustCompile(`^([0-9]+(ms|[smhdwy]))+$`)
+)
+
+// Escaper makes a value safe for one output context.
+type Escaper func(string) string
+
+// Target describes the monitored object.
+type Target struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Vars are the variables available to templates.
+type Vars struct {
+	Target Target
+	// Node the target runs on: the target itself for nodes, spec.nodeName for pods
+	Node string
+	// Labels of the target object
+	Labels map[string]string
+	// Template parameters, after defaults and FaultDetection values are applied
+	Params map[string]string
+}
+
+// promqlReplacer escapes a value for a PromQL string literal. PromQL uses Go
+// escaping, so the result is valid inside both "..." and '...'.
+var promqlReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`'`, `\'`,
+	"`", `\x60`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// PromQL escapes a value for use inside a PromQL string literal.
+func PromQL(s string) string {
+	return promqlReplacer.Replace(s)
+}
+
+// urlQueryReplacer escapes the characters url.PathEscape leaves that would
+// still split or change a query value.
+var urlQueryReplacer = strings.NewReplacer("&", "%26", "=", "%3D", "+", "%2B")
+
+// URL escapes a value for use in a URL path segment or query value. Spaces
+// become %20, which both read as a space.
+func URL(s string) string {
+	return urlQueryReplacer.Replace(url.PathEscape(s))
+}
+
+// Host leaves a value as is, for host and address fields. Escaping cannot
+// make a value a valid host name; the rendered field is checked with
+// CheckHost instead.
+func Host(s string) string {
+	return s
+}
+
+// CheckHost reports whether s is a host name or IP address, optionally
+// followed by a port when withPort is set.
+func CheckHost(s string, withPort bool) error {
+	host := s
+	if withPort {
+		h, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Errorf("%q is not host:port: %w", s, err)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%q has an invalid port", s)
+		}
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(strings.ToLower(host)); len(errs) > 0 {
+		return fmt.Errorf("%q is not a host name: %s", host, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// JSON escapes a value for use inside a JSON string.
+func JSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// Render executes text as a template with vars escaped by esc. Text without
+// template actions is returned as is. Unknown variables are an error.
+func Render(text string, vars Vars, esc Escaper) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, escapeVars(vars, esc)); err != nil {
+		return "", fmt.Errorf("rendering %q: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// Parse checks the template syntax of text.
+func Parse(text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+	if _, err := template.New("").Parse(text); err != nil {
+		return fmt.Errorf("parsing %q: %w", text, err)
+	}
+	return nil
+}
+
+// References returns the parameter names text refers to as .Params.<name>.
+func References(text string) []string {
+	var out []string
+	for _, m := range paramRef.FindAllStringSubmatch(text, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// Resolve applies the declared defaults to the values a FaultDetection
+// supplies. Unknown names, missing required parameters and values that do
+// not match their parameter's type are errors.
+func Resolve(declared []detectv1.TemplateParameter, supplied map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(declared))
+	known := make(map[string]bool, len(declared))
+	var missing []string
+	var invalid []string
+	for _, p := range declared {
+		known[p.Name] = true
+		v, ok := supplied[p.Name]
+		if !ok {
+			if p.Required && p.Default == "" {
+				missing = append(missing, p.Name)
+				continue
+			}
+			v = p.Default
+		}
+		if err := CheckValue(p.Type, v); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", p.Name, err))
+		}
+		out[p.Name] = v
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required parameters: %s", strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid parameters: %s", strings.Join(invalid, "; "))
+	}
+
+	var unknown []string
+	for name := range supplied {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameters: %s", strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
+
+// CheckValue reports whether v is a valid value of a parameter of type t.
+// Empty values are valid; they leave the parameter unset.
+func CheckValue(t detectv1.ParameterType, v string) error {
+	if v == "" {
+		return nil
+	}
+	switch t {
+	case detectv1.ParameterNumber:
+		if !promqlNumber.MatchString(v) {
+			return fmt.Errorf("%q is not a number", v)
+		}
+	case detectv1.ParameterDuration:
+		if !promqlDuration.MatchString(v) {
+			return fmt.Errorf("%q is not a duration", v)
+		}
+	}
+	return nil
+}
+
+// CheckPromQL reports template actions in the PromQL query text that are
+// outside string literals and are not a Number or Duration parameter of
+// declared. Escaping only protects values inside string literals; elsewhere
+// a value could add to the query.
+func CheckPromQL(text string, declared []detectv1.TemplateParameter) error {
+	types := make(map[string]detectv1.ParameterType, len(declared))
+	for _, p := range declared {
+		types[p.Name] = p.Type
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		if strings.HasPrefix(text[i:], "{{") {
+			end := strings.Index(text[i+2:], "}}")
+			if end < 0 {
+				return nil // reported by Parse
+			}
+			action := text[i+2 : i+2+end]
+			i += end + 3
+			if quote != 0 {
+				continue
+			}
+			action = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(action, "-"), "-"))
+			m := bareParam.FindStringSubmatch(action)
+			if m == nil || (types[m[1]] != detectv1.ParameterNumber && types[m[1]] != detectv1.ParameterDuration) {
+				return fmt.Errorf("{{%s}} is outside a string literal; only Number and Duration parameters may be used there", action)
+			}
+			continue
+		}
+		switch c := text[i]; {
+		case quote == 0 && (c == '"' || c == '\'' || c == '`'):
+			quote = c
+		case quote != 0 && quote != '`' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return nil
+}
+
+func escapeVars(v Vars, esc Escaper) Vars {
+	out := Vars{
+		Target: Target{
+			Kind:      esc(v.Target.Kind),
+			Namespace: esc(v.Target.Namespace),
+			Name:      esc(v.Target.Name),
+		},
+		Node:   esc(v.Node),
+		Labels: make(map[string]string, len(v.Labels)),
+		Params: make(map[string]string, len(v.Params)),
+	}
+	for k, val := range v.Labels {
+		out.Labels[k] = esc(val)
+	}
+	for k, val := range v.Params {
+		out.Params[k] = esc(val)
+	}
+	return out
+}
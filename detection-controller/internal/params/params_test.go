@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package params
+
+import (
+	"encoding/json"
+	"testing"
+
+	detectv1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+func TestRenderPromQL(t *testing.T) {
+	vars := Vars{
+		Target: Target{Kind: "Pod", Namespace: "shop", Name: "api-7d9f"},
+		Node:   "worker-1",
+		Labels: map[string]string{"app": "api", "app.kubernetes.io/part-of": "shop"},
+		Params: map[string]string{"window": "5m"},
+	}
+	got, err := Render(
+		`rate(http_requests_total{namespace="{{ .Target.Namespace }}",pod="{{ .Target.Name }}",node="{{ .Node }}",app="{{ .Labels.app }}",part_of="{{ index .Labels "app.kubernetes.io/part-of" }}"}[{{ .Params.window }}])`,
+		vars, PromQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `rate(http_requests_total{namespace="shop",pod="api-7d9f",node="worker-1",app="api",part_of="shop"}[5m])`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestRenderEscapesInjection(t *testing.T) {
+	vars := Vars{Params: map[string]string{"job": `api"} or vector(1) or up{job="`}}
+	got, err := Render(`up{job="{{ .Params.job }}"} == 0`, vars, PromQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `up{job="api\"} or vector(1) or up{job=\""} == 0`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	payload, err := Render(`{"pod":"{{ .Params.job }}"}`, vars, JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		t.Fatalf("payload is not valid JSON: %v", err)
+	}
+	if decoded["pod"] != vars.Params["job"] {
+		t.Errorf("pod = %q", decoded["pod"])
+	}
+
+	u, err := Render(`http://hook/recover?pod={{ .Params.job }}`, vars, URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != `http://hook/recover?pod=api%22%7D%20or%20vector%281%29%20or%20up%7Bjob%3D%22` {
+		t.Errorf("url = %s", u)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render(`{{ .Params.missing }}`, Vars{Params: map[string]string{}}, PromQL); err == nil {
+		t.Error("expected error for unknown parameter")
+	}
+	if _, err := Render(`{{ .Target.Name `, Vars{}, PromQL); err == nil {
+		t.Error("expected parse error")
+	}
+	if got, err := Render(`up == 0`, Vars{}, PromQL); err != nil || got != "up == 0" {
+		t.Errorf("plain text = %q, %v", got, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	declared := []detectv1.TemplateParameter{
+		{Name: "threshold", Default: "0.9"},
+		{Name: "job", Required: true},
+	}
+
+	got, err := Resolve(declared, map[string]string{"job": "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["threshold"] != "0.9" || got["job"] != "api" {
+		t.Errorf("params = %v", got)
+	}
+
+	if _, err := Resolve(declared, nil); err == nil || err.Error() != "missing required parameters: job" {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := Resolve(declared, map[string]string{"job": "api", "jbo": "x"}); err == nil || err.Error() != "unknown parameters: jbo" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResolveTypes(t *testing.T) {
+	declared := []detectv1.TemplateParameter{
+		{Name: "window", Type: detectv1.ParameterDuration, Default: "5m"},
+		{Name: "threshold", Type: detectv1.ParameterNumber, Default: "0.9"},
+	}
+	if _, err := Resolve(declared, map[string]string{"window": "1h30m", "threshold": "-1e3"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Resolve(declared, map[string]string{"window": "5m]) or vector(1", "threshold": "1 or up"})
+	want := `invalid parameters: window: "5m]) or vector(1" is not a duration; threshold: "1 or up" is not a number`
+	if err == nil || err.Error() != want {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCheckPromQL(t *testing.T) {
+	declared := []detectv1.TemplateParameter{
+		{Name: "window", Type: detectv1.ParameterDuration},
+		{Name: "job"},
+	}
+	for text, ok := range map[string]bool{
+		`up{job="{{ .Params.job }}"}`:                 true,
+		`up{job='{{ .Params.job }}'}`:                 true,
+		"up{job=`{{ .Params.job }}`}":                 true,
+		`up{job="a\"{{ .Params.job }}"}`:              true,
+		`rate(up[{{- .Params.window -}}])`:            true,
+		`up{job="x"} > {{ .Params.job }}`:             false,
+		`up{job="\\"} > {{ .Params.job }}`:            false,
+		`rate(up{pod="{{ .Target.Name }}"}[5m])`:      true,
+		`rate(up[5m]) and on(pod) {{ .Target.Name }}`: false,
+		`rate(up[{{ index .Params "window" }}])`:      false,
+		`rate(up[{{ .Params.missing }}])`:             false,
+	} {
+		if err := CheckPromQL(text, declared); (err == nil) != ok {
+			t.Errorf("CheckPromQL(%s) = %v, want ok %v", text, err, ok)
+		}
+	}
+}
+
+func TestURLKeepsPathsAndQueries(t *testing.T) {
+	vars := Vars{Params: map[string]string{"host": "svc:8080", "pod": "a b&admin=1"}}
+	u, err := Render(`http://{{ .Params.host }}/pods/{{ .Params.pod }}?pod={{ .Params.pod }}`, vars, URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != `http://svc:8080/pods/a%20b%26admin%3D1?pod=a%20b%26admin%3D1` {
+		t.Errorf("url = %s", u)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, tt := range []struct {
+		addr     string
+		withPort bool
+		ok       bool
+	}{
+		{"redis.shop.svc", false, true},
+		{"redis.shop.svc:6379", true, true},
+		{"10.0.0.1:80", true, true},
+		{"[::1]:80", true, true},
+		{"redis.shop.svc", true, false},
+		{"redis:0", true, false},
+		{"redis/../admin:80", true, false},
+		{"a b", false, false},
+	} {
+		if err := CheckHost(tt.addr, tt.withPort); (err == nil) != tt.ok {
+			t.Errorf("CheckHost(%q, %v) = %v, want ok %v", tt.addr, tt.withPort, err, tt.ok)
+		}
+	}
+}
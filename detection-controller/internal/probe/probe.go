@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package probe runs synthetic HTTP, TCP, gRPC health and DNS checks.
+//
+// A probe never returns an error: every failure, including an unreachable
+// endpoint, is an Outcome with Success unset and a Message saying why.
+// Timeouts are taken from the context.
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxBodyBytes bounds the response body read for BodyRegex.
+const maxBodyBytes = 1 << 20
+
+// Outcome is the result of one probe.
+type Outcome struct {
+	Success bool
+	// HTTP status code, 0 for other protocols
+	StatusCode int
+	Latency    time.Duration
+	Message    string
+}
+
+func failed(start time.Time, format string, args ...interface{}) Outcome {
+	return Outcome{Latency: time.Since(start), Message: fmt.Sprintf(format, args...)}
+}
+
+// HTTPCheck is what an HTTP answer must satisfy.
+type HTTPCheck struct {
+	// Default GET
+	Method string
+	// Accepted codes; any 2xx or 3xx when empty
+	StatusCodes []int
+	// Optional, matched against the response body
+	Body *regexp.Regexp
+}
+
+// HTTP requests url with client. Redirects are not followed, so a 3xx is
+// judged like any other status code.
+func HTTP(ctx context.Context, client *http.Client, url string, check HTTPCheck) Outcome {
+	start := time.Now()
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return failed(start, "invalid request: %v", err)
+	}
+
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return failed(start, "%v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	out := Outcome{StatusCode: resp.StatusCode}
+	var body []byte
+	if check.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	}
+	out.Latency = time.Since(start)
+
+	switch {
+	case !statusAccepted(resp.StatusCode, check.StatusCodes):
+		out.Message = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	case err != nil:
+		out.Message = fmt.Sprintf("reading body: %v", err)
+	case check.Body != nil && !check.Body.Match(body):
+		out.Message = fmt.Sprintf("body does not match %q", check.Body)
+	default:
+		out.Success = true
+	}
+	return out
+}
+
+func statusAccepted(code int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return code >= 200 && code < 400
+	}
+	return slices.Contains(accepted, code)
+}
+
+// TCP opens and closes a connection to addr.
+func TCP(ctx context.Context, addr string) Outcome {
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return failed(start, "%v", err)
+	}
+	_ = conn.Close()
+	return Outcome{Success: true, Latency: time.Since(start)}
+}
+
+// GRPCCheck configures a gRPC health check.
+type GRPCCheck struct {
+	// Checked service; the server as a whole when empty
+	Service string
+	// Plaintext when nil
+	TLS *tls.Config
+	// Sent as request metadata, e.g. authorization
+	Metadata map[string]string
+}
+
+// GRPC calls grpc.health.v1.Health/Check on addr and succeeds when the
+// service is SERVING.
+func GRPC(ctx context.Context, addr string, check GRPCCheck) Outcome {
+	start := time.Now()
+	creds := insecure.NewCredentials()
+	if check.TLS != nil {
+		creds = credentials.NewTLS(check.TLS)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return failed(start, "%v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	for k, v := range check.Metadata {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: check.Service})
+	if err != nil {
+		return failed(start, "%v", err)
+	}
+	out := Outcome{Latency: time.Since(start)}
+	if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+		out.Success = true
+	} else {
+		out.Message = fmt.Sprintf("status %s", resp.GetStatus())
+	}
+	return out
+}
+
+// DNS resolves host with resolver, or the default resolver when nil, and
+// succeeds when it has at least one address.
+func DNS(ctx context.Context, resolver *net.Resolver, host string) Outcome {
+	start := time.Now()
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return failed(start, "%v", err)
+	}
+	if len(addrs) == 0 {
+		return failed(start, "no addresses for %s", host)
+	}
+	return Outcome{Success: true, Latency: time.Since(start)}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case "/degraded":
+			_, _ = w.Write([]byte(`{"status":"degraded"}`))
+		case "/moved":
+			http.Redirect(w, r, "/healthz", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.Error(w, "boom", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	ok := regexp.MustCompile(`"status":"ok"`)
+
+	tests := []struct {
+		name    string
+		path    string
+		check   HTTPCheck
+		success bool
+		code    int
+		message string
+	}{
+		{name: "healthy", path: "/healthz", check: HTTPCheck{Body: ok}, success: true, code: 200},
+		{name: "body mismatch", path: "/degraded", check: HTTPCheck{Body: ok}, code: 200, message: "body does not match"},
+		{name: "server error", path: "/broken", code: 503, message: "unexpected status 503"},
+		{name: "expected error", path: "/broken", check: HTTPCheck{StatusCodes: []int{503}}, success: true, code: 503},
+		{name: "redirect not followed", path: "/moved", check: HTTPCheck{StatusCodes: []int{200}}, code: 302, message: "unexpected status 302"},
+		{name: "method", path: "/healthz", check: HTTPCheck{Method: http.MethodHead}, success: true, code: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := HTTP(context.Background(), srv.Client(), srv.URL+tt.path, tt.check)
+			if out.Success != tt.success || out.StatusCode != tt.code || !strings.Contains(out.Message, tt.message) {
+				t.Errorf("outcome = %+v, want success %v, status %d, message %q", out, tt.success, tt.code, tt.message)
+			}
+			if out.Latency <= 0 {
+				t.Errorf("latency = %s, want it measured", out.Latency)
+			}
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if out := HTTP(ctx, srv.Client(), srv.URL+"/slow", HTTPCheck{}); out.Success || out.Message == "" {
+			t.Errorf("outcome = %+v, want a timeout", out)
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer tlsSrv.Close()
+		if out := HTTP(context.Background(), &http.Client{}, tlsSrv.URL, HTTPCheck{}); out.Success {
+			t.Error("expected an untrusted certificate to fail")
+		}
+		if out := HTTP(context.Background(), tlsSrv.Client(), tlsSrv.URL, HTTPCheck{}); !out.Success {
+			t.Errorf("outcome = %+v, want success with the server's CA", out)
+		}
+	})
+}
+
+func TestTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	if out := TCP(context.Background(), addr); !out.Success {
+		t.Errorf("outcome = %+v, want success", out)
+	}
+	_ = l.Close()
+	if out := TCP(context.Background(), addr); out.Success || out.Message == "" {
+		t.Errorf("outcome = %+v, want connection refused", out)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("checkout.Cart", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+	addr := l.Addr().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if out := GRPC(ctx, addr, GRPCCheck{}); !out.Success {
+		t.Errorf("server outcome = %+v, want success", out)
+	}
+	if out := GRPC(ctx, addr, GRPCCheck{Service: "checkout.Cart"}); out.Success || out.Message != "status NOT_SERVING" {
+		t.Errorf("service outcome = %+v, want NOT_SERVING", out)
+	}
+	if out := GRPC(ctx, addr, GRPCCheck{Service: "unknown"}); out.Success {
+		t.Errorf("unknown service outcome = %+v, want failure", out)
+	}
+	// A TLS handshake against a plaintext server fails
+	tlsCfg := &tls.Config{InsecureSkipVerify: true} //nolint:gosec // test
+	if out := GRPC(ctx, addr, GRPCCheck{TLS: tlsCfg}); out.Success {
+		t.Errorf("tls outcome = %+v, want failure", out)
+	}
+}
+
+func TestDNS(t *testing.T) {
+	if out := DNS(context.Background(), nil, "localhost"); !out.Success {
+		t.Errorf("outcome = %+v, want localhost to resolve", out)
+	}
+	// .invalid never resolves (RFC 6761)
+	if out := DNS(context.Background(), nil, "checkout.invalid"); out.Success || out.Message == "" {
+		t.Errorf("outcome = %+v, want failure", out)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package promclient is a small client for the Prometheus HTTP query API.
+// It works against Prometheus itself and compatible backends such as
+// Thanos Query and Cortex/Mimir.
+package promclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoData is returned when a query succeeds but yields no samples.
+var ErrNoData = errors.New("no data returned")
+
+// maxErrorBody bounds how much of a non-JSON error body is kept.
+const maxErrorBody = 512
+
+// maxResponseBytes bounds the response read from Prometheus. Range queries
+// over many series are the largest; a response this big is refused rather
+// than cut off.
+const maxResponseBytes = 32 << 20
+
+// Client queries one Prometheus-compatible API.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// New returns a Client for the API rooted at address. httpClient carries
+// auth, TLS and timeout settings; http.DefaultClient is used when nil.
+func New(address string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(address, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid prometheus address %q: %w", address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid prometheus address %q: scheme must be http or https", address)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: u, httpClient: httpClient}, nil
+}
+
+// apiResponse is the envelope of every Prometheus API response.
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type queryData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// Sample is one vector element.
+type Sample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+// Series is one range vector element.
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]interface{}  `json:"values"`
+}
+
+// Point is a single sample of a range query.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Query runs an instant query and returns the value of the first sample.
+func (c *Client) Query(ctx context.Context, query string) (float64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+
+	var data queryData
+	if err := c.do(ctx, "/api/v1/query", params, &data); err != nil {
+		return 0, err
+	}
+
+	switch data.ResultType {
+	case "vector":
+		var samples []Sample
+		if err := json.Unmarshal(data.Result, &samples); err != nil {
+			return 0, fmt.Errorf("decoding vector result: %w", err)
+		}
+		if len(samples) == 0 {
+			return 0, ErrNoData
+		}
+		return parseSampleValue(samples[0].Value[1])
+	case "scalar":
+		var value [2]interface{}
+		if err := json.Unmarshal(data.Result, &value); err != nil {
+			return 0, fmt.Errorf("decoding scalar result: %w", err)
+		}
+		return parseSampleValue(value[1])
+	default:
+		return 0, fmt.Errorf("unsupported result type %q", data.ResultType)
+	}
+}
+
+// QueryRange runs a range query and returns the samples of the first series.
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]Point, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", formatTime(start))
+	params.Set("end", formatTime(end))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	var data queryData
+	if err := c.do(ctx, "/api/v1/query_range", params, &data); err != nil {
+		return nil, err
+	}
+	if data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unsupported result type %q", data.ResultType)
+	}
+
+	var series []Series
+	if err := json.Unmarshal(data.Result, &series); err != nil {
+		return nil, fmt.Errorf("decoding matrix result: %w", err)
+	}
+	if len(series) == 0 || len(series[0].Values) == 0 {
+		return nil, ErrNoData
+	}
+
+	points := make([]Point, 0, len(series[0].Values))
+	for _, v := range series[0].Values {
+		ts, ok := v[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected sample timestamp %v", v[0])
+		}
+		value, err := parseSampleValue(v[1])
+		if err != nil {
+			return nil, err
+		}
+		sec := int64(ts)
+		points = append(points, Point{
+			Time:  time.Unix(sec, int64((ts-float64(sec))*1e9)),
+			Value: value,
+		})
+	}
+	return points, nil
+}
+
+// do sends a GET request to path and decodes the data field into out.
+func (c *Client) do(ctx context.Context, path string, params url.Values, out interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return fmt.Errorf("response larger than %d bytes", maxResponseBytes)
+	}
+
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		// Proxies and auth layers often answer with plain text or HTML
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("prometheus returned %s: %s", resp.Status, truncate(string(body)))
+		}
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if apiResp.Status != "success" {
+		return fmt.Errorf("prometheus returned %s: %s: %s", resp.Status, apiResp.ErrorType, apiResp.Error)
+	}
+	return json.Unmarshal(apiResp.Data, out)
+}
+
+func parseSampleValue(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sample value %v", v)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxErrorBody {
+		return s[:maxErrorBody] + "..."
+	}
+	return s
+}
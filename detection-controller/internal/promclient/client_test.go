@@ -0,0 +1,205 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package promclient
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuongbac/detection-controller/internal/httpclient"
+)
+
+func TestQueryEncodesPromQLAndSendsAuth(t *testing.T) {
+	const query = `sum(rate(http_requests_total{job="api",code=~"5.."}[5m])) + 1`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prom/api/v1/query" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("query"); got != query {
+			t.Errorf("query = %q, want %q", got, query)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Scope-OrgID"); got != "team-a" {
+			t.Errorf("X-Scope-OrgID = %q", got)
+		}
+		_, _ = fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"42.5"]}]}}`)
+	}))
+	defer srv.Close()
+
+	hc, err := httpclient.New(httpclient.Config{
+		BearerToken: "s3cret",
+		Headers:     map[string]string{"X-Scope-OrgID": "team-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(srv.URL+"/prom/", hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := c.Query(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42.5 {
+		t.Errorf("value = %v, want 42.5", v)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+		is      error
+	}{
+		{
+			name:    "api error",
+			status:  http.StatusBadRequest,
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: "bad_data: parse error",
+		},
+		{
+			name:    "non json body",
+			status:  http.StatusUnauthorized,
+			body:    "Unauthorized",
+			wantErr: "401 Unauthorized: Unauthorized",
+		},
+		{
+			name:    "oversized body",
+			status:  http.StatusOK,
+			body:    `{"status":"success","data":{"resultType":"vector","result":[]},"pad":"` + strings.Repeat("x", maxResponseBytes) + `"}`,
+			wantErr: "response larger than",
+		},
+		{
+			name:   "empty vector",
+			status: http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			is:     ErrNoData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.Query(context.Background(), "up")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("err = %v, want %v", err, tt.is)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryHonoursContextTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Query(ctx, "up"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestBasicAuthOverTLSWithCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "prom" || pass != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`)
+	}))
+	defer srv.Close()
+
+	// Without the CA the server certificate must be rejected
+	c, _ := New(srv.URL, nil)
+	if _, err := c.Query(context.Background(), "1"); err == nil {
+		t.Fatal("expected TLS verification error")
+	}
+
+	hc, err := httpclient.New(httpclient.Config{
+		Username: "prom",
+		Password: "pw",
+		CAData:   pemCert(t, srv),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ = New(srv.URL, hc)
+	if v, err := c.Query(context.Background(), "1"); err != nil || v != 1 {
+		t.Errorf("Query = %v, %v", v, err)
+	}
+}
+
+func TestQueryRange(t *testing.T) {
+	end := time.Unix(1700000600, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/query_range" || q.Get("start") != "1700000000" || q.Get("end") != "1700000600" || q.Get("step") != "60" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1700000000,"1"],[1700000060.5,"2.5"]]}]}}`)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL, nil)
+	points, err := c.QueryRange(context.Background(), "up", end.Add(-10*time.Minute), end, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 || points[1].Value != 2.5 || points[1].Time.UnixMilli() != 1700000060500 {
+		t.Errorf("points = %+v", points)
+	}
+}
+
+func pemCert(t *testing.T, srv *httptest.Server) []byte {
+	t.Helper()
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
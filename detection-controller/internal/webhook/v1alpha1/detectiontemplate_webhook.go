@@ -0,0 +1,744 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/jsonsource"
+	"github.com/phuongbac/detection-controller/internal/params"
+)
+
+const (
+	// DefaultInterval is used when a template does not set spec.interval.
+	DefaultInterval = 30 * time.Second
+	// MinInterval is the shortest evaluation interval accepted.
+	MinInterval = 5 * time.Second
+)
+
+// eventsMetric is the result name of the matched event count.
+const eventsMetric = "events"
+
+// alertsMetric is the result name of the firing alert count.
+const alertsMetric = "alerts"
+
+// probeLatencySuffix names the result holding a probe's slowest answer.
+const probeLatencySuffix = "_latency"
+
+// parameterName matches names usable as {{ .Params.<name> }}.
+var parameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// nolint:unused
+// log is for logging in this package.
+var detectiontemplatelog = logf.Log.WithName("detectiontemplate-resource")
+
+// SetupDetectionTemplateWebhookWithManager registers the webhook for DetectionTemplate in the manager.
+func SetupDetectionTemplateWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&detectv1alpha1.DetectionTemplate{}).
+		WithValidator(&DetectionTemplateCustomValidator{}).
+		WithDefaulter(&DetectionTemplateCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-detect-failure-recovery-io-v1alpha1-detectiontemplate,mutating=true,failurePolicy=fail,sideEffects=None,groups=detect.failure-recovery.io,resources=detectiontemplates,verbs=create;update,versions=v1alpha1,name=mdetectiontemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// DetectionTemplateCustomDefaulter sets default values on DetectionTemplates.
+type DetectionTemplateCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &DetectionTemplateCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind DetectionTemplate.
+func (d *DetectionTemplateCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	tmpl, ok := obj.(*detectv1alpha1.DetectionTemplate)
+	if !ok {
+		return fmt.Errorf("expected a DetectionTemplate object but got %T", obj)
+	}
+	detectiontemplatelog.Info("Defaulting for DetectionTemplate", "name", tmpl.GetName())
+
+	spec := &tmpl.Spec
+	if spec.Interval.Duration == 0 {
+		spec.Interval = metav1.Duration{Duration: DefaultInterval}
+	}
+	if spec.ML != nil && spec.ML.Protocol == "" {
+		spec.ML.Protocol = detectv1alpha1.InferenceProtocolLegacy
+	}
+	for i := range spec.Queries {
+		if r := spec.Queries[i].Range; r != nil && r.Aggregation == "" {
+			r.Aggregation = detectv1alpha1.AggregationAvg
+		}
+	}
+	if c := spec.Composite; c != nil {
+		for i := range c.Detectors {
+			if p := c.Detectors[i].Prometheus; p != nil && p.Threshold != "" && p.Operator == "" {
+				p.Operator = ">"
+			}
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-detect-failure-recovery-io-v1alpha1-detectiontemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=detect.failure-recovery.io,resources=detectiontemplates,verbs=create;update,versions=v1alpha1,name=vdetectiontemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// DetectionTemplateCustomValidator validates DetectionTemplates on create and update.
+type DetectionTemplateCustomValidator struct{}
+
+var _ webhook.CustomValidator = &DetectionTemplateCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type DetectionTemplate.
+func (v *DetectionTemplateCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	tmpl, ok := obj.(*detectv1alpha1.DetectionTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a DetectionTemplate object but got %T", obj)
+	}
+	detectiontemplatelog.Info("Validation for DetectionTemplate upon creation", "name", tmpl.GetName())
+	return validateDetectionTemplate(tmpl)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type DetectionTemplate.
+func (v *DetectionTemplateCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	tmpl, ok := newObj.(*detectv1alpha1.DetectionTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a DetectionTemplate object for the newObj but got %T", newObj)
+	}
+	detectiontemplatelog.Info("Validation for DetectionTemplate upon update", "name", tmpl.GetName())
+	return validateDetectionTemplate(tmpl)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type DetectionTemplate.
+func (v *DetectionTemplateCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateDetectionTemplate(tmpl *detectv1alpha1.DetectionTemplate) (admission.Warnings, error) {
+	warnings, allErrs := ValidateDetectionTemplateSpec(&tmpl.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(
+		detectv1alpha1.GroupVersion.WithKind("DetectionTemplate").GroupKind(), tmpl.Name, allErrs)
+}
+
+// ValidateDetectionTemplateSpec checks a template for the mistakes the
+// controller cannot recover from at evaluation time.
+func ValidateDetectionTemplateSpec(spec *detectv1alpha1.DetectionTemplateSpec, path *field.Path) (admission.Warnings, field.ErrorList) {
+	var (
+		allErrs  field.ErrorList
+		warnings admission.Warnings
+	)
+
+	switch spec.Scope {
+	case detectv1alpha1.ScopeNode, detectv1alpha1.ScopePod, detectv1alpha1.ScopeCluster:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("scope"), spec.Scope,
+			[]detectv1alpha1.Scope{detectv1alpha1.ScopeNode, detectv1alpha1.ScopePod, detectv1alpha1.ScopeCluster}))
+	}
+	if spec.Interval.Duration < MinInterval {
+		allErrs = append(allErrs, field.Invalid(path.Child("interval"), spec.Interval.Duration.String(),
+			fmt.Sprintf("must be at least %s", MinInterval)))
+	}
+
+	// Option A to G and Composite are mutually exclusive
+	optionA := spec.PrometheusAPI != "" || len(spec.Queries) > 0
+	optionB := spec.APIVersion != "" || spec.Kind != "" || spec.FieldPath != ""
+	var sources []string
+	if optionA && spec.Composite == nil {
+		sources = append(sources, "prometheusAPI/queries")
+	}
+	if optionB {
+		sources = append(sources, "apiVersion/kind/fieldPath")
+	}
+	if spec.Events != nil {
+		sources = append(sources, "events")
+	}
+	if spec.Logs != nil {
+		sources = append(sources, "logs")
+	}
+	if len(spec.Probes) > 0 {
+		sources = append(sources, "probes")
+	}
+	if spec.HTTP != nil {
+		sources = append(sources, "http")
+	}
+	if spec.Alerts != nil {
+		sources = append(sources, "alerts")
+	}
+	if spec.Composite != nil {
+		sources = append(sources, "composite")
+		if len(spec.Queries) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("queries"), "composite templates define queries as prometheus detectors"))
+		}
+	}
+	switch len(sources) {
+	case 0:
+		allErrs = append(allErrs, field.Required(path, "one of prometheusAPI/queries, apiVersion/kind/fieldPath, events, logs, probes, http, alerts or composite is required"))
+	case 1:
+	default:
+		allErrs = append(allErrs, field.Invalid(path, strings.Join(sources, ", "), "only one detection source may be set"))
+	}
+
+	// Metrics that statistical detectors and ML features can refer to
+	metrics := map[string]bool{}
+
+	if optionA && spec.Composite == nil {
+		if spec.PrometheusAPI == "" {
+			allErrs = append(allErrs, field.Required(path.Child("prometheusAPI"), "required with queries"))
+		}
+		if len(spec.Queries) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("queries"), "required with prometheusAPI"))
+		}
+	}
+	if spec.PrometheusAPI != "" {
+		allErrs = append(allErrs, validateURL(path.Child("prometheusAPI"), spec.PrometheusAPI)...)
+	}
+	for i, q := range spec.Queries {
+		qPath := path.Child("queries").Index(i)
+		if q.Metric == "" {
+			allErrs = append(allErrs, field.Required(qPath.Child("metric"), ""))
+		} else if metrics[q.Metric] {
+			allErrs = append(allErrs, field.Duplicate(qPath.Child("metric"), q.Metric))
+		}
+		metrics[q.Metric] = true
+		if strings.TrimSpace(q.Query) == "" {
+			allErrs = append(allErrs, field.Required(qPath.Child("query"), ""))
+		}
+		allErrs = append(allErrs, validateQuery(qPath.Child("query"), q.Query, spec.Parameters)...)
+		if q.Range != nil {
+			allErrs = append(allErrs, validateRange(qPath.Child("range"), q.Range)...)
+		}
+	}
+
+	if optionB {
+		for name, value := range map[string]string{"apiVersion": spec.APIVersion, "kind": spec.Kind, "fieldPath": spec.FieldPath} {
+			if value == "" {
+				allErrs = append(allErrs, field.Required(path.Child(name), "apiVersion, kind and fieldPath must be set together"))
+			}
+		}
+		if spec.Expected == "" {
+			allErrs = append(allErrs, field.Required(path.Child("expected"), "required with fieldPath"))
+		}
+	}
+
+	if spec.Events != nil {
+		allErrs = append(allErrs, validateEvents(path.Child("events"), spec.Events)...)
+		metrics[eventsMetric] = true
+	}
+
+	if spec.Logs != nil {
+		allErrs = append(allErrs, validateLogs(path.Child("logs"), spec.Logs, metrics)...)
+	}
+
+	for i := range spec.Probes {
+		allErrs = append(allErrs, validateProbe(path.Child("probes").Index(i), &spec.Probes[i], spec.Parameters, metrics)...)
+	}
+
+	if spec.HTTP != nil {
+		allErrs = append(allErrs, validateHTTPSource(path.Child("http"), spec.HTTP, spec.Parameters, metrics)...)
+	}
+
+	if spec.Alerts != nil {
+		allErrs = append(allErrs, validateAlerts(path.Child("alerts"), spec.Alerts)...)
+		metrics[alertsMetric] = true
+	}
+
+	if spec.Composite != nil {
+		allErrs = append(allErrs, validateComposite(path.Child("composite"), spec, metrics)...)
+	}
+
+	if spec.Rule != "" {
+		if !(optionA || spec.HTTP != nil) || spec.Composite != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("rule"), "rules apply to prometheusAPI/queries and http templates"))
+		} else {
+			allErrs = append(allErrs, validateRule(path.Child("rule"), spec.Rule, metrics, spec.Parameters)...)
+		}
+	}
+
+	for i, s := range spec.Statistical {
+		allErrs = append(allErrs, validateStatistical(path.Child("statistical").Index(i), s, metrics)...)
+	}
+
+	if spec.ML != nil {
+		errs, w := validateML(path.Child("ml"), spec.ML)
+		allErrs = append(allErrs, errs...)
+		warnings = append(warnings, w...)
+	}
+
+	seen := map[string]bool{}
+	for i, p := range spec.Parameters {
+		pPath := path.Child("parameters").Index(i)
+		switch {
+		case !parameterName.MatchString(p.Name):
+			allErrs = append(allErrs, field.Invalid(pPath.Child("name"), p.Name, "must be a letter or underscore followed by letters, digits or underscores"))
+		case seen[p.Name]:
+			allErrs = append(allErrs, field.Duplicate(pPath.Child("name"), p.Name))
+		}
+		seen[p.Name] = true
+		if err := params.CheckValue(p.Type, p.Default); err != nil {
+			allErrs = append(allErrs, field.Invalid(pPath.Child("default"), p.Default, err.Error()))
+		}
+	}
+	allErrs = append(allErrs, validateTemplated(path.Child("triggerAPI"), spec.TriggerAPI, spec.Parameters)...)
+	allErrs = append(allErrs, validateTemplated(path.Child("triggerPayload"), spec.TriggerPayload, spec.Parameters)...)
+
+	return warnings, allErrs
+}
+
+func validateComposite(path *field.Path, spec *detectv1alpha1.DetectionTemplateSpec, metrics map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	c := spec.Composite
+
+	switch c.Operator {
+	case detectv1alpha1.CompositeAtLeast:
+		if c.MinFiring < 1 || int(c.MinFiring) > len(c.Detectors) {
+			allErrs = append(allErrs, field.Invalid(path.Child("minFiring"), c.MinFiring,
+				fmt.Sprintf("must be between 1 and the number of detectors (%d)", len(c.Detectors))))
+		}
+	default:
+		if c.MinFiring != 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("minFiring"), "only used with operator AtLeast"))
+		}
+	}
+
+	names := map[string]bool{}
+	for i, d := range c.Detectors {
+		dPath := path.Child("detectors").Index(i)
+		if d.Name == "" {
+			allErrs = append(allErrs, field.Required(dPath.Child("name"), ""))
+		} else if names[d.Name] {
+			allErrs = append(allErrs, field.Duplicate(dPath.Child("name"), d.Name))
+		}
+		names[d.Name] = true
+
+		set := 0
+		for _, isSet := range []bool{d.Field != nil, d.Prometheus != nil, d.Statistical != nil, d.ML != nil, d.Probe != nil} {
+			if isSet {
+				set++
+			}
+		}
+		if set != 1 {
+			allErrs = append(allErrs, field.Invalid(dPath, d.Name, "exactly one of field, prometheus, statistical, ml and probe must be set"))
+			continue
+		}
+
+		switch {
+		case d.Field != nil:
+			fPath := dPath.Child("field")
+			for name, value := range map[string]string{"apiVersion": d.Field.APIVersion, "kind": d.Field.Kind, "fieldPath": d.Field.FieldPath} {
+				if value == "" {
+					allErrs = append(allErrs, field.Required(fPath.Child(name), ""))
+				}
+			}
+		case d.Prometheus != nil:
+			pPath := dPath.Child("prometheus")
+			if spec.PrometheusAPI == "" {
+				allErrs = append(allErrs, field.Required(path.Root().Child("spec", "prometheusAPI"), "required by prometheus detectors"))
+			}
+			if strings.TrimSpace(d.Prometheus.Query) == "" {
+				allErrs = append(allErrs, field.Required(pPath.Child("query"), ""))
+			}
+			allErrs = append(allErrs, validateQuery(pPath.Child("query"), d.Prometheus.Query, spec.Parameters)...)
+			if d.Prometheus.Range != nil {
+				allErrs = append(allErrs, validateRange(pPath.Child("range"), d.Prometheus.Range)...)
+			}
+			if d.Prometheus.Threshold == "" && !d.Prometheus.FireOnNoData {
+				allErrs = append(allErrs, field.Required(pPath.Child("threshold"), "required unless fireOnNoData is set"))
+			}
+			allErrs = append(allErrs, validateFloat(pPath.Child("threshold"), d.Prometheus.Threshold)...)
+			metrics[d.Name] = true
+		case d.Probe != nil:
+			pPath := dPath.Child("probe")
+			if d.Probe.Name != "" && d.Probe.Name != d.Name {
+				allErrs = append(allErrs, field.Invalid(pPath.Child("name"), d.Probe.Name, "must be empty or the detector's name"))
+			}
+			p := *d.Probe
+			p.Name = d.Name
+			allErrs = append(allErrs, validateProbe(pPath, &p, spec.Parameters, metrics)...)
+		case d.ML != nil:
+			if spec.ML == nil {
+				allErrs = append(allErrs, field.Required(path.Root().Child("spec", "ml"), "required by ml detectors"))
+			}
+			allErrs = append(allErrs, validateFloat(dPath.Child("ml", "threshold"), d.ML.Threshold)...)
+		case d.Statistical != nil:
+			if len(spec.Statistical) == 0 {
+				allErrs = append(allErrs, field.Required(path.Root().Child("spec", "statistical"), "required by statistical detectors"))
+			}
+		}
+	}
+
+	// Statistical detectors may refer to any prometheus or probe detector
+	for i, d := range c.Detectors {
+		if d.Statistical != nil && !metrics[d.Statistical.Metric] {
+			allErrs = append(allErrs, field.NotFound(path.Child("detectors").Index(i).Child("statistical", "metric"), d.Statistical.Metric))
+		}
+	}
+	return allErrs
+}
+
+// validateRule compiles a rule of the form "<metric> > <threshold>".
+func validateRule(path *field.Path, rule string, metrics map[string]bool, declared []detectv1alpha1.TemplateParameter) field.ErrorList {
+	allErrs := validateTemplated(path, rule, declared)
+	parts := strings.Split(rule, ">")
+	if len(parts) != 2 {
+		return append(allErrs, field.Invalid(path, rule, `must have the form "<metric> > <threshold>"`))
+	}
+	metric := strings.TrimSpace(parts[0])
+	if !metrics[metric] {
+		allErrs = append(allErrs, field.Invalid(path, rule, fmt.Sprintf("metric %q is not defined in queries or http values", metric)))
+	}
+	threshold := strings.TrimSpace(parts[1])
+	if !strings.Contains(threshold, "{{") {
+		if _, err := strconv.ParseFloat(threshold, 64); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, rule, fmt.Sprintf("threshold %q is not a number", threshold)))
+		}
+	}
+	return allErrs
+}
+
+func validateRange(path *field.Path, r *detectv1alpha1.RangeSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Lookback.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("lookback"), r.Lookback.Duration.String(), "must be positive"))
+	}
+	if r.Step != nil && (r.Step.Duration <= 0 || r.Step.Duration > r.Lookback.Duration) {
+		allErrs = append(allErrs, field.Invalid(path.Child("step"), r.Step.Duration.String(), "must be positive and at most the lookback"))
+	}
+	switch r.Aggregation {
+	case detectv1alpha1.AggregationCountAbove, detectv1alpha1.AggregationFractionAbove:
+		if r.Threshold == "" {
+			allErrs = append(allErrs, field.Required(path.Child("threshold"), fmt.Sprintf("required by %s", r.Aggregation)))
+		}
+	}
+	return append(allErrs, validateFloat(path.Child("threshold"), r.Threshold)...)
+}
+
+func validateEvents(path *field.Path, e *detectv1alpha1.EventSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if e.MessagePattern != "" {
+		if _, err := regexp.Compile(e.MessagePattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("messagePattern"), e.MessagePattern, err.Error()))
+		}
+	}
+	if e.Window != nil && e.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("window"), e.Window.Duration.String(), "must be positive"))
+	}
+	if e.MinCount < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("minCount"), e.MinCount, "must not be negative"))
+	}
+	if len(e.Reasons) == 0 && e.InvolvedObjectKind == "" && e.Type == "" && e.MessagePattern == "" {
+		allErrs = append(allErrs, field.Required(path, "at least one of reasons, involvedObjectKind, type and messagePattern is required"))
+	}
+	return allErrs
+}
+
+// validateLogs checks the log patterns and records their names as metrics.
+func validateLogs(path *field.Path, l *detectv1alpha1.LogSpec, metrics map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if l.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(l.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("selector"), l.Selector, err.Error()))
+		}
+	}
+	if len(l.Patterns) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("patterns"), ""))
+	}
+	for i, p := range l.Patterns {
+		pPath := path.Child("patterns").Index(i)
+		if p.Name == "" {
+			allErrs = append(allErrs, field.Required(pPath.Child("name"), ""))
+		} else if metrics[p.Name] {
+			allErrs = append(allErrs, field.Duplicate(pPath.Child("name"), p.Name))
+		}
+		metrics[p.Name] = true
+		if p.Regex == "" {
+			allErrs = append(allErrs, field.Required(pPath.Child("regex"), ""))
+		} else if _, err := regexp.Compile(p.Regex); err != nil {
+			allErrs = append(allErrs, field.Invalid(pPath.Child("regex"), p.Regex, err.Error()))
+		}
+		if p.MinCount < 0 {
+			allErrs = append(allErrs, field.Invalid(pPath.Child("minCount"), p.MinCount, "must not be negative"))
+		}
+	}
+	if l.Window != nil && l.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("window"), l.Window.Duration.String(), "must be positive"))
+	}
+	if l.MaxLineLength < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxLineLength"), l.MaxLineLength, "must not be negative"))
+	}
+	return allErrs
+}
+
+// validateHTTPSource checks the endpoint and JSONPaths of an HTTP source and
+// records its metrics.
+func validateHTTPSource(path *field.Path, h *detectv1alpha1.HTTPSourceSpec, declared []detectv1alpha1.TemplateParameter, metrics map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if h.URL == "" {
+		allErrs = append(allErrs, field.Required(path.Child("url"), ""))
+	} else {
+		allErrs = append(allErrs, validateTemplated(path.Child("url"), h.URL, declared)...)
+		if !strings.Contains(h.URL, "{{") {
+			allErrs = append(allErrs, validateURL(path.Child("url"), h.URL)...)
+		}
+	}
+	if h.Body != "" {
+		if h.Method != http.MethodPost {
+			allErrs = append(allErrs, field.Forbidden(path.Child("body"), "only sent with POST"))
+		}
+		allErrs = append(allErrs, validateTemplated(path.Child("body"), h.Body, declared)...)
+	}
+	if len(h.Values) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("values"), ""))
+	}
+	for i, v := range h.Values {
+		vPath := path.Child("values").Index(i)
+		if v.Metric == "" {
+			allErrs = append(allErrs, field.Required(vPath.Child("metric"), ""))
+		} else if metrics[v.Metric] {
+			allErrs = append(allErrs, field.Duplicate(vPath.Child("metric"), v.Metric))
+		}
+		metrics[v.Metric] = true
+		if v.JSONPath == "" {
+			allErrs = append(allErrs, field.Required(vPath.Child("jsonPath"), ""))
+		} else if err := jsonsource.Parse(v.JSONPath); err != nil {
+			allErrs = append(allErrs, field.Invalid(vPath.Child("jsonPath"), v.JSONPath, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateAlerts checks the alert matchers.
+func validateAlerts(path *field.Path, a *detectv1alpha1.AlertSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(a.Matchers) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("matchers"), ""))
+	}
+	for name := range a.Matchers {
+		if name == "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("matchers"), name, "label name must not be empty"))
+		}
+	}
+	return allErrs
+}
+
+// validateProbe checks one probe and records its failure count and latency
+// as metrics.
+func validateProbe(path *field.Path, p *detectv1alpha1.ProbeSpec, declared []detectv1alpha1.TemplateParameter, metrics map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if p.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+	} else if metrics[p.Name] || metrics[p.Name+probeLatencySuffix] {
+		allErrs = append(allErrs, field.Duplicate(path.Child("name"), p.Name))
+	}
+	metrics[p.Name] = true
+	metrics[p.Name+probeLatencySuffix] = true
+
+	switch p.Type {
+	case detectv1alpha1.ProbeHTTP, detectv1alpha1.ProbeTCP, detectv1alpha1.ProbeGRPC, detectv1alpha1.ProbeDNS:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), p.Type,
+			[]detectv1alpha1.ProbeType{detectv1alpha1.ProbeHTTP, detectv1alpha1.ProbeTCP, detectv1alpha1.ProbeGRPC, detectv1alpha1.ProbeDNS}))
+	}
+
+	switch {
+	case p.Address != "" && p.Selector != nil:
+		allErrs = append(allErrs, field.Forbidden(path.Child("selector"), "address and selector are mutually exclusive"))
+	case p.Address != "":
+		allErrs = append(allErrs, validateTemplated(path.Child("address"), p.Address, declared)...)
+		if !strings.Contains(p.Address, "{{") {
+			allErrs = append(allErrs, validateProbeAddress(path.Child("address"), p)...)
+		}
+	case p.Type == detectv1alpha1.ProbeDNS && p.Selector != nil:
+		allErrs = append(allErrs, field.Forbidden(path.Child("selector"), "pods have no name to resolve"))
+	case p.Type != detectv1alpha1.ProbeDNS && (p.Port < 1 || p.Port > 65535):
+		allErrs = append(allErrs, field.Invalid(path.Child("port"), p.Port, "must be between 1 and 65535 without an address"))
+	}
+	if p.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(p.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("selector"), p.Selector, err.Error()))
+		}
+	}
+
+	if p.Type != detectv1alpha1.ProbeHTTP {
+		for name, set := range map[string]bool{
+			"path":                p.Path != "",
+			"method":              p.Method != "",
+			"expectedStatusCodes": len(p.ExpectedStatusCodes) > 0,
+			"bodyRegex":           p.BodyRegex != "",
+		} {
+			if set {
+				allErrs = append(allErrs, field.Forbidden(path.Child(name), "only used by HTTP probes"))
+			}
+		}
+	}
+	if p.GRPCService != "" && p.Type != detectv1alpha1.ProbeGRPC {
+		allErrs = append(allErrs, field.Forbidden(path.Child("grpcService"), "only used by GRPC probes"))
+	}
+	for i, code := range p.ExpectedStatusCodes {
+		if code < 100 || code > 599 {
+			allErrs = append(allErrs, field.Invalid(path.Child("expectedStatusCodes").Index(i), code, "must be an HTTP status code"))
+		}
+	}
+	if p.BodyRegex != "" {
+		if _, err := regexp.Compile(p.BodyRegex); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("bodyRegex"), p.BodyRegex, err.Error()))
+		}
+	}
+	if p.Timeout != nil && p.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("timeout"), p.Timeout.Duration.String(), "must be positive"))
+	}
+	if p.MaxLatency != nil && p.MaxLatency.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxLatency"), p.MaxLatency.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// validateProbeAddress checks that a literal address suits the probe type.
+func validateProbeAddress(path *field.Path, p *detectv1alpha1.ProbeSpec) field.ErrorList {
+	switch p.Type {
+	case detectv1alpha1.ProbeHTTP:
+		return validateURL(path, p.Address)
+	case detectv1alpha1.ProbeTCP, detectv1alpha1.ProbeGRPC:
+		if _, port, err := net.SplitHostPort(p.Address); err != nil || port == "" {
+			return field.ErrorList{field.Invalid(path, p.Address, "must be host:port")}
+		}
+	case detectv1alpha1.ProbeDNS:
+		if strings.ContainsAny(p.Address, ":/") {
+			return field.ErrorList{field.Invalid(path, p.Address, "must be a host name")}
+		}
+	}
+	return nil
+}
+
+func validateStatistical(path *field.Path, s detectv1alpha1.StatisticalSpec, metrics map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if s.Metric != "" && !metrics[s.Metric] {
+		allErrs = append(allErrs, field.NotFound(path.Child("metric"), s.Metric))
+	}
+	allErrs = append(allErrs, validateFloat(path.Child("sensitivity"), s.Sensitivity)...)
+	allErrs = append(allErrs, validateFloat(path.Child("limit"), s.Limit)...)
+	if s.Alpha != "" {
+		if a, err := strconv.ParseFloat(s.Alpha, 64); err != nil || a <= 0 || a > 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("alpha"), s.Alpha, "must be a number in (0, 1]"))
+		}
+	}
+	if s.MinSamples < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("minSamples"), s.MinSamples, "must not be negative"))
+	}
+	if s.WindowSize < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("windowSize"), s.WindowSize, "must not be negative"))
+	}
+	if s.Algorithm == detectv1alpha1.AlgorithmForecast && s.Limit == "" {
+		allErrs = append(allErrs, field.Required(path.Child("limit"), "required by forecast"))
+	}
+	return allErrs
+}
+
+func validateML(path *field.Path, ml *detectv1alpha1.MLSpec) (field.ErrorList, admission.Warnings) {
+	var (
+		allErrs  field.ErrorList
+		warnings admission.Warnings
+	)
+	if ml.Image != "" && ml.Endpoint != "" {
+		allErrs = append(allErrs, field.Invalid(path, ml.Endpoint, "image and endpoint are mutually exclusive"))
+	}
+	if ml.Endpoint != "" {
+		allErrs = append(allErrs, validateURL(path.Child("endpoint"), ml.Endpoint)...)
+	}
+	if ml.Image == "" && ml.Endpoint == "" {
+		warnings = append(warnings, "spec.ml has neither image nor endpoint; the model is never called")
+	}
+	if ml.Replicas != nil && *ml.Replicas < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("replicas"), *ml.Replicas, "must be at least 1"))
+	}
+	if ml.Port < 0 || ml.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(path.Child("port"), ml.Port, "must be a valid port"))
+	}
+	if ml.Protocol == detectv1alpha1.InferenceProtocolV2 && ml.ModelName == "" {
+		allErrs = append(allErrs, field.Required(path.Child("modelName"), "required by protocol v2"))
+	}
+	allErrs = append(allErrs, validateFloat(path.Child("threshold"), ml.Threshold)...)
+	return allErrs, warnings
+}
+
+// validateTemplated checks template syntax and that every referenced
+// parameter is declared.
+func validateTemplated(path *field.Path, text string, declared []detectv1alpha1.TemplateParameter) field.ErrorList {
+	if err := params.Parse(text); err != nil {
+		return field.ErrorList{field.Invalid(path, text, err.Error())}
+	}
+	var allErrs field.ErrorList
+	for _, ref := range params.References(text) {
+		found := false
+		for _, p := range declared {
+			if p.Name == ref {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.Invalid(path, text, fmt.Sprintf("parameter %q is not declared in spec.parameters", ref)))
+		}
+	}
+	return allErrs
+}
+
+// validateQuery checks a templated PromQL query, which may only use Number
+// and Duration parameters outside string literals.
+func validateQuery(path *field.Path, text string, declared []detectv1alpha1.TemplateParameter) field.ErrorList {
+	allErrs := validateTemplated(path, text, declared)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	if err := params.CheckPromQL(text, declared); err != nil {
+		return field.ErrorList{field.Invalid(path, text, err.Error())}
+	}
+	return nil
+}
+
+func validateFloat(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return field.ErrorList{field.Invalid(path, value, "must be a number")}
+	}
+	return nil
+}
+
+func validateURL(path *field.Path, value string) field.ErrorList {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return field.ErrorList{field.Invalid(path, value, "must be an http or https URL")}
+	}
+	return nil
+}
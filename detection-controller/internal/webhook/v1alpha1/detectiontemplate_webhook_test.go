@@ -0,0 +1,303 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+var _ = Describe("DetectionTemplate Webhook", func() {
+	var (
+		obj       *detectv1alpha1.DetectionTemplate
+		validator DetectionTemplateCustomValidator
+		defaulter DetectionTemplateCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &detectv1alpha1.DetectionTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-template"},
+			Spec: detectv1alpha1.DetectionTemplateSpec{
+				Scope:         detectv1alpha1.ScopeNode,
+				Interval:      metav1.Duration{Duration: 30 * time.Second},
+				PrometheusAPI: "http://prometheus:9090",
+				Queries:       []detectv1alpha1.QuerySpec{{Metric: "cpu", Query: "node_cpu"}},
+				Rule:          "cpu > 0.9",
+			},
+		}
+		validator = DetectionTemplateCustomValidator{}
+		defaulter = DetectionTemplateCustomDefaulter{}
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: "webhook-template"}})
+	})
+
+	Context("When creating DetectionTemplate under Defaulting Webhook", func() {
+		It("Should apply defaults when a required field is empty", func() {
+			obj.Spec.Interval = metav1.Duration{}
+			obj.Spec.Queries[0].Range = &detectv1alpha1.RangeSpec{Lookback: metav1.Duration{Duration: 5 * time.Minute}}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Interval.Duration).To(Equal(DefaultInterval))
+			Expect(obj.Spec.Queries[0].Range.Aggregation).To(Equal(detectv1alpha1.AggregationAvg))
+		})
+
+		It("Should default the interval through the API server", func() {
+			obj.Spec.Interval = metav1.Duration{}
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Interval.Duration).To(Equal(DefaultInterval))
+		})
+	})
+
+	Context("When creating or updating DetectionTemplate under Validating Webhook", func() {
+		It("Should admit a valid template", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		})
+
+		It("Should deny an interval below the minimum", func() {
+			obj.Spec.Interval = metav1.Duration{Duration: time.Second}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.interval")))
+		})
+
+		It("Should deny mixing Prometheus queries and a field check", func() {
+			obj.Spec.APIVersion = "v1"
+			obj.Spec.Kind = "Node"
+			obj.Spec.FieldPath = "spec.unschedulable"
+			obj.Spec.Expected = "false"
+			err := k8sClient.Create(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("only one detection source may be set")))
+		})
+
+		It("Should deny a template without a detection source", func() {
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("one of prometheusAPI/queries")))
+		})
+
+		It("Should deny a rule on an undefined metric", func() {
+			obj.Spec.Rule = "memory > 0.9"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`metric "memory" is not defined`)))
+		})
+
+		It("Should deny a rule that does not compile", func() {
+			obj.Spec.Rule = "cpu >= high"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.rule")))
+		})
+
+		It("Should deny duplicate query metrics", func() {
+			obj.Spec.Queries = append(obj.Spec.Queries, detectv1alpha1.QuerySpec{Metric: "cpu", Query: "other"})
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.queries[1].metric")))
+		})
+
+		It("Should deny a countAbove range without a threshold", func() {
+			obj.Spec.Queries[0].Range = &detectv1alpha1.RangeSpec{
+				Lookback:    metav1.Duration{Duration: 5 * time.Minute},
+				Aggregation: detectv1alpha1.AggregationCountAbove,
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.queries[0].range.threshold")))
+		})
+
+		It("Should deny a statistical detector on an unknown metric", func() {
+			obj.Spec.Statistical = []detectv1alpha1.StatisticalSpec{{Algorithm: detectv1alpha1.AlgorithmEWMA, Metric: "disk"}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.statistical[0].metric")))
+		})
+
+		It("Should deny an ML model with both an image and an endpoint", func() {
+			obj.Spec.ML = &detectv1alpha1.MLSpec{ModelName: "m", Image: "model:latest", Endpoint: "http://model:8080"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("mutually exclusive")))
+		})
+
+		It("Should validate event matchers", func() {
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			obj.Spec.Events = &detectv1alpha1.EventSpec{Reasons: []string{"OOMKilling"}, MessagePattern: "("}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.events.messagePattern")))
+
+			obj.Spec.Events.MessagePattern = "out of memory"
+			obj.Spec.Statistical = []detectv1alpha1.StatisticalSpec{{Algorithm: detectv1alpha1.AlgorithmEWMA, Metric: "events"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("denying events combined with another source")
+			obj.Spec.PrometheusAPI = "http://prometheus:9090"
+			obj.Spec.Queries = []detectv1alpha1.QuerySpec{{Metric: "cpu", Query: "node_cpu"}}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("only one detection source may be set")))
+		})
+
+		It("Should validate log patterns", func() {
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			obj.Spec.Logs = &detectv1alpha1.LogSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+				Patterns: []detectv1alpha1.LogPattern{
+					{Name: "panics", Regex: "^panic:"},
+					{Name: "panics", Regex: "("},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.logs.patterns[1].name")))
+			Expect(err).To(MatchError(ContainSubstring("spec.logs.patterns[1].regex")))
+
+			obj.Spec.Logs.Patterns[1] = detectv1alpha1.LogPattern{Name: "db-errors", Regex: "timeout", JSONField: "error.kind", MinCount: 5}
+			obj.Spec.Statistical = []detectv1alpha1.StatisticalSpec{{Algorithm: detectv1alpha1.AlgorithmEWMA, Metric: "db-errors"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate probes", func() {
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			obj.Spec.Probes = []detectv1alpha1.ProbeSpec{
+				{Name: "healthz", Type: detectv1alpha1.ProbeHTTP, Address: "checkout:8080/healthz"},
+				{Name: "grpc", Type: detectv1alpha1.ProbeGRPC, BodyRegex: "ok"},
+				{Name: "dns", Type: detectv1alpha1.ProbeDNS, Selector: &metav1.LabelSelector{}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.probes[0].address")))
+			Expect(err).To(MatchError(ContainSubstring("spec.probes[1].port")))
+			Expect(err).To(MatchError(ContainSubstring("spec.probes[1].bodyRegex")))
+			Expect(err).To(MatchError(ContainSubstring("spec.probes[2].selector")))
+
+			obj.Spec.Probes = []detectv1alpha1.ProbeSpec{
+				{Name: "healthz", Type: detectv1alpha1.ProbeHTTP, Address: "http://checkout:8080/healthz", ExpectedStatusCodes: []int32{200}},
+				{Name: "grpc", Type: detectv1alpha1.ProbeGRPC, Port: 9090, GRPCService: "checkout.Cart"},
+				{Name: "dns", Type: detectv1alpha1.ProbeDNS, Address: "checkout.shop.svc"},
+			}
+			obj.Spec.Statistical = []detectv1alpha1.StatisticalSpec{{Algorithm: detectv1alpha1.AlgorithmEWMA, Metric: "healthz_latency"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate http sources", func() {
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = "queue_depth > 100"
+			obj.Spec.HTTP = &detectv1alpha1.HTTPSourceSpec{
+				URL:    "etcd:2379/health",
+				Body:   `{"probe":true}`,
+				Values: []detectv1alpha1.JSONValue{{Metric: "queue_depth", JSONPath: "{.queue[0"}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.http.url")))
+			Expect(err).To(MatchError(ContainSubstring("spec.http.body")))
+			Expect(err).To(MatchError(ContainSubstring("spec.http.values[0].jsonPath")))
+
+			obj.Spec.HTTP.URL = "https://checkout.shop.svc/status"
+			obj.Spec.HTTP.Method = "POST"
+			obj.Spec.HTTP.Values[0].JSONPath = ".queue.depth"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate alert sources", func() {
+			obj.Spec.Alerts = &detectv1alpha1.AlertSpec{}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("only one detection source may be set")))
+			Expect(err).To(MatchError(ContainSubstring("spec.alerts.matchers")))
+
+			obj.Spec.PrometheusAPI = ""
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			obj.Spec.Alerts.Matchers = map[string]string{"alertname": "KubeNodeNotReady"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny undeclared template parameters", func() {
+			obj.Spec.Queries[0].Query = `node_cpu{instance="{{ .Params.instance }}"}`
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`parameter "instance" is not declared`)))
+
+			obj.Spec.Parameters = []detectv1alpha1.TemplateParameter{{Name: "instance", Required: true}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should only allow typed parameters outside PromQL strings", func() {
+			obj.Spec.Queries[0].Query = `rate(node_cpu[{{ .Params.window }}]) > {{ .Params.limit }}`
+			obj.Spec.Parameters = []detectv1alpha1.TemplateParameter{
+				{Name: "window", Default: "5m"},
+				{Name: "limit", Type: detectv1alpha1.ParameterNumber, Default: "0.9"},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("{{.Params.window}} is outside a string literal")))
+
+			obj.Spec.Parameters[0].Type = detectv1alpha1.ParameterDuration
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Parameters[0].Default = "5m]) or vector(1"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.parameters[0].default")))
+		})
+
+		It("Should validate composite detectors", func() {
+			obj.Spec.Queries = nil
+			obj.Spec.Rule = ""
+			obj.Spec.Composite = &detectv1alpha1.CompositeSpec{
+				Operator:  detectv1alpha1.CompositeAtLeast,
+				MinFiring: 3,
+				Detectors: []detectv1alpha1.SubDetectorSpec{
+					{Name: "load", Prometheus: &detectv1alpha1.PrometheusCheck{Query: "node_load1", Threshold: "4"}},
+					{Name: "ready", Field: &detectv1alpha1.FieldCheck{APIVersion: "v1", Kind: "Node", FieldPath: "status.conditions[Ready].status", Expected: "True"}},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.composite.minFiring")))
+
+			obj.Spec.Composite.MinFiring = 2
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			By("validating probe detectors like spec.probes")
+			obj.Spec.Composite.Detectors = append(obj.Spec.Composite.Detectors, detectv1alpha1.SubDetectorSpec{
+				Name: "healthz", Probe: &detectv1alpha1.ProbeSpec{Type: detectv1alpha1.ProbeHTTP, Address: "http://checkout:8080/healthz"},
+			})
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			obj.Spec.Composite.Detectors[2].Probe.Address = "checkout:8080"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.composite.detectors[2].probe.address")))
+			obj.Spec.Composite.Detectors = obj.Spec.Composite.Detectors[:2]
+
+			By("denying a detector with more than one check")
+			obj.Spec.Composite.Detectors[1].ML = &detectv1alpha1.MLCheck{}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("exactly one of field, prometheus, statistical, ml and probe")))
+		})
+
+		It("Should validate updates", func() {
+			oldObj := obj.DeepCopy()
+			obj.Spec.Scope = "Namespace"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.scope")))
+		})
+	})
+})
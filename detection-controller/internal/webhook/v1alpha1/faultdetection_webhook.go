@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+	"github.com/phuongbac/detection-controller/internal/params"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var faultdetectionlog = logf.Log.WithName("faultdetection-resource")
+
+// SetupFaultDetectionWebhookWithManager registers the webhook for FaultDetection in the manager.
+func SetupFaultDetectionWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&detectv1alpha1.FaultDetection{}).
+		WithValidator(&FaultDetectionCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&FaultDetectionCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-detect-failure-recovery-io-v1alpha1-faultdetection,mutating=true,failurePolicy=fail,sideEffects=None,groups=detect.failure-recovery.io,resources=faultdetections,verbs=create;update,versions=v1alpha1,name=mfaultdetection-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// FaultDetectionCustomDefaulter sets default values on FaultDetections.
+type FaultDetectionCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &FaultDetectionCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind FaultDetection.
+func (d *FaultDetectionCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	fd, ok := obj.(*detectv1alpha1.FaultDetection)
+	if !ok {
+		return fmt.Errorf("expected a FaultDetection object but got %T", obj)
+	}
+	faultdetectionlog.Info("Defaulting for FaultDetection", "name", fd.GetName())
+
+	// Namespaced targets are looked up in the FaultDetection's namespace
+	// unless told otherwise
+	if t := fd.Spec.Target; t != nil {
+		*t = t.WithDefaultNamespace(fd.Namespace)
+		if t.Kind == "Pod" && t.APIVersion == "" {
+			t.APIVersion = "v1"
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-detect-failure-recovery-io-v1alpha1-faultdetection,mutating=false,failurePolicy=fail,sideEffects=None,groups=detect.failure-recovery.io,resources=faultdetections,verbs=create;update,versions=v1alpha1,name=vfaultdetection-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// FaultDetectionCustomValidator validates FaultDetections against the
+// DetectionTemplate they refer to.
+type FaultDetectionCustomValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &FaultDetectionCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type FaultDetection.
+func (v *FaultDetectionCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	fd, ok := obj.(*detectv1alpha1.FaultDetection)
+	if !ok {
+		return nil, fmt.Errorf("expected a FaultDetection object but got %T", obj)
+	}
+	faultdetectionlog.Info("Validation for FaultDetection upon creation", "name", fd.GetName())
+	return v.validate(ctx, fd)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type FaultDetection.
+func (v *FaultDetectionCustomValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	fd, ok := newObj.(*detectv1alpha1.FaultDetection)
+	if !ok {
+		return nil, fmt.Errorf("expected a FaultDetection object for the newObj but got %T", newObj)
+	}
+	faultdetectionlog.Info("Validation for FaultDetection upon update", "name", fd.GetName())
+	// Objects being deleted must stay updatable so finalizers can be removed
+	if !fd.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return v.validate(ctx, fd)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type FaultDetection.
+func (v *FaultDetectionCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *FaultDetectionCustomValidator) validate(ctx context.Context, fd *detectv1alpha1.FaultDetection) (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if fd.Spec.TemplateRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("templateRef"), ""))
+		return nil, invalidFaultDetection(fd, allErrs)
+	}
+
+	tmpl := &detectv1alpha1.DetectionTemplate{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: fd.Spec.TemplateRef}, tmpl); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("reading DetectionTemplate %q: %w", fd.Spec.TemplateRef, err)
+		}
+		// Templates may be applied after the FaultDetections that use them.
+		// The controller reports the missing template in status until then.
+		return admission.Warnings{fmt.Sprintf(
+			"DetectionTemplate %q not found; parameters and target are checked once it exists", fd.Spec.TemplateRef)}, nil
+	}
+
+	if _, err := params.Resolve(tmpl.Spec.Parameters, fd.Spec.Parameters); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("parameters"), fd.Spec.Parameters, err.Error()))
+	}
+	allErrs = append(allErrs, validateTarget(specPath.Child("target"), fd.Spec.Target, &tmpl.Spec)...)
+
+	return nil, invalidFaultDetection(fd, allErrs)
+}
+
+// validateTarget checks that the target the template's scope and sources
+// need is present.
+func validateTarget(path *field.Path, target *detectv1alpha1.ObjectRef, spec *detectv1alpha1.DetectionTemplateSpec) field.ErrorList {
+	hasName := target != nil && target.Name != ""
+	var allErrs field.ErrorList
+
+	switch {
+	case spec.Events != nil:
+		// Events are attributed to their involved object, so any scope may watch all of them
+	case spec.Logs != nil && spec.Logs.Selector == nil:
+		if target == nil || target.Kind != "Pod" || !hasName {
+			allErrs = append(allErrs, field.Required(path, "a Pod target is required by log templates without a selector"))
+		}
+	case spec.Logs != nil:
+		// The selector picks the pods
+	case len(spec.Probes) > 0:
+		for _, p := range spec.Probes {
+			if p.Address == "" && p.Selector == nil &&
+				(target == nil || (target.Kind != "Pod" && target.Kind != "Service") || !hasName) {
+				allErrs = append(allErrs, field.Required(path,
+					fmt.Sprintf("a Pod or Service target is required by probe %q without address or selector", p.Name)))
+				break
+			}
+		}
+	case spec.Scope == detectv1alpha1.ScopePod && !hasName:
+		allErrs = append(allErrs, field.Required(path.Child("name"), "required by Pod scoped templates"))
+	case spec.Composite != nil:
+		for _, d := range spec.Composite.Detectors {
+			if d.Field != nil && !hasName {
+				allErrs = append(allErrs, field.Required(path.Child("name"),
+					fmt.Sprintf("required by field detector %q", d.Name)))
+				break
+			}
+			if d.Probe != nil && d.Probe.Address == "" && d.Probe.Selector == nil &&
+				(target == nil || (target.Kind != "Pod" && target.Kind != "Service") || !hasName) {
+				allErrs = append(allErrs, field.Required(path,
+					fmt.Sprintf("a Pod or Service target is required by probe detector %q without address or selector", d.Name)))
+				break
+			}
+		}
+	case spec.FieldPath != "" && spec.Scope != detectv1alpha1.ScopeNode && !hasName:
+		// Only Node scoped field checks can run against every object
+		allErrs = append(allErrs, field.Required(path.Child("name"),
+			fmt.Sprintf("required by %s scoped field checks", spec.Scope)))
+	}
+
+	if target != nil && target.Kind == "" && hasName {
+		allErrs = append(allErrs, field.Required(path.Child("kind"), "required with name"))
+	}
+	return allErrs
+}
+
+func invalidFaultDetection(fd *detectv1alpha1.FaultDetection, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(detectv1alpha1.GroupVersion.WithKind("FaultDetection").GroupKind(), fd.Name, allErrs)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	detectv1alpha1 "github.com/phuongbac/detection-controller/api/v1alpha1"
+)
+
+var _ = Describe("FaultDetection Webhook", func() {
+	const (
+		podTemplate  = "webhook-pod-template"
+		nodeTemplate = "webhook-node-template"
+		fdName       = "webhook-fd"
+	)
+
+	var (
+		obj       *detectv1alpha1.FaultDetection
+		validator FaultDetectionCustomValidator
+		defaulter FaultDetectionCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: podTemplate},
+			Spec: detectv1alpha1.DetectionTemplateSpec{
+				Scope:         detectv1alpha1.ScopePod,
+				Interval:      metav1.Duration{Duration: 30 * time.Second},
+				Parameters:    []detectv1alpha1.TemplateParameter{{Name: "maxRestarts", Required: true}},
+				PrometheusAPI: "http://prometheus:9090",
+				Queries: []detectv1alpha1.QuerySpec{{
+					Metric: "restarts",
+					Query:  `kube_pod_container_status_restarts_total{pod="{{ .Target.Name }}"}`,
+				}},
+				Rule: "restarts > {{ .Params.maxRestarts }}",
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &detectv1alpha1.DetectionTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeTemplate},
+			Spec: detectv1alpha1.DetectionTemplateSpec{
+				Scope:      detectv1alpha1.ScopeNode,
+				Interval:   metav1.Duration{Duration: 30 * time.Second},
+				APIVersion: "v1",
+				Kind:       "Node",
+				FieldPath:  "status.conditions[Ready].status",
+				Expected:   "True",
+			},
+		})).To(Succeed())
+
+		obj = &detectv1alpha1.FaultDetection{
+			ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"},
+			Spec: detectv1alpha1.FaultDetectionSpec{
+				TemplateRef: podTemplate,
+				Target:      &detectv1alpha1.ObjectRef{Kind: "Pod", Name: "checkout-0"},
+				Parameters:  map[string]string{"maxRestarts": "3"},
+			},
+		}
+		validator = FaultDetectionCustomValidator{Client: k8sClient}
+		defaulter = FaultDetectionCustomDefaulter{}
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &detectv1alpha1.FaultDetection{ObjectMeta: metav1.ObjectMeta{Name: fdName, Namespace: "default"}})
+		_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: podTemplate}})
+		_ = k8sClient.Delete(ctx, &detectv1alpha1.DetectionTemplate{ObjectMeta: metav1.ObjectMeta{Name: nodeTemplate}})
+	})
+
+	Context("When creating FaultDetection under Defaulting Webhook", func() {
+		It("Should default the namespace of a Pod target", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Target.Namespace).To(Equal("default"))
+			Expect(obj.Spec.Target.APIVersion).To(Equal("v1"))
+		})
+
+		It("Should default the namespace of other namespaced targets only", func() {
+			obj.Spec.Target = &detectv1alpha1.ObjectRef{Kind: "Service", Name: "checkout"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Target.Namespace).To(Equal("default"))
+
+			obj.Spec.Target = &detectv1alpha1.ObjectRef{Kind: "Node", Name: "worker-1"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Target.Namespace).To(BeEmpty())
+		})
+
+		It("Should leave an explicit namespace alone", func() {
+			obj.Spec.Target.Namespace = "shop"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Target.Namespace).To(Equal("shop"))
+		})
+	})
+
+	Context("When creating or updating FaultDetection under Validating Webhook", func() {
+		It("Should admit a FaultDetection with its template, target and parameters", func() {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Target.Namespace).To(Equal("default"))
+		})
+
+		It("Should admit a missing template with a warning", func() {
+			obj.Spec.TemplateRef = "does-not-exist"
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring(`DetectionTemplate "does-not-exist" not found`)))
+		})
+
+		It("Should deny a missing template reference", func() {
+			obj.Spec.TemplateRef = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.templateRef: Required value")))
+		})
+
+		It("Should deny a Pod scoped template without a target", func() {
+			obj.Spec.Target = nil
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.target.name")))
+		})
+
+		It("Should admit a Node scoped field check without a target", func() {
+			obj.Spec.TemplateRef = nodeTemplate
+			obj.Spec.Target = nil
+			obj.Spec.Parameters = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should require a Pod or Service target for composite probes without an address", func() {
+			spec := &detectv1alpha1.DetectionTemplateSpec{
+				Scope: detectv1alpha1.ScopeCluster,
+				Composite: &detectv1alpha1.CompositeSpec{
+					Operator: detectv1alpha1.CompositeOr,
+					Detectors: []detectv1alpha1.SubDetectorSpec{{
+						Name:  "healthz",
+						Probe: &detectv1alpha1.ProbeSpec{Type: detectv1alpha1.ProbeHTTP, Port: 8080},
+					}},
+				},
+			}
+			path := field.NewPath("spec", "target")
+			Expect(validateTarget(path, nil, spec)).To(ConsistOf(
+				HaveField("Detail", ContainSubstring(`probe detector "healthz"`))))
+			Expect(validateTarget(path, &detectv1alpha1.ObjectRef{Kind: "Service", Name: "checkout"}, spec)).To(BeEmpty())
+
+			spec.Composite.Detectors[0].Probe.Address = "http://checkout:8080/healthz"
+			Expect(validateTarget(path, nil, spec)).To(BeEmpty())
+		})
+
+		It("Should deny missing and unknown parameters", func() {
+			obj.Spec.Parameters = nil
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("missing required parameters: maxRestarts")))
+
+			obj.Spec.Parameters = map[string]string{"maxRestarts": "3", "typo": "1"}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("unknown parameters: typo")))
+		})
+
+		It("Should validate updates", func() {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			obj.Spec.Target = nil
+			err := k8sClient.Update(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})